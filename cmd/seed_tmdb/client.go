@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNotFound is returned for a resource TMDB does not have, such as a movie
+// removed after it was listed.
+var errNotFound = errors.New("not found on TMDB")
+
+// maxRetries is how often a request is retried when TMDB answers 429 or a
+// server error, or cannot be reached.
+const maxRetries = 5
+
+// tmdbClient calls the TMDB API at baseURL, spacing requests to stay under
+// the rate limit. Pointing baseURL at a local server lets the sync run
+// against a fake TMDB.
+type tmdbClient struct {
+	baseURL  string
+	apiKey   string
+	http     *http.Client
+	interval time.Duration
+	next     time.Time
+}
+
+func newTMDBClient(baseURL, apiKey string, requestsPerSecond int) *tmdbClient {
+	interval := time.Duration(0)
+	if requestsPerSecond > 0 {
+		interval = time.Second / time.Duration(requestsPerSecond)
+	}
+	return &tmdbClient{
+		baseURL:  baseURL,
+		apiKey:   apiKey,
+		http:     &http.Client{Timeout: 15 * time.Second},
+		interval: interval,
+	}
+}
+
+// throttle waits until the next request is allowed.
+func (c *tmdbClient) throttle() {
+	if wait := time.Until(c.next); wait > 0 {
+		time.Sleep(wait)
+	}
+	c.next = time.Now().Add(c.interval)
+}
+
+// get fetches path into target. Rate limited and failed requests are retried,
+// waiting as long as Retry-After asks or backing off exponentially.
+func (c *tmdbClient) get(path string, query url.Values, target any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	if c.apiKey != "" {
+		query.Set("api_key", c.apiKey)
+	}
+	address := c.baseURL + path + "?" + query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		c.throttle()
+		retryable, wait, err := c.fetch(address, path, target)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt == maxRetries {
+			return err
+		}
+		if wait == 0 {
+			wait = min(time.Second<<attempt, 30*time.Second)
+		}
+		time.Sleep(wait)
+	}
+}
+
+func (c *tmdbClient) fetch(address, path string, target any) (bool, time.Duration, error) {
+	resp, err := c.http.Get(address)
+	if err != nil {
+		return true, 0, fmt.Errorf("GET %s: %v", path, c.redact(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, 0, fmt.Errorf("GET %s: %w", path, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("GET %s: %s: %s", path, resp.Status, body)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			return true, time.Duration(seconds) * time.Second, err
+		}
+		return false, 0, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return false, 0, fmt.Errorf("GET %s: invalid response: %v", path, err)
+	}
+	return false, 0, nil
+}
+
+// redact drops the request URL from a request error, as it carries the API
+// key, and masks the key should the cause mention it too.
+func (c *tmdbClient) redact(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if c.apiKey == "" {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), c.apiKey, "REDACTED"))
+}
+
+type idPage struct {
+	Page       int `json:"page"`
+	TotalPages int `json:"total_pages"`
+	Results    []struct {
+		ID int `json:"id"`
+	} `json:"results"`
+}
+
+// pagedIDs collects the IDs of every page of a paged list, or of the first
+// maxPages when maxPages is above 0.
+func (c *tmdbClient) pagedIDs(path string, query url.Values, maxPages int) ([]int, error) {
+	var ids []int
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		q := url.Values{}
+		for key, values := range query {
+			q[key] = values
+		}
+		q.Set("page", strconv.Itoa(page))
+
+		var list idPage
+		if err := c.get(path, q, &list); err != nil {
+			return ids, err
+		}
+		for _, item := range list.Results {
+			ids = append(ids, item.ID)
+		}
+		if page >= list.TotalPages || len(list.Results) == 0 {
+			break
+		}
+	}
+	return ids, nil
+}
+
+type movieDetail struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	Overview     string `json:"overview"`
+	ReleaseDate  string `json:"release_date"`
+	Runtime      int    `json:"runtime"`
+	PosterPath   string `json:"poster_path"`
+	BackdropPath string `json:"backdrop_path"`
+	Genres       []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	Credits struct {
+		Crew []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Job  string `json:"job"`
+		} `json:"crew"`
+		Cast []struct {
+			ID        int    `json:"id"`
+			Name      string `json:"name"`
+			Character string `json:"character"`
+			Order     int    `json:"order"`
+		} `json:"cast"`
+	} `json:"credits"`
+}
+
+// movie fetches a movie with its credits in one request.
+func (c *tmdbClient) movie(id int) (movieDetail, error) {
+	var detail movieDetail
+	err := c.get(fmt.Sprintf("/movie/%d", id), url.Values{"append_to_response": {"credits"}}, &detail)
+	return detail, err
+}
@@ -1,137 +1,248 @@
 package main
 
 import (
+	"be-tickitz/dto"
+	"be-tickitz/models"
 	"be-tickitz/utils"
-	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
-type MovieListResponse struct {
-	Results []struct {
-		ID int `json:"id"`
-	} `json:"results"`
-}
+// changesWindow is the longest date range TMDB's change list accepts.
+const changesWindow = 14 * 24 * time.Hour
 
-type MovieDetail struct {
-	Title        string `json:"title"`
-	Overview     string `json:"overview"`
-	ReleaseDate  string `json:"release_date"`
-	Runtime      int    `json:"runtime"`
-	PosterPath   string `json:"poster_path"`
-	BackdropPath string `json:"backdrop_path"`
-	Genres       []struct {
-		Name string `json:"name"`
-	} `json:"genres"`
+type syncOptions struct {
+	dryRun       bool
+	since        time.Time
+	categories   []string
+	maxPages     int
+	castLimit    int
+	imageBaseURL string
 }
 
-type Credits struct {
-	Crew []struct {
-		Name string `json:"name"`
-		Job  string `json:"job"`
-	} `json:"crew"`
-	Cast []struct {
-		Name string `json:"name"`
-	} `json:"cast"`
+func main() {
+	godotenv.Load()
+
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if args[0] != "sync" {
+			fmt.Fprintf(os.Stderr, "unknown command %q\nusage: seed_tmdb sync [flags]\n", args[0])
+			os.Exit(2)
+		}
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "fetch and compare everything but save nothing")
+	since := fs.String("since", "", "only refresh stored movies TMDB changed since this date (YYYY-MM-DD); new movies are always added")
+	categories := fs.String("categories", "now_playing,upcoming", "comma separated TMDB movie lists to sync")
+	maxPages := fs.Int("max-pages", 0, "pages to fetch per list, 0 for all")
+	castLimit := fs.Int("cast-limit", 6, "top billed actors to keep per movie")
+	rate := fs.Int("rate", utils.GetEnvInt("TMDB_RATE_LIMIT", 20), "maximum requests per second")
+	baseURL := fs.String("base-url", envOr("TMDB_BASE_URL", "https://api.themoviedb.org/3"), "TMDB API base URL")
+	imageBaseURL := fs.String("image-base-url", envOr("TMDB_IMAGE_BASE_URL", "https://image.tmdb.org/t/p"), "TMDB image base URL")
+	fs.Parse(args)
+
+	opts := syncOptions{
+		dryRun:       *dryRun,
+		categories:   strings.Split(*categories, ","),
+		maxPages:     *maxPages,
+		castLimit:    *castLimit,
+		imageBaseURL: strings.TrimRight(*imageBaseURL, "/"),
+	}
+	if *since != "" {
+		date, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			log.Fatalf("Invalid --since %q, expected YYYY-MM-DD", *since)
+		}
+		opts.since = date
+	}
+
+	client := newTMDBClient(strings.TrimRight(*baseURL, "/"), os.Getenv("TMDB_API_KEY"), *rate)
+	if !runSync(client, opts) {
+		os.Exit(1)
+	}
 }
 
-func fetchJSON(url string, target interface{}) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
-	defer resp.Body.Close()
-	return json.NewDecoder(resp.Body).Decode(target)
+	return fallback
 }
 
-func main() {
-	godotenv.Load()
-	apiKey := os.Getenv("TMDB_API_KEY")
-	db, err := utils.ConnectDB()
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Release()
-
-	categories := []string{"now_playing", "upcoming"}
-	for _, cat := range categories {
-		log.Println("📥 Fetching category:", cat)
-		var list MovieListResponse
-		if err := fetchJSON("https://api.themoviedb.org/3/movie/"+cat+"?api_key="+apiKey, &list); err != nil {
-			log.Println("Failed to fetch", cat)
-			continue
+// runSync upserts the movies of the TMDB lists and reports whether every one
+// of them synced.
+func runSync(client *tmdbClient, opts syncOptions) bool {
+	var ids []int
+	seen := map[int]bool{}
+	for _, category := range opts.categories {
+		category = strings.TrimSpace(category)
+		log.Println("📥 Fetching category:", category)
+		listed, err := client.pagedIDs("/movie/"+category, nil, opts.maxPages)
+		if err != nil {
+			log.Printf("❌ Failed to fetch %s: %v", category, err)
+			return false
 		}
+		for _, id := range listed {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
 
-		for _, item := range list.Results {
-			var detail MovieDetail
-			var credits Credits
+	if !opts.since.IsZero() {
+		stored, err := models.TMDBMovieIDs()
+		if err != nil {
+			log.Println("❌ Failed to list stored movies:", err)
+			return false
+		}
+		changed, err := changedSince(client, opts.since, time.Now())
+		if err != nil {
+			log.Println("❌ Failed to fetch changed movies:", err)
+			return false
+		}
+		ids = toRefresh(ids, stored, changed)
+	}
 
-			detailURL := fmt.Sprintf("https://api.themoviedb.org/3/movie/%d?api_key=%s", item.ID, apiKey)
-			creditURL := fmt.Sprintf("https://api.themoviedb.org/3/movie/%d/credits?api_key=%s", item.ID, apiKey)
+	counts := map[string]int{}
+	var people dto.TMDBSyncResult
+	for _, id := range ids {
+		detail, err := client.movie(id)
+		if errors.Is(err, errNotFound) {
+			log.Printf("⏭️  Skipped TMDB %d: no longer on TMDB", id)
+			counts["skipped"]++
+			continue
+		}
+		if err != nil {
+			log.Printf("❌ Failed to fetch movie %d: %v", id, err)
+			counts["failed"]++
+			continue
+		}
+		// Movies need a release date, which TMDB leaves empty for some
+		// announced ones; they are added once it is set.
+		if detail.ReleaseDate == "" {
+			log.Printf("⏭️  Skipped %q (TMDB %d): no release date yet", detail.Title, id)
+			counts["skipped"]++
+			continue
+		}
 
-			if fetchJSON(detailURL, &detail) != nil || fetchJSON(creditURL, &credits) != nil {
-				continue
-			}
+		result, err := models.SyncTMDBMovie(toTMDBMovie(detail, opts), opts.dryRun)
+		if err != nil {
+			log.Printf("❌ Failed to sync %q (TMDB %d): %v", detail.Title, id, err)
+			counts["failed"]++
+			continue
+		}
+		counts[result.Action]++
+		people.GenresCreated += result.GenresCreated
+		people.DirectorsCreated += result.DirectorsCreated
+		people.ActorsCreated += result.ActorsCreated
+		if result.Action != "unchanged" {
+			log.Printf("✅ %s: %s", result.Action, detail.Title)
+		}
+	}
 
-			var movieID int
-			err := db.QueryRow(context.Background(), `
-				INSERT INTO movies (title, description, release_date, duration_minutes, image, horizontal_image)
-				VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
-			`, detail.Title, detail.Overview, detail.ReleaseDate, detail.Runtime,
-				"https://image.tmdb.org/t/p/w500"+detail.PosterPath,
-				"https://image.tmdb.org/t/p/original"+detail.BackdropPath,
-			).Scan(&movieID)
-			if err != nil {
-				log.Println("❌ Insert movie failed:", err)
-				continue
-			}
+	prefix := "🎉 Synced"
+	if opts.dryRun {
+		prefix = "🔍 Dry run, nothing saved:"
+	}
+	log.Printf("%s %d movies: %d created, %d updated, %d unchanged, %d skipped, %d failed; %d genres, %d directors and %d actors added",
+		prefix, len(ids), counts["create"], counts["update"], counts["unchanged"], counts["skipped"], counts["failed"],
+		people.GenresCreated, people.DirectorsCreated, people.ActorsCreated)
 
-			for _, g := range detail.Genres {
-				var genreID int
-				db.QueryRow(context.Background(), `
-					INSERT INTO genres (genre_name) VALUES ($1)
-					ON CONFLICT (genre_name) DO UPDATE SET genre_name = EXCLUDED.genre_name
-					RETURNING id
-				`, g.Name).Scan(&genreID)
+	if !opts.dryRun && counts["create"]+counts["update"] > 0 {
+		if err := models.RefreshSuggestIndex(); err != nil {
+			log.Println("Failed to refresh suggestion index:", err)
+		}
+	}
+	return counts["failed"] == 0
+}
 
-				db.Exec(context.Background(), `INSERT INTO movie_genres (id_movie, id_genre) VALUES ($1, $2)`, movieID, genreID)
-			}
+// changedSince returns the movies TMDB changed between since and today.
+// TMDB's change list covers at most 14 days a request, so longer ranges are
+// fetched window by window.
+func changedSince(client *tmdbClient, since, today time.Time) (map[int]bool, error) {
+	changed := map[int]bool{}
+	for start := since; !start.After(today); start = start.Add(changesWindow) {
+		end := start.Add(changesWindow - 24*time.Hour)
+		if end.After(today) {
+			end = today
+		}
+		query := url.Values{
+			"start_date": {start.Format("2006-01-02")},
+			"end_date":   {end.Format("2006-01-02")},
+		}
+		ids, err := client.pagedIDs("/movie/changes", query, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			changed[id] = true
+		}
+	}
+	return changed, nil
+}
 
-			for _, crew := range credits.Crew {
-				if crew.Job == "Director" {
-					var directorID int
-					db.QueryRow(context.Background(), `
-						INSERT INTO directors (director_name) VALUES ($1)
-						ON CONFLICT (director_name) DO UPDATE SET director_name = EXCLUDED.director_name
-						RETURNING id
-					`, crew.Name).Scan(&directorID)
-
-					db.Exec(context.Background(), `INSERT INTO movie_directors (id_movie, id_director) VALUES ($1, $2)`, movieID, directorID)
-					break
-				}
-			}
+// toRefresh narrows listed to movies not stored yet and stored movies that
+// changed, adding stored movies that changed but left the lists.
+func toRefresh(listed []int, stored, changed map[int]bool) []int {
+	var ids []int
+	seen := map[int]bool{}
+	for _, id := range listed {
+		seen[id] = true
+		if !stored[id] || changed[id] {
+			ids = append(ids, id)
+		}
+	}
+	for id := range changed {
+		if stored[id] && !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
 
-			for i, cast := range credits.Cast {
-				if i >= 6 {
-					break
-				}
-				var actorID int
-				db.QueryRow(context.Background(), `
-					INSERT INTO actors (actor_name) VALUES ($1)
-					ON CONFLICT (actor_name) DO UPDATE SET actor_name = EXCLUDED.actor_name
-					RETURNING id
-				`, cast.Name).Scan(&actorID)
-
-				db.Exec(context.Background(), `INSERT INTO movie_casts (id_movie, id_actor) VALUES ($1, $2)`, movieID, actorID)
-			}
+func toTMDBMovie(detail movieDetail, opts syncOptions) dto.TMDBMovie {
+	movie := dto.TMDBMovie{
+		TMDBID:      detail.ID,
+		Title:       detail.Title,
+		Description: detail.Overview,
+		ReleaseDate: detail.ReleaseDate,
+		Duration:    detail.Runtime,
+	}
+	if detail.PosterPath != "" {
+		movie.Image = opts.imageBaseURL + "/w500" + detail.PosterPath
+	}
+	if detail.BackdropPath != "" {
+		movie.HorizontalImage = opts.imageBaseURL + "/original" + detail.BackdropPath
+	}
 
-			log.Println("✅ Inserted:", detail.Title)
+	for _, g := range detail.Genres {
+		movie.Genres = append(movie.Genres, g.Name)
+	}
+	for _, crew := range detail.Credits.Crew {
+		if crew.Job == "Director" {
+			movie.Directors = append(movie.Directors, dto.TMDBCredit{
+				TMDBID: crew.ID, Name: crew.Name, Job: crew.Job, Order: len(movie.Directors),
+			})
+		}
+	}
+	for _, cast := range detail.Credits.Cast {
+		if len(movie.Cast) >= opts.castLimit {
+			break
 		}
+		movie.Cast = append(movie.Cast, dto.TMDBCredit{
+			TMDBID: cast.ID, Name: cast.Name, Role: cast.Character, Order: cast.Order,
+		})
 	}
-	log.Println("🎉 Done seeding all movies with genres, directors, and casts")
+	return movie
 }
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"be-tickitz/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTMDB serves paged lists, the change list and movie details the way
+// TMDB does, and records the requests it gets.
+type fakeTMDB struct {
+	mu       sync.Mutex
+	requests []*url.URL
+	lists    map[string][][]int
+	changes  map[string][]int
+	movies   map[int]map[string]any
+	handle   func(w http.ResponseWriter, r *http.Request) bool
+}
+
+func newFakeTMDB(t *testing.T, f *fakeTMDB) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.URL)
+		f.mu.Unlock()
+		if f.handle != nil && f.handle(w, r) {
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if r.URL.Path == "/movie/changes" {
+			key := r.URL.Query().Get("start_date") + "/" + r.URL.Query().Get("end_date")
+			writeIDPage(w, 1, [][]int{f.changes[key]})
+			return
+		}
+		if pages, ok := f.lists[r.URL.Path]; ok {
+			writeIDPage(w, page, pages)
+			return
+		}
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/movie/"))
+		if movie, ok := f.movies[id]; ok {
+			json.NewEncoder(w).Encode(movie)
+			return
+		}
+		http.Error(w, `{"status_message":"The resource you requested could not be found."}`, http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeIDPage(w http.ResponseWriter, page int, pages [][]int) {
+	list := map[string]any{"page": page, "total_pages": len(pages), "results": []any{}}
+	if page >= 1 && page <= len(pages) {
+		results := []any{}
+		for _, id := range pages[page-1] {
+			results = append(results, map[string]int{"id": id})
+		}
+		list["results"] = results
+	}
+	json.NewEncoder(w).Encode(list)
+}
+
+func (f *fakeTMDB) paths() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var paths []string
+	for _, u := range f.requests {
+		paths = append(paths, u.Path+"?"+u.Query().Get("page"))
+	}
+	return paths
+}
+
+func TestPagedIDsFollowsTotalPages(t *testing.T) {
+	f := &fakeTMDB{lists: map[string][][]int{"/movie/now_playing": {{1, 2}, {3}, {4, 5}}}}
+	client := newTMDBClient(newFakeTMDB(t, f).URL, "key", 0)
+
+	ids, err := client.pagedIDs("/movie/now_playing", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("ids = %v, want every page", ids)
+	}
+	if got := f.paths(); !slices.Equal(got, []string{"/movie/now_playing?1", "/movie/now_playing?2", "/movie/now_playing?3"}) {
+		t.Fatalf("requests = %v, want pages 1 to 3 once", got)
+	}
+
+	ids, err = client.pagedIDs("/movie/now_playing", nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, []int{1, 2, 3}) {
+		t.Fatalf("ids with max 2 pages = %v, want the first two pages", ids)
+	}
+}
+
+func TestGetWaitsForRetryAfter(t *testing.T) {
+	calls := 0
+	f := &fakeTMDB{handle: func(w http.ResponseWriter, r *http.Request) bool {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, `{"status_message":"Too many requests"}`, http.StatusTooManyRequests)
+			return true
+		}
+		if r.URL.Query().Get("api_key") != "key" {
+			http.Error(w, "missing api key", http.StatusUnauthorized)
+			return true
+		}
+		fmt.Fprint(w, `{"id": 7, "title": "Retried"}`)
+		return true
+	}}
+	client := newTMDBClient(newFakeTMDB(t, f).URL, "key", 0)
+
+	start := time.Now()
+	detail, err := client.movie(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Title != "Retried" || calls != 2 {
+		t.Fatalf("title %q after %d calls, want Retried after 2", detail.Title, calls)
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Fatalf("retried after %v, want the 1s Retry-After", waited)
+	}
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	f := &fakeTMDB{handle: func(w http.ResponseWriter, r *http.Request) bool {
+		calls++
+		if r.URL.Path == "/movie/1" {
+			http.Error(w, `{"status_message":"Invalid API key"}`, http.StatusUnauthorized)
+			return true
+		}
+		return false
+	}}
+	client := newTMDBClient(newFakeTMDB(t, f).URL, "key", 0)
+
+	if _, err := client.movie(1); err == nil || errors.Is(err, errNotFound) || calls != 1 {
+		t.Fatalf("401: err = %v after %d calls, want one failed call", err, calls)
+	}
+	calls = 0
+	if _, err := client.movie(2); !errors.Is(err, errNotFound) || calls != 1 {
+		t.Fatalf("404: err = %v after %d calls, want errNotFound after one call", err, calls)
+	}
+}
+
+func TestFetchErrorsLeaveOutTheAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	address := server.URL
+	server.Close()
+
+	client := newTMDBClient(address, "s3cr3t-key", 0)
+	var target idPage
+	retryable, _, err := client.fetch(address+"/movie/1?api_key=s3cr3t-key", "/movie/1", &target)
+	if err == nil || !retryable {
+		t.Fatalf("fetch from a closed server = %v (retryable %v), want a retryable error", err, retryable)
+	}
+	if strings.Contains(err.Error(), "s3cr3t-key") {
+		t.Fatalf("error %q leaks the API key", err)
+	}
+	if !strings.Contains(err.Error(), "/movie/1") || !strings.Contains(err.Error(), "refused") {
+		t.Fatalf("error %q should name the path and the cause", err)
+	}
+}
+
+func TestChangedSinceFetchesFourteenDayWindows(t *testing.T) {
+	f := &fakeTMDB{changes: map[string][]int{
+		"2025-01-01/2025-01-14": {1, 2},
+		"2025-01-15/2025-01-28": {2, 3},
+		"2025-01-29/2025-02-10": {4},
+	}}
+	client := newTMDBClient(newFakeTMDB(t, f).URL, "", 0)
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+	changed, err := changedSince(client, since, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var windows []string
+	for _, u := range f.requests {
+		windows = append(windows, u.Query().Get("start_date")+"/"+u.Query().Get("end_date"))
+	}
+	if want := []string{"2025-01-01/2025-01-14", "2025-01-15/2025-01-28", "2025-01-29/2025-02-10"}; !slices.Equal(windows, want) {
+		t.Fatalf("windows = %v, want %v", windows, want)
+	}
+	if len(changed) != 4 || !changed[1] || !changed[2] || !changed[3] || !changed[4] {
+		t.Fatalf("changed = %v, want movies 1 to 4", changed)
+	}
+}
+
+func TestToRefresh(t *testing.T) {
+	listed := []int{1, 2, 3}
+	stored := map[int]bool{1: true, 2: true, 9: true, 10: true}
+	changed := map[int]bool{2: true, 9: true, 11: true}
+
+	// 3 is new, 2 changed, 9 changed after leaving the lists; 1 and 10 did
+	// not change and 11 was never stored.
+	got := toRefresh(listed, stored, changed)
+	if !slices.Equal(got, []int{2, 3, 9}) {
+		t.Fatalf("toRefresh = %v, want [2 3 9]", got)
+	}
+}
+
+func TestToTMDBMovie(t *testing.T) {
+	var detail movieDetail
+	json.Unmarshal([]byte(`{
+		"id": 5, "title": "Dune", "overview": "Sand", "release_date": "2021-10-22", "runtime": 155,
+		"poster_path": "/poster.jpg", "backdrop_path": "",
+		"genres": [{"name": "Science Fiction"}],
+		"credits": {
+			"crew": [{"id": 1, "name": "Denis Villeneuve", "job": "Director"}, {"id": 2, "name": "Hans Zimmer", "job": "Original Music Composer"}],
+			"cast": [{"id": 3, "name": "Timothée Chalamet", "character": "Paul", "order": 0}, {"id": 4, "name": "Zendaya", "character": "Chani", "order": 1}]
+		}
+	}`), &detail)
+
+	movie := toTMDBMovie(detail, syncOptions{castLimit: 1, imageBaseURL: "https://img"})
+	if movie.Image != "https://img/w500/poster.jpg" || movie.HorizontalImage != "" {
+		t.Errorf("images = %q, %q", movie.Image, movie.HorizontalImage)
+	}
+	if len(movie.Directors) != 1 || movie.Directors[0].Name != "Denis Villeneuve" {
+		t.Errorf("directors = %+v, want only the director", movie.Directors)
+	}
+	if len(movie.Cast) != 1 || movie.Cast[0].Role != "Paul" {
+		t.Errorf("cast = %+v, want the top billed actor", movie.Cast)
+	}
+}
+
+// Sync tests that write to the database run only with DB_TESTS=1. They use
+// TMDB IDs from testTMDBID on and delete what they created.
+const testTMDBID = 990_000_000
+
+func requireDB(t *testing.T) {
+	t.Helper()
+	if os.Getenv("DB_TESTS") != "1" {
+		t.Skip("set DB_TESTS=1 to run against the database configured in .env")
+	}
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		t.Skip("database unavailable:", err)
+	}
+	defer conn.Release()
+
+	cleanup := func() {
+		ctx := context.Background()
+		conn, err := utils.ConnectDB()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Release()
+		for _, table := range []string{"movies", "directors", "actors"} {
+			if _, err := conn.Exec(ctx, `DELETE FROM `+table+` WHERE tmdb_id >= $1`, testTMDBID); err != nil {
+				t.Error(err)
+			}
+		}
+		if _, err := conn.Exec(ctx, `DELETE FROM genres WHERE genre_name LIKE 'TMDB Test %'`); err != nil {
+			t.Error(err)
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+}
+
+func syncTestServer(t *testing.T) *fakeTMDB {
+	credit := func(id int, name string) map[string]any {
+		return map[string]any{"id": testTMDBID + id, "name": name, "job": "Director", "character": name + " role", "order": id}
+	}
+	movie := func(id int, title, releaseDate string, genres ...string) map[string]any {
+		genreList := []any{}
+		for _, g := range genres {
+			genreList = append(genreList, map[string]string{"name": g})
+		}
+		return map[string]any{
+			"id": testTMDBID + id, "title": title, "release_date": releaseDate, "runtime": 100,
+			"genres": genreList,
+			"credits": map[string]any{
+				"crew": []any{credit(100, "TMDB Test Director")},
+				"cast": []any{credit(200, "TMDB Test Actor"), credit(200+id, "TMDB Test Actor "+strconv.Itoa(id))},
+			},
+		}
+	}
+	return &fakeTMDB{
+		lists: map[string][][]int{
+			"/movie/now_playing": {{testTMDBID + 1}, {testTMDBID + 2}},
+			// 3 has no release date yet and 4 is no longer on TMDB.
+			"/movie/upcoming": {{testTMDBID + 2, testTMDBID + 3, testTMDBID + 4}},
+		},
+		movies: map[int]map[string]any{
+			testTMDBID + 1: movie(1, "TMDB Test One", "2025-07-01", "TMDB Test Drama"),
+			testTMDBID + 2: movie(2, "TMDB Test Two", "2025-08-01", "TMDB Test Drama", "TMDB Test Comedy"),
+			testTMDBID + 3: movie(3, "TMDB Test Three", ""),
+		},
+	}
+}
+
+func countTestRows(t *testing.T) map[string]int {
+	t.Helper()
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Release()
+
+	counts := map[string]int{}
+	for _, table := range []string{"movies", "directors", "actors"} {
+		var n int
+		err := conn.QueryRow(context.Background(), `SELECT COUNT(*) FROM `+table+` WHERE tmdb_id >= $1`, testTMDBID).Scan(&n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[table] = n
+	}
+	var n int
+	err = conn.QueryRow(context.Background(), `SELECT COUNT(*) FROM genres WHERE genre_name LIKE 'TMDB Test %'`).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts["genres"] = n
+	return counts
+}
+
+func TestSyncTwiceAddsNoDuplicates(t *testing.T) {
+	requireDB(t)
+	f := syncTestServer(t)
+	client := newTMDBClient(newFakeTMDB(t, f).URL, "", 0)
+	opts := syncOptions{categories: []string{"now_playing", "upcoming"}, castLimit: 6}
+
+	want := map[string]int{"movies": 2, "directors": 1, "actors": 3, "genres": 2}
+	for run := 1; run <= 2; run++ {
+		// Movie 4 is skipped rather than failed, so both runs succeed.
+		if !runSync(client, opts) {
+			t.Fatalf("run %d reported failures", run)
+		}
+		if got := countTestRows(t); !maps.Equal(got, want) {
+			t.Fatalf("after run %d: rows = %v, want %v", run, got, want)
+		}
+	}
+}
+
+func TestSyncDryRunSavesNothing(t *testing.T) {
+	requireDB(t)
+	f := syncTestServer(t)
+	client := newTMDBClient(newFakeTMDB(t, f).URL, "", 0)
+	opts := syncOptions{dryRun: true, categories: []string{"now_playing", "upcoming"}, castLimit: 6}
+
+	if !runSync(client, opts) {
+		t.Fatal("dry run reported failures")
+	}
+	want := map[string]int{"movies": 0, "directors": 0, "actors": 0, "genres": 0}
+	if got := countTestRows(t); !maps.Equal(got, want) {
+		t.Fatalf("rows after a dry run = %v, want none", got)
+	}
+}
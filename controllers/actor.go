@@ -4,7 +4,9 @@ import (
   "be-tickitz/dto"
   "be-tickitz/models"
   "be-tickitz/utils"
+  "errors"
   "net/http"
+  "strconv"
 
   "github.com/gin-gonic/gin"
   "github.com/golang-jwt/jwt/v5"
@@ -36,7 +38,7 @@ func CreateActor(c *gin.Context) {
     return
   }
 
-  actor, err := models.CreateActor(input)
+  actor, err := models.CreateActor(auditActor(c), input)
   if err != nil {
     c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to create actor", Errors: err.Error()})
     return
@@ -51,17 +53,23 @@ func CreateActor(c *gin.Context) {
 // @Tags Actors
 // @Produce json
 // @Security BearerAuth
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: name, id (prefix - for descending)"
+// @Param search query string false "Filter by name"
 // @Success 200 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /actors [get]
 func GetAllActors(c *gin.Context) {
-  actors, err := models.GetAllActors()
+  q := utils.ParsePageQuery(c, 0)
+  actors, page, err := models.GetAllActors(q, models.ScopeLive)
   if err != nil {
     c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch actors", Errors: err.Error()})
     return
   }
 
-  c.JSON(http.StatusOK, utils.Response{Success: true, Message: "All actors", Results: actors})
+  c.JSON(http.StatusOK, utils.Response{Success: true, Message: "All actors", Results: actors, PageInfo: utils.NewPageInfo(c, q, page)})
 }
 
 
@@ -73,7 +81,9 @@ func GetAllActors(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Actor ID"
 // @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /admin/actors/{id} [delete]
 func DeleteActor(c *gin.Context) {
@@ -85,8 +95,16 @@ func DeleteActor(c *gin.Context) {
 		return
 	}
 
-	id := c.Param("id")
-	err := models.DeleteActor(id)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid actor ID"})
+		return
+	}
+	err = models.DeleteActor(auditActor(c), id)
+	if errors.Is(err, models.ErrActorNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Actor not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
       Success: false, 
@@ -96,4 +114,76 @@ func DeleteActor(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Actor deleted"})
-}
\ No newline at end of file
+}
+
+// GetAdminActors godoc
+// @Summary Get all actors for admin
+// @Description Admin only. Actors including deleted ones, which carry a deletedAt
+// @Tags Actors
+// @Produce json
+// @Security BearerAuth
+// @Param deleted query bool false "true for deleted actors only, false for live ones only"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: name, id (prefix - for descending)"
+// @Param search query string false "Filter by name"
+// @Success 200 {object} utils.Response{results=[]models.Actor}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/actors [get]
+func GetAdminActors(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view deleted actors"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	actors, page, err := models.GetAllActors(q, adminCatalogScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch actors", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "All actors", Results: actors, PageInfo: utils.NewPageInfo(c, q, page)})
+}
+
+// RestoreActor godoc
+// @Summary Restore a deleted actor
+// @Description Admin only. Bring back a deleted actor so it shows up in public listings again
+// @Tags Actors
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Actor ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/actors/{id}/restore [post]
+func RestoreActor(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can restore actors"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid actor ID"})
+		return
+	}
+
+	err = models.RestoreActor(auditActor(c), id)
+	if errors.Is(err, models.ErrActorNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Deleted actor not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to restore actor", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Actor restored"})
+}
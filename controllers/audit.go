@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// auditActor identifies the admin making a request for the audit log.
+func auditActor(c *gin.Context) dto.AuditActor {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID, _ := claims["userId"].(float64)
+	return dto.AuditActor{
+		UserID:    int(userID),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// GetAuditLog godoc
+// @Summary Get audit log
+// @Description Admin only. Changes made by admins, with who made them, from where, and the fields before and after the change
+// @Tags Audit
+// @Security BearerAuth
+// @Produce json
+// @Param actorId query int false "Filter by admin user ID"
+// @Param action query string false "Filter by action, e.g. create, update, delete"
+// @Param entityType query string false "Filter by entity: movie, genre, director, actor, payment_method, promo, review, showtime, transaction, user, email, webhook, webhook_delivery"
+// @Param entityId query int false "Filter by entity ID"
+// @Param from query string false "Changes on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Changes on or before this date (YYYY-MM-DD)"
+// @Param search query string false "Search admin email"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, id (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.AuditEntry}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/audit-log [get]
+func GetAuditLog(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view the audit log"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 20)
+	entries, page, err := models.GetAuditLog(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch audit log", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "Audit log",
+		Results:  entries,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"be-tickitz/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminCatalogScope reads the ?deleted= filter of admin catalog listings:
+// "true" lists only deleted rows, "false" only live ones, and without it
+// both are listed.
+func adminCatalogScope(c *gin.Context) models.CatalogScope {
+	switch c.Query("deleted") {
+	case "true":
+		return models.ScopeDeleted
+	case "false":
+		return models.ScopeLive
+	default:
+		return models.ScopeAll
+	}
+}
@@ -4,7 +4,9 @@ import (
   "be-tickitz/dto"
   "be-tickitz/models"
   "be-tickitz/utils"
+  "errors"
   "net/http"
+  "strconv"
 
   "github.com/gin-gonic/gin"
   "github.com/golang-jwt/jwt/v5"
@@ -37,7 +39,7 @@ func CreateDirector(c *gin.Context) {
     return
   }
 
-  director, err := models.CreateDirector(input)
+  director, err := models.CreateDirector(auditActor(c), input)
   if err != nil {
     c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to create director", Errors: err.Error()})
     return
@@ -52,11 +54,17 @@ func CreateDirector(c *gin.Context) {
 // @Tags Directors
 // @Produce json
 // @Security BearerAuth
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: name, id (prefix - for descending)"
+// @Param search query string false "Filter by name"
 // @Success 200 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /directors [get]
 func GetAllDirectors(c *gin.Context) {
-  directors, err := models.GetAllDirectors()
+  q := utils.ParsePageQuery(c, 0)
+  directors, page, err := models.GetAllDirectors(q, models.ScopeLive)
   if err != nil {
     c.JSON(http.StatusInternalServerError, utils.Response{
       Success: false, 
@@ -70,6 +78,7 @@ func GetAllDirectors(c *gin.Context) {
     Success: true, 
     Message: "All directors", 
     Results: directors,
+    PageInfo: utils.NewPageInfo(c, q, page),
   })
 }
 
@@ -82,7 +91,9 @@ func GetAllDirectors(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Director ID"
 // @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /admin/directors/{id} [delete]
 func DeleteDirector(c *gin.Context) {
@@ -94,8 +105,16 @@ func DeleteDirector(c *gin.Context) {
 		return
 	}
 
-	id := c.Param("id")
-	err := models.DeleteDirector(id)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid director ID"})
+		return
+	}
+	err = models.DeleteDirector(auditActor(c), id)
+	if errors.Is(err, models.ErrDirectorNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Director not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
       Success: false, 
@@ -105,4 +124,76 @@ func DeleteDirector(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Director deleted"})
-}
\ No newline at end of file
+}
+
+// GetAdminDirectors godoc
+// @Summary Get all directors for admin
+// @Description Admin only. Directors including deleted ones, which carry a deletedAt
+// @Tags Directors
+// @Produce json
+// @Security BearerAuth
+// @Param deleted query bool false "true for deleted directors only, false for live ones only"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: name, id (prefix - for descending)"
+// @Param search query string false "Filter by name"
+// @Success 200 {object} utils.Response{results=[]models.Director}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/directors [get]
+func GetAdminDirectors(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view deleted directors"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	directors, page, err := models.GetAllDirectors(q, adminCatalogScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch directors", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "All directors", Results: directors, PageInfo: utils.NewPageInfo(c, q, page)})
+}
+
+// RestoreDirector godoc
+// @Summary Restore a deleted director
+// @Description Admin only. Bring back a deleted director so it shows up in public listings again
+// @Tags Directors
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Director ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/directors/{id}/restore [post]
+func RestoreDirector(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can restore directors"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid director ID"})
+		return
+	}
+
+	err = models.RestoreDirector(auditActor(c), id)
+	if errors.Is(err, models.ErrDirectorNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Deleted director not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to restore director", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Director restored"})
+}
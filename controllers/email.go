@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// GetOutboxEmails godoc
+// @Summary List outgoing emails
+// @Description Admin only. Emails in the delivery outbox. Shows the dead letters (emails that ran out of retries) unless another status is asked for
+// @Tags Emails
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "queued, sending, sent or dead" default(dead)
+// @Param search query string false "Search recipient or subject"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, id (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.OutboxEmail}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/emails [get]
+func GetOutboxEmails(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view emails"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 20)
+	emails, page, err := models.GetOutboxEmails(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch emails", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "Outbox emails",
+		Results:  emails,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// RetryEmail godoc
+// @Summary Retry a dead email
+// @Description Admin only. Put a dead letter back in the queue with a fresh set of attempts
+// @Tags Emails
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Email ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/emails/{id}/retry [post]
+func RetryEmail(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can retry emails"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid email ID"})
+		return
+	}
+
+	err = models.RetryEmail(auditActor(c), id)
+	if errors.Is(err, models.ErrEmailNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Dead email not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to retry email", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Email queued again"})
+}
@@ -4,7 +4,10 @@ import (
 	"be-tickitz/dto"
 	"be-tickitz/models"
 	"be-tickitz/utils"
+	"errors"
+	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -45,7 +48,7 @@ func CreateGenre(c *gin.Context) {
 		return
 	}
 
-	created, err := models.CreateGenre(genre)
+	created, err := models.CreateGenre(auditActor(c), genre)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -83,7 +86,7 @@ func AddGenretoMovie(c *gin.Context) {
 		return
 	}
 
-	err := models.AddGenretoMovie(req.IDMovie, req.IDGenre)
+	err := models.AddGenretoMovie(auditActor(c), req.IDMovie, req.IDGenre)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -105,11 +108,26 @@ func AddGenretoMovie(c *gin.Context) {
 // @Description Retrieve all available genres
 // @Tags Genres
 // @Produce json
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: name, id (prefix - for descending)"
+// @Param search query string false "Filter by name"
 // @Success 200 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /genres [get]
 func GetAllGenres(c *gin.Context) {
-	genres, err := models.GetAllGenres()
+	q := utils.ParsePageQuery(c, 0)
+	cacheKey := "/genres?" + c.Request.URL.Query().Encode()
+
+	cached, fromCache, err := utils.CacheRemember(context.Background(), cacheKey, utils.CacheTTL(), []string{models.CacheTagGenres},
+		func() (cachedPage[[]models.Genre], error) {
+			genres, page, err := models.GetAllGenres(q, models.ScopeLive)
+			if err != nil {
+				return cachedPage[[]models.Genre]{}, err
+			}
+			return cachedPage[[]models.Genre]{Results: genres, PageInfo: utils.NewPageInfo(c, q, page)}, nil
+		})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -119,10 +137,13 @@ func GetAllGenres(c *gin.Context) {
 		return
 	}
 
+	utils.SetCacheStatus(c, fromCache)
+	utils.SetLastModified(c, utils.CacheTagsModifiedAt(c.Request.Context(), []string{models.CacheTagGenres}))
 	c.JSON(http.StatusOK, utils.Response{
 		Success: true,
 		Message: "All genres",
-		Results: genres,
+		Results: cached.Results,
+		PageInfo: cached.PageInfo,
 	})
 }
 
@@ -134,7 +155,9 @@ func GetAllGenres(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Genre ID"
 // @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /admin/genres/{id} [delete]
 func DeleteGenre(c *gin.Context) {
@@ -144,8 +167,16 @@ func DeleteGenre(c *gin.Context) {
 		return
 	}
 
-	id := c.Param("id")
-	err := models.DeleteGenre(id)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid genre ID"})
+		return
+	}
+	err = models.DeleteGenre(auditActor(c), id)
+	if errors.Is(err, models.ErrGenreNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Genre not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to delete genre", Errors: err.Error()})
 		return
@@ -153,3 +184,75 @@ func DeleteGenre(c *gin.Context) {
 
 	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Genre deleted"})
 }
+
+// GetAdminGenres godoc
+// @Summary Get all genres for admin
+// @Description Admin only. Genres including deleted ones, which carry a deletedAt
+// @Tags Genres
+// @Produce json
+// @Security BearerAuth
+// @Param deleted query bool false "true for deleted genres only, false for live ones only"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: name, id (prefix - for descending)"
+// @Param search query string false "Filter by name"
+// @Success 200 {object} utils.Response{results=[]models.Genre}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/genres [get]
+func GetAdminGenres(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view deleted genres"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	genres, page, err := models.GetAllGenres(q, adminCatalogScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch genres", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "All genres", Results: genres, PageInfo: utils.NewPageInfo(c, q, page)})
+}
+
+// RestoreGenre godoc
+// @Summary Restore a deleted genre
+// @Description Admin only. Bring back a deleted genre so it shows up in public listings again
+// @Tags Genres
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Genre ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/genres/{id}/restore [post]
+func RestoreGenre(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can restore genres"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid genre ID"})
+		return
+	}
+
+	err = models.RestoreGenre(auditActor(c), id)
+	if errors.Is(err, models.ErrGenreNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Deleted genre not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to restore genre", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Genre restored"})
+}
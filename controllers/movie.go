@@ -4,12 +4,13 @@ import (
 	"be-tickitz/dto"
 	"be-tickitz/models"
 	"be-tickitz/utils"
+	"errors"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"net/url"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +19,16 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// cachedPage is the cache payload for a list page.
+type cachedPage[T any] struct {
+	Results  T               `json:"results"`
+	PageInfo *utils.PageInfo `json:"pageInfo"`
+}
+
+// showingCacheTTL is kept short because now-showing and upcoming lists also
+// change as time passes, not only when the catalog is edited.
+const showingCacheTTL = 5 * time.Minute
+
 // CreateMovie godoc
 // @Summary Create new movie
 // @Description Admin only. Add a new movie with metadata and relations
@@ -53,7 +64,7 @@ func CreateMovie(c *gin.Context) {
 		return
 	}
 
-	created, err := models.CreateMovie(movie)
+	created, err := models.CreateMovie(auditActor(c), movie)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -74,6 +85,8 @@ func CreateMovie(c *gin.Context) {
 		GenreIDs:        movie.GenreIDs,
 		DirectorIDs:     movie.DirectorIDs,
 		CastIDs:         movie.CastIDs,
+		Crew:            movie.Crew,
+		Casts:           movie.Casts,
 	}
 
 	c.JSON(http.StatusOK, utils.Response{
@@ -89,75 +102,133 @@ func CreateMovie(c *gin.Context) {
 // @Tags Movies
 // @Produce json
 // @Param search query string false "Search keyword"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: id, title, releaseDate, duration, relevance (prefix - for descending)"
 // @Success 200 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /movies [get]
 func GetAllMovies(c *gin.Context) {
-	ctx := context.Background()
+	q := utils.ParsePageQuery(c, 0)
+	cacheKey := "/movies?" + c.Request.URL.Query().Encode()
+	tags := []string{models.CacheTagMovies}
 
-	err := utils.RedisClient().Ping(ctx).Err()
-	noredis := false
-	if err != nil {
-		log.Println("Redis unavailable:", err.Error())
-		noredis = true
-	}
-
-	search := c.DefaultQuery("search", "")
-	cacheKey := "/movies?search=" + search
-
-	if !noredis {
-		val, err := utils.RedisClient().Get(ctx, cacheKey).Result()
-		if err == nil {
-			var cached []dto.MovieList
-			if err := json.Unmarshal([]byte(val), &cached); err == nil {
-				c.JSON(http.StatusOK, utils.Response{
-					Success: true,
-					Message: "All movies (from Redis)",
-					Results: cached,
+	cached, fromCache, err := utils.CacheRemember(context.Background(), cacheKey, utils.CacheTTL(), tags,
+		func() (cachedPage[[]dto.MovieList], error) {
+			rawMovies, page, err := models.GetAllMovies(q, models.ScopeLive)
+			if err != nil {
+				return cachedPage[[]dto.MovieList]{}, err
+			}
+
+			var movies []dto.MovieList
+			for _, m := range rawMovies {
+				movies = append(movies, dto.MovieList{
+					ID:              m.ID,
+					Title:           m.Title,
+					Description:     m.Description,
+					ReleaseDate:     m.ReleaseDate,
+					Duration:        m.Duration,
+					Image:           m.Image,
+					HorizontalImage: m.HorizontalImage,
 				})
-				return
 			}
-		}
+			return cachedPage[[]dto.MovieList]{Results: movies, PageInfo: utils.NewPageInfo(c, q, page)}, nil
+		})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to fetch movies",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	utils.SetCacheStatus(c, fromCache)
+	utils.SetLastModified(c, utils.CacheTagsModifiedAt(c.Request.Context(), tags))
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "All movies",
+		Results:  cached.Results,
+		PageInfo: cached.PageInfo,
+	})
+}
+
+// SuggestMovies godoc
+// @Summary Autocomplete suggestions
+// @Description Suggest movie titles, actors and genres whose name has a word starting with q
+// @Tags Movies
+// @Produce json
+// @Param q query string true "Prefix typed by the user"
+// @Param limit query int false "Max suggestions per type" default(5)
+// @Success 200 {object} utils.Response{results=dto.Suggestions}
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /movies/suggest [get]
+func SuggestMovies(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Query parameter q is required",
+		})
+		return
 	}
 
-	var rawMovies []models.Movie
-	if search == "" {
-		rawMovies, err = models.GetAllMovies()
-	} else {
-		rawMovies, err = models.SearchMovies(search)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit <= 0 || limit > 20 {
+		limit = 5
 	}
 
+	suggestions, err := models.Suggest(q, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
-			Message: "Failed to fetch movies",
+			Message: "Failed to fetch suggestions",
 			Errors:  err.Error(),
 		})
 		return
 	}
 
-	var movies []dto.MovieList
-	for _, m := range rawMovies {
-		movies = append(movies, dto.MovieList{
-			ID:              m.ID,
-			Title:           m.Title,
-			Description:     m.Description,
-			ReleaseDate:     m.ReleaseDate,
-			Duration:        m.Duration,
-			Image:           m.Image,
-			HorizontalImage: m.HorizontalImage,
-		})
+	c.JSON(http.StatusOK, utils.Response{
+		Success: true,
+		Message: "Suggestions",
+		Results: suggestions,
+	})
+}
+
+// GetRecommendedMovies godoc
+// @Summary Recommended movies
+// @Description Now-showing movies ranked by how well they match the genres, directors and cast of your past bookings, or by popularity when you have none
+// @Tags Movies
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Max movies" default(10)
+// @Success 200 {object} utils.Response{results=[]dto.RecommendedMovie}
+// @Failure 500 {object} utils.Response
+// @Router /movies/recommended [get]
+func GetRecommendedMovies(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 10
 	}
 
-	if !noredis {
-		if encoded, err := json.Marshal(movies); err == nil {
-			utils.RedisClient().Set(ctx, cacheKey, encoded, 0)
-		}
+	movies, err := models.GetRecommendations(userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to fetch recommendations",
+			Errors:  err.Error(),
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, utils.Response{
 		Success: true,
-		Message: "All movies",
+		Message: "Recommended for you",
 		Results: movies,
 	})
 }
@@ -169,52 +240,39 @@ func GetAllMovies(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Movie ID"
 // @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
 // @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
 // @Router /movies/{id} [get]
 func GetMovieByID(c *gin.Context) {
-	ctx := context.Background()
-
-	id := c.Param("id")
-	cacheKey := "/movies/" + id
-
-	err := utils.RedisClient().Ping(ctx).Err()
-	noredis := false
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		log.Println("Redis unavailable:", err.Error())
-		noredis = true
-	}
-
-	if !noredis {
-		val, err := utils.RedisClient().Get(ctx, cacheKey).Result()
-		if err == nil {
-			var cached dto.MovieDetail
-			if err := json.Unmarshal([]byte(val), &cached); err == nil {
-				c.JSON(http.StatusOK, utils.Response{
-					Success: true,
-					Message: "Movie details (from Redis)",
-					Results: cached,
-				})
-				return
-			}
-		}
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+		return
 	}
+	cacheKey := "/movies/" + strconv.Itoa(id)
+	tags := []string{models.MovieCacheTag(id), models.CacheTagGenres, models.CacheTagPeople}
 
-	movie, err := models.GetMovieByID(id)
+	movie, fromCache, err := utils.CacheRemember(context.Background(), cacheKey, utils.CacheTTL(), tags,
+		func() (dto.MovieDetail, error) {
+			return models.GetMovieByID(id)
+		})
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Movie not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, utils.Response{
+		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
-			Message: "Movie not found",
+			Message: "Failed to fetch movie",
 			Errors:  err.Error(),
 		})
 		return
 	}
 
-	if !noredis {
-		if encoded, err := json.Marshal(movie); err == nil {
-			utils.RedisClient().Set(ctx, cacheKey, encoded, 0)
-		}
-	}
-
+	utils.SetCacheStatus(c, fromCache)
+	utils.SetLastModified(c, movie.UpdatedAt)
+	utils.SetLastModified(c, utils.CacheTagsModifiedAt(c.Request.Context(), tags))
 	c.JSON(http.StatusOK, utils.Response{
 		Success: true,
 		Message: "Movie details",
@@ -224,25 +282,36 @@ func GetMovieByID(c *gin.Context) {
 
 // GetNowShowing godoc
 // @Summary Get now showing movies
-// @Description Retrieve list of currently showing movies with search, genre filter, sort, and pagination
+// @Description Retrieve movies with bookable showtimes inside the run window, with search, genre, city and cinema filters, sort, and pagination
 // @Tags Movies
 // @Produce json
-// @Param search query string false "Search by title"
+// @Param search query string false "Search by title, description, genre or credits"
 // @Param genres query string false "Comma-separated genre IDs"
-// @Param sort query string false "Sort by: latest, name-asc, name-desc"
+// @Param location query string false "Filter by city"
+// @Param cinema query string false "Filter by cinema"
+// @Param sort query string false "Sort by: latest, name-asc, name-desc, or a key (id, title, releaseDate, duration, relevance) with optional - prefix"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(12)
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
 // @Success 200 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /movies/now-showing [get]
 func GetNowShowing(c *gin.Context) {
-	ctx := context.Background()
-
-	search := c.DefaultQuery("search", "")
 	genresStr := c.DefaultQuery("genres", "")
-	sort := c.DefaultQuery("sort", "latest")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "12"))
+	filter := dto.ShowtimeFilter{
+		Location: c.Query("location"),
+		Cinema:   c.Query("cinema"),
+	}
+
+	q := utils.ParsePageQuery(c, 12)
+	switch c.DefaultQuery("sort", "latest") {
+	case "latest":
+		q.Sort, q.Desc = "releaseDate", true
+	case "name-asc":
+		q.Sort, q.Desc = "title", false
+	case "name-desc":
+		q.Sort, q.Desc = "title", true
+	}
 
 	genres := []int{}
 	if genresStr != "" {
@@ -253,35 +322,17 @@ func GetNowShowing(c *gin.Context) {
 		}
 	}
 
-	cacheKey := fmt.Sprintf("/movies/now-showing?search=%s&genres=%s&sort=%s&page=%d&limit=%d",
-		url.QueryEscape(search), url.QueryEscape(genresStr), sort, page, limit)
-
-	err := utils.RedisClient().Ping(ctx).Err()
-	if err == nil {
-		val, err := utils.RedisClient().Get(ctx, cacheKey).Result()
-		if err == nil {
-			var cached []models.Movie
-			if err := json.Unmarshal([]byte(val), &cached); err == nil {
-				var totalRows int
-				if totalStr, err := utils.RedisClient().Get(ctx, cacheKey+":total").Result(); err == nil {
-					totalRows, _ = strconv.Atoi(totalStr)
-				}
-				c.JSON(http.StatusOK, utils.Response{
-					Success: true,
-					Message: "Now showing (from Redis)",
-					Results: map[string]interface{}{
-						"movies": cached,
-						"total":  totalRows,
-					},
-				})
-				return
-			}
-		}
-	} else {
-		log.Println("Redis error:", err.Error())
-	}
+	cacheKey := "/movies/now-showing?" + c.Request.URL.Query().Encode()
+	tags := []string{models.CacheTagMovies, models.CacheTagShowtimes, models.CacheTagReviews}
 
-	movies, totalRows, err := models.GetNowShowing(search, genres, sort, page, limit)
+	cached, fromCache, err := utils.CacheRemember(context.Background(), cacheKey, showingCacheTTL, tags,
+		func() (cachedPage[[]models.Movie], error) {
+			movies, page, err := models.GetNowShowing(genres, filter, q)
+			if err != nil {
+				return cachedPage[[]models.Movie]{}, err
+			}
+			return cachedPage[[]models.Movie]{Results: movies, PageInfo: utils.NewPageInfo(c, q, page)}, nil
+		})
 	if err != nil {
 		log.Println("Database error:", err.Error())
 		c.JSON(http.StatusInternalServerError, utils.Response{
@@ -292,65 +343,51 @@ func GetNowShowing(c *gin.Context) {
 		return
 	}
 
-	if err == nil {
-		if encoded, err := json.Marshal(movies); err == nil {
-			err = utils.RedisClient().Set(ctx, cacheKey, encoded, 1*time.Hour).Err()
-			if err != nil {
-				log.Println("Failed to cache to Redis:", err.Error())
-			}
-			err = utils.RedisClient().Set(ctx, cacheKey+":total", totalRows, 1*time.Hour).Err()
-			if err != nil {
-				log.Println("Failed to cache total to Redis:", err.Error())
-			}
-		}
-	}
-
+	utils.SetCacheStatus(c, fromCache)
+	utils.SetLastModified(c, utils.CacheTagsModifiedAt(c.Request.Context(), tags))
 	c.JSON(http.StatusOK, utils.Response{
 		Success: true,
 		Message: "Now showing",
 		Results: map[string]interface{}{
-			"movies": movies,
-			"total":  totalRows,
+			"movies": cached.Results,
+			"total":  cached.PageInfo.Total,
 		},
+		PageInfo: cached.PageInfo,
 	})
 }
 
 // GetUpcoming godoc
 // @Summary Get upcoming movies
-// @Description Retrieve list of upcoming movies
+// @Description Retrieve movies not yet bookable inside the run window, optionally scheduled in a city or cinema
 // @Tags Movies
 // @Produce json
+// @Param location query string false "Filter by city"
+// @Param cinema query string false "Filter by cinema"
+// @Param search query string false "Filter by title"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: releaseDate, title, duration, id (prefix - for descending)"
 // @Success 200 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /movies/upcoming [get]
 func GetUpcoming(c *gin.Context) {
-	ctx := context.Background()
-
-	cacheKey := "/movies/upcoming"
-
-	err := utils.RedisClient().Ping(ctx).Err()
-	noredis := false
-	if err != nil {
-		log.Println("Redis unavailable:", err.Error())
-		noredis = true
-	}
-
-	if !noredis {
-		val, err := utils.RedisClient().Get(ctx, cacheKey).Result()
-		if err == nil {
-			var cached []models.Movie
-			if err := json.Unmarshal([]byte(val), &cached); err == nil {
-				c.JSON(http.StatusOK, utils.Response{
-					Success: true,
-					Message: "Upcoming movies (from Redis)",
-					Results: cached,
-				})
-				return
-			}
-		}
+	filter := dto.ShowtimeFilter{
+		Location: c.Query("location"),
+		Cinema:   c.Query("cinema"),
 	}
+	q := utils.ParsePageQuery(c, 0)
+	cacheKey := "/movies/upcoming?" + c.Request.URL.Query().Encode()
+	tags := []string{models.CacheTagMovies, models.CacheTagShowtimes}
 
-	movies, err := models.GetUpcoming()
+	cached, fromCache, err := utils.CacheRemember(context.Background(), cacheKey, showingCacheTTL, tags,
+		func() (cachedPage[[]models.Movie], error) {
+			movies, page, err := models.GetUpcoming(filter, q)
+			if err != nil {
+				return cachedPage[[]models.Movie]{}, err
+			}
+			return cachedPage[[]models.Movie]{Results: movies, PageInfo: utils.NewPageInfo(c, q, page)}, nil
+		})
 	if err != nil {
 		log.Println(err.Error())
 		c.JSON(http.StatusInternalServerError, utils.Response{
@@ -361,16 +398,13 @@ func GetUpcoming(c *gin.Context) {
 		return
 	}
 
-	if !noredis {
-		if encoded, err := json.Marshal(movies); err == nil {
-			utils.RedisClient().Set(ctx, cacheKey, encoded, 0)
-		}
-	}
-
+	utils.SetCacheStatus(c, fromCache)
+	utils.SetLastModified(c, utils.CacheTagsModifiedAt(c.Request.Context(), tags))
 	c.JSON(http.StatusOK, utils.Response{
-		Success: true,
-		Message: "Upcoming movies",
-		Results: movies,
+		Success:  true,
+		Message:  "Upcoming movies",
+		Results:  cached.Results,
+		PageInfo: cached.PageInfo,
 	})
 }
 
@@ -382,7 +416,9 @@ func GetUpcoming(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Movie ID"
 // @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /admin/movies/{id} [delete]
 func DeleteMovie(c *gin.Context) {
@@ -392,8 +428,16 @@ func DeleteMovie(c *gin.Context) {
 		return
 	}
 
-	id := c.Param("id")
-	err := models.DeleteMovie(id)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+		return
+	}
+	err = models.DeleteMovie(auditActor(c), id)
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Movie not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to delete movie", Errors: err.Error()})
 		return
@@ -439,7 +483,7 @@ func UpdateMovie(c *gin.Context) {
 		return
 	}
 
-	if err := models.UpdateMovie(id, input); err != nil {
+	if err := models.UpdateMovie(auditActor(c), id, input); err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
 			Message: "Failed to update movie",
@@ -453,3 +497,215 @@ func UpdateMovie(c *gin.Context) {
 		Message: "Movie updated successfully",
 	})
 }
+
+// GetAdminMovies godoc
+// @Summary Get all movies for admin
+// @Description Admin only. Movies including deleted ones, which carry a deletedAt
+// @Tags Movies
+// @Produce json
+// @Security BearerAuth
+// @Param deleted query bool false "true for deleted movies only, false for live ones only"
+// @Param search query string false "Search keyword"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: id, title, releaseDate, duration, relevance (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.MovieList}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/movies [get]
+func GetAdminMovies(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view deleted movies"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	rawMovies, page, err := models.GetAllMovies(q, adminCatalogScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch movies", Errors: err.Error()})
+		return
+	}
+
+	movies := []dto.MovieList{}
+	for _, m := range rawMovies {
+		movies = append(movies, dto.MovieList{
+			ID:              m.ID,
+			Title:           m.Title,
+			Description:     m.Description,
+			ReleaseDate:     m.ReleaseDate,
+			Duration:        m.Duration,
+			Image:           m.Image,
+			HorizontalImage: m.HorizontalImage,
+			DeletedAt:       m.DeletedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "All movies", Results: movies, PageInfo: utils.NewPageInfo(c, q, page)})
+}
+
+// RestoreMovie godoc
+// @Summary Restore a deleted movie
+// @Description Admin only. Bring back a deleted movie so it shows up in public listings again
+// @Tags Movies
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Movie ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/movies/{id}/restore [post]
+func RestoreMovie(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can restore movies"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+		return
+	}
+
+	err = models.RestoreMovie(auditActor(c), id)
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Deleted movie not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to restore movie", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Movie restored"})
+}
+
+// maxMovieImportSize caps the size of an uploaded movie import file.
+const maxMovieImportSize = 10 << 20
+
+// ImportMovies godoc
+// @Summary Import movies
+// @Description Admin only. Create or update movies from a JSON or CSV file in the format of the export. Movies are matched by externalId, genres, directors and actors by name, and missing ones are created. Nothing is imported when any row is invalid; the errors of each row are returned instead. An existing movie keeps its release date and duration when the file leaves them empty. With dryRun=true the import is checked and counted without saving anything
+// @Tags Movies
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Movies as a JSON array or CSV file"
+// @Param format query string false "json or csv, defaults to the file extension"
+// @Param dryRun query bool false "Check the file without saving it"
+// @Success 200 {object} utils.Response{results=dto.MovieImportResult}
+// @Failure 400 {object} utils.Response{results=dto.MovieImportResult}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/movies/import [post]
+func ImportMovies(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can import movies"})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid dryRun", Errors: "dryRun must be true or false"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMovieImportSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Upload the movies as the file field", Errors: err.Error()})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(header.Filename), "."))
+	}
+	if !slices.Contains(models.MovieFileFormats, format) {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Invalid format",
+			Errors:  "format must be one of: " + strings.Join(models.MovieFileFormats, ", "),
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Failed to read file", Errors: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := models.ImportMovies(auditActor(c), file, format, dryRun)
+	if errors.Is(err, models.ErrImportInvalid) {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid import file", Errors: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to import movies", Errors: err.Error()})
+		return
+	}
+	if result.Failed > 0 {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Some movies are invalid, nothing was imported", Results: result})
+		return
+	}
+
+	message := "Movies imported"
+	if dryRun {
+		message = "Movies checked, nothing was saved"
+	}
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: message, Results: result})
+}
+
+// ExportMovies godoc
+// @Summary Export movies
+// @Description Admin only. Download movies with their genres, directors and cast in the format the import reads
+// @Tags Movies
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "json or csv" default(json)
+// @Param deleted query bool false "true for deleted movies only, false for live ones only"
+// @Param search query string false "Search title or external ID"
+// @Param sort query string false "Sort by: id, title (prefix - for descending)"
+// @Success 200 {array} dto.MovieRecord
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/movies/export [get]
+func ExportMovies(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can export movies"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if !slices.Contains(models.MovieFileFormats, format) {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Invalid format",
+			Errors:  "format must be one of: " + strings.Join(models.MovieFileFormats, ", "),
+		})
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="movies-%s.%s"`, time.Now().Format("20060102"), format))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := models.ExportMovies(c.Writer, format, adminCatalogScope(c), utils.ParsePageQuery(c, 0)); err != nil {
+		log.Printf("Export of movies failed: %v", err)
+		c.Abort()
+	}
+}
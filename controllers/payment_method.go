@@ -4,7 +4,9 @@ import (
 	"be-tickitz/dto"
 	"be-tickitz/models"
 	"be-tickitz/utils"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -45,7 +47,7 @@ func CreatePaymentMethod(c *gin.Context) {
 		return
 	}
 
-	method, err := models.CreatePaymentMethod(req.PaymentName)
+	method, err := models.CreatePaymentMethod(auditActor(c), req.PaymentName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -72,7 +74,7 @@ func CreatePaymentMethod(c *gin.Context) {
 // @Failure 500 {object} utils.Response
 // @Router /payment-method [get]
 func GetAllPaymentMethod(c *gin.Context) {
-  payment_method, err := models.GetAllPaymentMethod()
+  payment_method, err := models.GetAllPaymentMethod(models.ScopeLive)
   if err != nil {
     c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false, 
@@ -95,7 +97,9 @@ func GetAllPaymentMethod(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Payment Method ID"
 // @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /admin/payment-method/{id} [delete]
 func DeletePaymentMethod(c *gin.Context) {
@@ -107,8 +111,16 @@ func DeletePaymentMethod(c *gin.Context) {
 		return
 	}
 
-	id := c.Param("id")
-	err := models.DeletePaymentMethod(id)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid payment method ID"})
+		return
+	}
+	err = models.DeletePaymentMethod(auditActor(c), id)
+	if errors.Is(err, models.ErrPaymentMethodNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Payment method not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
       Success: false, 
@@ -120,4 +132,70 @@ func DeletePaymentMethod(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.Response{
 		Success: true, 
 		Message: "Payment method deleted"})
-}
\ No newline at end of file
+}
+
+// GetAdminPaymentMethods godoc
+// @Summary Get all payment methods for admin
+// @Description Admin only. Payment methods including deleted ones, which carry a deletedAt
+// @Tags Payment Method
+// @Produce json
+// @Security BearerAuth
+// @Param deleted query bool false "true for deleted payment methods only, false for live ones only"
+// @Success 200 {object} utils.Response{results=[]models.PaymentMethod}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/payment-method [get]
+func GetAdminPaymentMethods(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view deleted payment methods"})
+		return
+	}
+
+	methods, err := models.GetAllPaymentMethod(adminCatalogScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch payment method", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "All payment method", Results: methods})
+}
+
+// RestorePaymentMethod godoc
+// @Summary Restore a deleted payment method
+// @Description Admin only. Bring back a deleted payment method so it shows up in public listings again
+// @Tags Payment Method
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Payment Method ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/payment-method/{id}/restore [post]
+func RestorePaymentMethod(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can restore payment methods"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid payment method ID"})
+		return
+	}
+
+	err = models.RestorePaymentMethod(auditActor(c), id)
+	if errors.Is(err, models.ErrPaymentMethodNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Deleted payment method not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to restore payment method", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Payment method restored"})
+}
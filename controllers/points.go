@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// GetMyPoints godoc
+// @Summary Get my loyalty points
+// @Description Points balance, tier from the last 12 months of spend, and the points history
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param type query string false "Filter history by type: earn, redeem, reversal, refund, adjust"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Success 200 {object} utils.Response{results=dto.PointsResponse}
+// @Failure 500 {object} utils.Response
+// @Router /profile/points [get]
+func GetMyPoints(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	summary, err := models.GetPointsSummary(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch points", Errors: err.Error()})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 20)
+	history, page, err := models.GetPointsHistory(userID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch points history", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "Your points",
+		Results:  dto.PointsResponse{Summary: summary, History: history},
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
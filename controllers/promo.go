@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// CreatePromo godoc
+// @Summary Create promo code
+// @Description Admin only. Add a percentage or fixed discount code with optional limits, validity window and movie/cinema/payment method restrictions
+// @Tags Promos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePromoRequest true "Promo data"
+// @Success 200 {object} utils.Response{results=dto.Promo}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/promos [post]
+func CreatePromo(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can add promos"})
+		return
+	}
+
+	var input dto.CreatePromoRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	promo, err := models.CreatePromo(auditActor(c), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Failed to create promo", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Promo created", Results: promo})
+}
+
+// GetAllPromos godoc
+// @Summary Get all promo codes
+// @Description Admin only. List promo codes with their usage
+// @Tags Promos
+// @Security BearerAuth
+// @Produce json
+// @Param search query string false "Search code or description"
+// @Param isActive query bool false "Filter by active flag"
+// @Param discountType query string false "Filter by percent or fixed"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, code, endsAt, id (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.Promo}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/promos [get]
+func GetAllPromos(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view promos"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	promos, page, err := models.GetAllPromos(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch promos", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "All promos",
+		Results:  promos,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// GetPromoByID godoc
+// @Summary Get promo code
+// @Description Admin only. Retrieve a promo code by ID
+// @Tags Promos
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Promo ID"
+// @Success 200 {object} utils.Response{results=dto.Promo}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/promos/{id} [get]
+func GetPromoByID(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view promos"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid promo ID"})
+		return
+	}
+
+	promo, err := models.GetPromoByID(id)
+	if errors.Is(err, models.ErrPromoNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Promo not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch promo", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Promo details", Results: promo})
+}
+
+// UpdatePromo godoc
+// @Summary Update promo code
+// @Description Admin only. Change the fields that are sent; 0 clears maxDiscount, usageLimit and perUserLimit, and a restriction list replaces the current one
+// @Tags Promos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Promo ID"
+// @Param request body dto.UpdatePromoRequest true "Fields to change"
+// @Success 200 {object} utils.Response{results=dto.Promo}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/promos/{id} [patch]
+func UpdatePromo(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can update promos"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid promo ID"})
+		return
+	}
+
+	var input dto.UpdatePromoRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	promo, err := models.UpdatePromo(auditActor(c), id, input)
+	if errors.Is(err, models.ErrPromoNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Promo not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Failed to update promo", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Promo updated", Results: promo})
+}
+
+// DeletePromo godoc
+// @Summary Delete promo code
+// @Description Admin only. Delete a promo code; transactions keep the code and discount they were given
+// @Tags Promos
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Promo ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/promos/{id} [delete]
+func DeletePromo(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can delete promos"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid promo ID"})
+		return
+	}
+
+	err = models.DeletePromo(auditActor(c), id)
+	if errors.Is(err, models.ErrPromoNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Promo not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to delete promo", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Promo deleted"})
+}
+
+// ValidatePromo godoc
+// @Summary Preview a promo code
+// @Description Check a promo code against an order for a scheduled showtime and return the discount it would give at the showtime price, without using it
+// @Tags Promos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ValidatePromoRequest true "Order to check"
+// @Success 200 {object} utils.Response{results=dto.PromoQuote}
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /promos/validate [post]
+func ValidatePromo(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	var input dto.ValidatePromoRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	quote, err := models.QuotePromo(userID, input)
+	if errors.Is(err, models.ErrPromoInvalid) {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to validate promo", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Promo code is valid", Results: quote})
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// reportRange reads the from and to query dates (YYYY-MM-DD, inclusive).
+// Without them a report covers the last 30 days.
+func reportRange(c *gin.Context) (time.Time, time.Time, error) {
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	from, to := today.AddDate(0, 0, -29), today
+
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		to = t
+	}
+	if from.After(to) {
+		return from, to, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
+
+// exportFormat reads the format query of an exportable endpoint, falling back
+// to fallback ("" meaning JSON). It answers 400 and returns false when the
+// format is not supported.
+func exportFormat(c *gin.Context, fallback string) (string, bool) {
+	format := c.DefaultQuery("format", fallback)
+	if format == "" || slices.Contains(utils.ExportFormats, format) {
+		return format, true
+	}
+	c.JSON(http.StatusBadRequest, utils.Response{
+		Success: false,
+		Message: "Invalid format",
+		Errors:  "format must be one of: " + strings.Join(utils.ExportFormats, ", "),
+	})
+	return "", false
+}
+
+// writeExport streams a file with the header row and whatever rows writes.
+// Once the file has started the status can no longer change, so a failure
+// leaves the file unfinished and is only logged.
+func writeExport(c *gin.Context, format, name string, header []any, rows func(write func(values ...any) error) error) {
+	w, err := utils.StartExport(c, format, name)
+	if err == nil {
+		err = w.WriteRow(header...)
+	}
+	if err == nil {
+		err = rows(w.WriteRow)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		log.Printf("Export of %s failed: %v", name, err)
+		c.Abort()
+	}
+}
+
+// GetSalesReport godoc
+// @Summary Sales report
+// @Description Admin only. Revenue, tickets sold and discounts of paid transactions booked in a date range, grouped by movie, cinema, day, payment method or hourly time slot
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param groupBy query string false "movie, cinema, day, payment-method or time-slot" default(movie)
+// @Param from query string false "First booking date (YYYY-MM-DD), defaults to 29 days ago"
+// @Param to query string false "Last booking date (YYYY-MM-DD), defaults to today"
+// @Param format query string false "Download as csv or xlsx instead of JSON"
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {object} utils.Response{results=dto.SalesReport}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/reports/sales [get]
+func GetSalesReport(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view reports"})
+		return
+	}
+
+	groupBy := c.DefaultQuery("groupBy", "movie")
+	if !slices.Contains(models.SalesGroupings(), groupBy) {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Invalid groupBy",
+			Errors:  "groupBy must be one of: " + strings.Join(models.SalesGroupings(), ", "),
+		})
+		return
+	}
+
+	format, ok := exportFormat(c, "")
+	if !ok {
+		return
+	}
+
+	from, to, err := reportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid date range", Errors: err.Error()})
+		return
+	}
+
+	report, err := models.GetSalesReport(groupBy, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to build sales report", Errors: err.Error()})
+		return
+	}
+
+	if format != "" {
+		header := []any{"Key", "Label", "Transactions", "Tickets", "Revenue", "Discounts"}
+		writeExport(c, format, "sales-by-"+groupBy, header, func(write func(values ...any) error) error {
+			for _, r := range append(report.Rows, report.Totals) {
+				if err := write(r.Key, r.Label, r.Transactions, r.Tickets, r.Revenue, r.Discounts); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Sales report", Results: report})
+}
+
+// GetOccupancyReport godoc
+// @Summary Occupancy report
+// @Description Admin only. Seats sold against capacity for each showtime in a date range. Cancelled and refunded bookings do not count
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "First show date (YYYY-MM-DD), defaults to 29 days ago"
+// @Param to query string false "Last show date (YYYY-MM-DD), defaults to today"
+// @Param search query string false "Search movie title"
+// @Param movieId query int false "Filter by movie"
+// @Param location query string false "Filter by location"
+// @Param cinema query string false "Filter by cinema"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: showDate, rate, id (prefix - for descending)"
+// @Param format query string false "Download every matching showtime as csv or xlsx instead of JSON"
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {object} utils.Response{results=[]dto.OccupancyRow}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/reports/occupancy [get]
+func GetOccupancyReport(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view reports"})
+		return
+	}
+
+	from, to, err := reportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid date range", Errors: err.Error()})
+		return
+	}
+
+	format, ok := exportFormat(c, "")
+	if !ok {
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 20)
+	if format != "" {
+		writeExport(c, format, "occupancy", models.OccupancyExportColumns, func(write func(values ...any) error) error {
+			return models.ExportOccupancyReport(from, to, q, write)
+		})
+		return
+	}
+
+	rows, page, err := models.GetOccupancyReport(from, to, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to build occupancy report", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "Occupancy report",
+		Results:  rows,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// GetTopCustomers godoc
+// @Summary Top customers report
+// @Description Admin only. Users ranked by what they paid for bookings made in a date range
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "First booking date (YYYY-MM-DD), defaults to 29 days ago"
+// @Param to query string false "Last booking date (YYYY-MM-DD), defaults to today"
+// @Param limit query int false "Number of customers, at most 100" default(10)
+// @Param format query string false "Download as csv or xlsx instead of JSON"
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {object} utils.Response{results=[]dto.TopCustomer}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/reports/top-customers [get]
+func GetTopCustomers(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view reports"})
+		return
+	}
+
+	from, to, err := reportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid date range", Errors: err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid limit", Errors: "limit must be between 1 and 100"})
+		return
+	}
+
+	format, ok := exportFormat(c, "")
+	if !ok {
+		return
+	}
+
+	customers, err := models.GetTopCustomers(from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to build top customers report", Errors: err.Error()})
+		return
+	}
+
+	if format != "" {
+		header := []any{"User ID", "Email", "Name", "Transactions", "Tickets", "Spend"}
+		writeExport(c, format, "top-customers", header, func(write func(values ...any) error) error {
+			for _, cu := range customers {
+				if err := write(cu.UserID, cu.Email, cu.FullName, cu.Transactions, cu.Tickets, cu.Spend); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Top customers", Results: customers})
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// CreateReview godoc
+// @Summary Review a movie
+// @Description Rate (1-5) and review a movie. Only users with a completed booking for the movie can review it, once.
+// @Tags Reviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Movie ID"
+// @Param request body dto.CreateReviewRequest true "Review"
+// @Success 200 {object} utils.Response{results=dto.Review}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /movies/{id}/reviews [post]
+func CreateReview(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	movieID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+		return
+	}
+
+	var input dto.CreateReviewRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	review, err := models.CreateReview(userID, movieID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrMovieNotFound):
+			c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Movie not found"})
+		case errors.Is(err, models.ErrNotVerifiedViewer):
+			c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: err.Error()})
+		case errors.Is(err, models.ErrReviewExists):
+			c.JSON(http.StatusConflict, utils.Response{Success: false, Message: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to create review", Errors: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Review created", Results: review})
+}
+
+// GetMovieReviews godoc
+// @Summary Get movie reviews
+// @Description Retrieve the public reviews of a movie
+// @Tags Reviews
+// @Produce json
+// @Param id path int true "Movie ID"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page" default(10)
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, rating, id (prefix - for descending)"
+// @Param rating query int false "Filter by rating"
+// @Success 200 {object} utils.Response{results=[]dto.Review}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /movies/{id}/reviews [get]
+func GetMovieReviews(c *gin.Context) {
+	movieID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 10)
+	q.Filters.Del("status")
+	reviews, page, err := models.GetMovieReviews(movieID, q)
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Movie not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch reviews", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "Movie reviews",
+		Results:  reviews,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// UpdateReview godoc
+// @Summary Edit my review
+// @Description Change the rating or text of your own review
+// @Tags Reviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body dto.UpdateReviewRequest true "Fields to change"
+// @Success 200 {object} utils.Response{results=dto.Review}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /reviews/{id} [patch]
+func UpdateReview(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid review ID"})
+		return
+	}
+
+	var input dto.UpdateReviewRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	review, err := models.UpdateReview(userID, reviewID, input)
+	if errors.Is(err, models.ErrReviewNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to update review", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Review updated", Results: review})
+}
+
+// DeleteReview godoc
+// @Summary Delete my review
+// @Description Delete your own review
+// @Tags Reviews
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /reviews/{id} [delete]
+func DeleteReview(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid review ID"})
+		return
+	}
+
+	err = models.DeleteReview(userID, reviewID)
+	if errors.Is(err, models.ErrReviewNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to delete review", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Review deleted"})
+}
+
+// GetAllReviews godoc
+// @Summary Get all reviews
+// @Description Admin only. List reviews for moderation, including hidden ones
+// @Tags Reviews
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status: visible, flagged, hidden"
+// @Param movieId query int false "Filter by movie"
+// @Param search query string false "Search review text"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, rating, id (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.Review}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/reviews [get]
+func GetAllReviews(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view all reviews"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	reviews, page, err := models.GetAllReviews(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch reviews", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "All reviews",
+		Results:  reviews,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// ModerateReview godoc
+// @Summary Moderate a review
+// @Description Admin only. Flag, hide or restore a review
+// @Tags Reviews
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param request body dto.ModerateReviewRequest true "New status"
+// @Success 200 {object} utils.Response{results=dto.Review}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/reviews/{id} [patch]
+func ModerateReview(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can moderate reviews"})
+		return
+	}
+
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid review ID"})
+		return
+	}
+
+	var input dto.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	review, err := models.ModerateReview(auditActor(c), reviewID, input)
+	if errors.Is(err, models.ErrReviewNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to moderate review", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Review moderated", Results: review})
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search godoc
+// @Summary Search movies, actors and directors
+// @Description Full-text search over titles, descriptions, genres and credits, tolerant to small typos. Results are ranked and movie matches include highlighted snippets.
+// @Tags Search
+// @Produce json
+// @Param q query string true "Search keyword"
+// @Param limit query int false "Max results per type" default(10)
+// @Success 200 {object} utils.Response{results=dto.SearchResults}
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /search [get]
+func Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Query parameter q is required",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	results, err := models.SearchCatalog(q, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to search",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success: true,
+		Message: "Search results",
+		Results: results,
+	})
+}
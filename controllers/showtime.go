@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// CreateShowtime godoc
+// @Summary Create showtime
+// @Description Admin only. Schedule a movie in a cinema at a date and time
+// @Tags Showtimes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateShowtimeRequest true "Showtime data"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/showtimes [post]
+func CreateShowtime(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can add showtimes"})
+		return
+	}
+
+	var input dto.CreateShowtimeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	showtime, err := models.CreateShowtime(auditActor(c), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to create showtime", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Showtime created", Results: showtime})
+}
+
+// GetShowtimes godoc
+// @Summary Get upcoming showtimes
+// @Description Retrieve bookable showtimes, optionally filtered by movie, date, city and cinema
+// @Tags Showtimes
+// @Produce json
+// @Param movie_id query int false "Movie ID"
+// @Param date query string false "Show date (YYYY-MM-DD)"
+// @Param location query string false "City"
+// @Param cinema query string false "Cinema"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /showtimes [get]
+func GetShowtimes(c *gin.Context) {
+	var movieID int
+	if raw := c.Query("movie_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+			return
+		}
+		movieID = id
+	}
+	filter := dto.ShowtimeFilter{
+		Location: c.Query("location"),
+		Cinema:   c.Query("cinema"),
+	}
+
+	showtimes, err := models.GetShowtimes(movieID, c.Query("date"), filter)
+	if errors.Is(err, models.ErrInvalidShowDate) {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid date, use YYYY-MM-DD", Errors: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch showtimes", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Showtimes", Results: showtimes})
+}
+
+// DeleteShowtime godoc
+// @Summary Delete a showtime
+// @Description Admin only. Delete a showtime by ID
+// @Tags Showtimes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Showtime ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/showtimes/{id} [delete]
+func DeleteShowtime(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can delete showtimes"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid showtime ID"})
+		return
+	}
+	err = models.DeleteShowtime(auditActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to delete showtime", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Showtime deleted"})
+}
+
+// seatStreamHeartbeat keeps idle seat streams from being closed by proxies.
+const seatStreamHeartbeat = 25 * time.Second
+
+// GetSeatStream godoc
+// @Summary Stream seat changes
+// @Description Server-sent events for a showtime's seat map. A "snapshot" event with the taken and held seats comes first, then "seat-taken", "seat-held" and "seat-released" events as seats change on any app instance. The stream ends when the client falls behind; reconnecting delivers a fresh snapshot
+// @Tags Showtimes
+// @Produce text/event-stream
+// @Param id path int true "Showtime ID"
+// @Success 200 {object} dto.SeatSnapshot
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /showtimes/{id}/seats/stream [get]
+func GetSeatStream(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid showtime ID"})
+		return
+	}
+
+	// Subscribe before reading the snapshot, so no change falls in between.
+	events, stop := models.SubscribeSeats(id)
+	defer stop()
+
+	snapshot, err := models.GetSeatSnapshot(id)
+	if errors.Is(err, models.ErrShowtimeNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Showtime not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to load seats", Errors: err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("snapshot", snapshot)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(seatStreamHeartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case payload, ok := <-events:
+			if !ok {
+				return false
+			}
+			var event dto.SeatEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return true
+			}
+			c.SSEvent(event.Type, event)
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+		}
+		return true
+	})
+}
+
+// HoldSeats godoc
+// @Summary Hold seats
+// @Description Hold seats while checking out, so other customers see them as taken. Holds last SEAT_HOLD_MINUTES and holding again extends them. When a seat is booked or held by someone else nothing is held and the conflicting seats are returned
+// @Tags Showtimes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Showtime ID"
+// @Param request body dto.SeatHoldRequest true "Seats to hold"
+// @Success 200 {object} utils.Response{results=dto.SeatHold}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response{results=[]string}
+// @Failure 500 {object} utils.Response
+// @Router /showtimes/{id}/seats/hold [post]
+func HoldSeats(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid showtime ID"})
+		return
+	}
+
+	var input dto.SeatHoldRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	hold, conflicts, err := models.HoldSeats(id, userID, input.Seats)
+	if errors.Is(err, models.ErrShowtimeNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Showtime not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to hold seats", Errors: err.Error()})
+		return
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusConflict, utils.Response{Success: false, Message: "Seats are not available", Results: conflicts})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Seats held", Results: hold})
+}
+
+// ReleaseSeats godoc
+// @Summary Release held seats
+// @Description Release the seats you hold on a showtime, all of them unless seats is given
+// @Tags Showtimes
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Showtime ID"
+// @Param seats query string false "Seats (comma-separated)"
+// @Success 200 {object} utils.Response{results=[]string}
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /showtimes/{id}/seats/hold [delete]
+func ReleaseSeats(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid showtime ID"})
+		return
+	}
+
+	seats := []string{}
+	if s := c.Query("seats"); s != "" {
+		seats = strings.Split(s, ",")
+	}
+
+	released, err := models.ReleaseSeats(id, userID, seats)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to release seats", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Seats released", Results: released})
+}
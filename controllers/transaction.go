@@ -4,10 +4,15 @@ import (
 	"be-tickitz/dto"
 	"be-tickitz/models"
 	"be-tickitz/utils"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -69,6 +74,14 @@ func CreateTransaction(c *gin.Context) {
 	}
 
 	transactionID, err := models.CreateTransaction(userID, input)
+	if errors.Is(err, models.ErrPromoInvalid) || errors.Is(err, models.ErrPointsInvalid) || errors.Is(err, models.ErrSeatsHeld) ||
+		errors.Is(err, models.ErrMovieNotFound) || errors.Is(err, models.ErrPaymentMethodNotFound) {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -152,12 +165,48 @@ func CheckSeatAvailability(c *gin.Context) {
 	})
 }
 
+// checkTransactionFilters validates the typed admin transaction filters, so a
+// malformed value is reported instead of failing in the database.
+func checkTransactionFilters(c *gin.Context) error {
+	for _, key := range []string{"movieId", "paymentMethod", "minPrice", "maxPrice"} {
+		if v := c.Query(key); v != "" {
+			if _, err := strconv.Atoi(v); err != nil {
+				return fmt.Errorf("%s must be a number", key)
+			}
+		}
+	}
+	for _, key := range []string{"showDateFrom", "showDateTo"} {
+		if v := c.Query(key); v != "" {
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				return fmt.Errorf("%s must be a date in YYYY-MM-DD format", key)
+			}
+		}
+	}
+	return nil
+}
+
 // GetAllTransactions godoc
 // @Summary Get all transactions (admin only)
 // @Tags Transactions
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {object} utils.Response
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, showDate, totalPrice, id (prefix - for descending)"
+// @Param search query string false "Search movie title or user email"
+// @Param email query string false "Filter by user email"
+// @Param movieId query int false "Filter by movie"
+// @Param location query string false "Filter by location"
+// @Param cinema query string false "Filter by cinema"
+// @Param paymentMethod query int false "Filter by payment method ID"
+// @Param status query string false "Filter by status: pending, paid, cancelled, refunded"
+// @Param showDateFrom query string false "Earliest show date (YYYY-MM-DD)"
+// @Param showDateTo query string false "Latest show date (YYYY-MM-DD)"
+// @Param minPrice query int false "Lowest total price"
+// @Param maxPrice query int false "Highest total price"
+// @Success 200 {object} utils.Response{results=[]dto.TransactionSummary}
+// @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /admin/transactions [get]
@@ -173,7 +222,13 @@ func GetAllTransactions(c *gin.Context) {
 		return
 	}
 
-	results, err := models.GetAllTransactions()
+	if err := checkTransactionFilters(c); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid filter", Errors: err.Error()})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	results, page, err := models.GetAllTransactions(q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -187,6 +242,61 @@ func GetAllTransactions(c *gin.Context) {
 		Success: true,
 		Message: "All transactions",
 		Results: results,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// ExportTransactions godoc
+// @Summary Export transactions (admin only)
+// @Description Download transactions booked in a date range as CSV or XLSX. Rows are streamed from the database, so the range can be as large as needed
+// @Tags Transactions
+// @Security BearerAuth
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv or xlsx" default(csv)
+// @Param from query string false "First booking date (YYYY-MM-DD), defaults to 29 days ago"
+// @Param to query string false "Last booking date (YYYY-MM-DD), defaults to today"
+// @Param search query string false "Search movie title or user email"
+// @Param email query string false "Filter by user email"
+// @Param movieId query int false "Filter by movie"
+// @Param location query string false "Filter by location"
+// @Param cinema query string false "Filter by cinema"
+// @Param paymentMethod query int false "Filter by payment method ID"
+// @Param status query string false "Filter by status: pending, paid, cancelled, refunded"
+// @Param showDateFrom query string false "Earliest show date (YYYY-MM-DD)"
+// @Param showDateTo query string false "Latest show date (YYYY-MM-DD)"
+// @Param minPrice query int false "Lowest total price"
+// @Param maxPrice query int false "Highest total price"
+// @Param sort query string false "Sort by: createdAt, showDate, totalPrice, id (prefix - for descending)"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/transactions/export [get]
+func ExportTransactions(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can access"})
+		return
+	}
+
+	format, ok := exportFormat(c, "csv")
+	if !ok {
+		return
+	}
+
+	from, to, err := reportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid date range", Errors: err.Error()})
+		return
+	}
+	if err := checkTransactionFilters(c); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid filter", Errors: err.Error()})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	writeExport(c, format, "transactions", models.TransactionExportColumns, func(write func(values ...any) error) error {
+		return models.ExportTransactions(from, to, q, write)
 	})
 }
 
@@ -195,6 +305,13 @@ func GetAllTransactions(c *gin.Context) {
 // @Tags Transactions
 // @Security BearerAuth
 // @Produce json
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, showDate, totalPrice, id (prefix - for descending)"
+// @Param search query string false "Filter by movie title"
+// @Param location query string false "Filter by location"
+// @Param cinema query string false "Filter by cinema"
 // @Success 200 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
@@ -203,7 +320,8 @@ func GetMyTransactions(c *gin.Context) {
 	claims := c.MustGet("user").(jwt.MapClaims)
 	userID := int(claims["userId"].(float64))
 
-	transactions, err := models.GetUserTransactions(userID)
+	q := utils.ParsePageQuery(c, 0)
+	transactions, page, err := models.GetUserTransactions(userID, q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -217,5 +335,233 @@ func GetMyTransactions(c *gin.Context) {
 		Success: true,
 		Message: "Your transactions",
 		Results: transactions,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// GetBookingCalendar godoc
+// @Summary Download a booking as a calendar event
+// @Description iCalendar (RFC 5545) event for one of your bookings, from the show time to the end of the movie. Import it into any calendar app
+// @Tags Transactions
+// @Security BearerAuth
+// @Produce text/calendar
+// @Param id path int true "Transaction ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /transactions/{id}/calendar.ics [get]
+func GetBookingCalendar(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Invalid transaction ID",
+		})
+		return
+	}
+
+	ics, err := models.GetBookingCalendar(id, userID)
+	if errors.Is(err, models.ErrTransactionNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{
+			Success: false,
+			Message: "Transaction not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to create calendar event",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, models.BookingCalendarFilename(id)))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+// transactionDocument loads the transaction in the path for one of its PDFs,
+// answering the request itself when it cannot.
+func transactionDocument(c *gin.Context) (dto.TransactionDocument, bool) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Invalid transaction ID",
+		})
+		return dto.TransactionDocument{}, false
+	}
+
+	doc, err := models.GetTransactionDocument(id, userID)
+	if errors.Is(err, models.ErrTransactionNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{
+			Success: false,
+			Message: "Transaction not found",
+		})
+		return doc, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to load transaction",
+			Errors:  err.Error(),
+		})
+		return doc, false
+	}
+	return doc, true
+}
+
+// sendPDF renders a document and sends it as a download. Documents that do
+// not exist for the transaction yet are answered with 409.
+func sendPDF(c *gin.Context, filename string, render func(w io.Writer) error) {
+	var buf bytes.Buffer
+	err := render(&buf)
+	if errors.Is(err, models.ErrTicketUnavailable) || errors.Is(err, models.ErrInvoiceUnavailable) {
+		c.JSON(http.StatusConflict, utils.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to create PDF",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// GetTicketPDF godoc
+// @Summary Download an e-ticket
+// @Description PDF e-ticket for one of your paid bookings, with the poster, show, seats and a QR code to scan at the cinema
+// @Tags Transactions
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path int true "Transaction ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /transactions/{id}/ticket.pdf [get]
+func GetTicketPDF(c *gin.Context) {
+	doc, ok := transactionDocument(c)
+	if !ok {
+		return
+	}
+	sendPDF(c, models.TicketFilename(doc), func(w io.Writer) error {
+		return models.WriteTicketPDF(w, doc)
+	})
+}
+
+// GetInvoicePDF godoc
+// @Summary Download an invoice
+// @Description PDF invoice for one of your transactions once it was paid, with the price of every seat, discounts, the included tax and the payment method. Invoice numbers are issued on payment and never change
+// @Tags Transactions
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path int true "Transaction ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /transactions/{id}/invoice.pdf [get]
+func GetInvoicePDF(c *gin.Context) {
+	doc, ok := transactionDocument(c)
+	if !ok {
+		return
+	}
+	sendPDF(c, models.InvoiceFilename(doc), func(w io.Writer) error {
+		return models.WriteInvoicePDF(w, doc)
+	})
+}
+
+// UpdateTransactionStatus godoc
+// @Summary Update transaction status
+// @Description Admin only. Mark a pending transaction paid, or cancel or refund it. Loyalty points are credited on paid and reversed on cancel or refund.
+// @Tags Transactions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body dto.UpdateTransactionStatusRequest true "New status"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/transactions/{id}/status [patch]
+func UpdateTransactionStatus(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{
+			Success: false,
+			Message: "Only admin can update transactions",
+		})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Invalid transaction ID",
+		})
+		return
+	}
+
+	var input dto.UpdateTransactionStatusRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Success: false,
+			Message: "Invalid input",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	_, err = models.UpdateTransactionStatus(auditActor(c), id, input.Status)
+	switch {
+	case errors.Is(err, models.ErrTransactionNotFound):
+		c.JSON(http.StatusNotFound, utils.Response{
+			Success: false,
+			Message: "Transaction not found",
+		})
+		return
+	case errors.Is(err, models.ErrInvalidStatusChange):
+		c.JSON(http.StatusConflict, utils.Response{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to update transaction",
+			Errors:  err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success: true,
+		Message: "Transaction " + input.Status,
 	})
 }
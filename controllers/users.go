@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -143,7 +144,7 @@ func Login(ctx *gin.Context) {
 
 // ForgotPassword godoc
 // @Summary Send password reset token
-// @Description Send reset token to user's email if email is valid
+// @Description Queue an email with a reset token if the email belongs to a user. The email is delivered in the background and retried if the mail server is down
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -175,23 +176,36 @@ func ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	token, err := utils.GenerateJWT("reset_password", user.ID, 10*time.Minute, nil)
+	profile, err := models.GetUserByID(user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
-			Message: "Failed to generate token",
+			Message: "Failed to fetch user",
 			Errors:  err.Error(),
 		})
 		return
 	}
 
-	body := fmt.Sprintf("<p>Copy to this token to reset your password:</p><code>%s</code>", token)
+	const resetTokenTTL = 10 * time.Minute
+	token, err := utils.GenerateJWT("reset_password", user.ID, resetTokenTTL, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{
+			Success: false,
+			Message: "Failed to generate token",
+			Errors:  err.Error(),
+		})
+		return
+	}
 
-	err = utils.SendEmail(req.Email, "Reset Your Password", body)
+	err = models.QueuePasswordResetEmail(profile.Email, profile.Language, dto.PasswordResetEmail{
+		Name:             profile.FullName,
+		Token:            token,
+		ExpiresInMinutes: int(resetTokenTTL.Minutes()),
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
-			Message: "Failed to send email",
+			Message: "Failed to queue email",
 			Errors:  err.Error(),
 		})
 		return
@@ -278,6 +292,12 @@ func ResetPassword(c *gin.Context) {
 // @Tags Users
 // @Security BearerAuth
 // @Produce json
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: id, email, fullName (prefix - for descending)"
+// @Param search query string false "Filter by email or name"
+// @Param role query string false "Filter by role"
 // @Success 200 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
@@ -294,7 +314,8 @@ func GetAllUsers(c *gin.Context) {
 		return
 	}
 
-	users, err := models.GetAllUsers()
+	q := utils.ParsePageQuery(c, 0)
+	users, page, err := models.GetAllUsers(q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.Response{
 			Success: false,
@@ -320,6 +341,7 @@ func GetAllUsers(c *gin.Context) {
 		Success: true,
 		Message: "All users",
 		Results: userList,
+		PageInfo: utils.NewPageInfo(c, q, page),
 	})
 }
 
@@ -352,6 +374,7 @@ func GetProfile(c *gin.Context) {
 		Role:     user.Role,
 		Phone:    user.PhoneNumber,
 		Picture:  user.ProfilePicture,
+		Language: user.Language,
 	}
 
 	c.JSON(http.StatusOK, utils.Response{
@@ -394,7 +417,7 @@ func DeleteUserByID(c *gin.Context) {
 		return
 	}
 
-	err = models.DeleteUserByID(userID)
+	err = models.DeleteUserByID(auditActor(c), userID)
 	if err != nil {
 		if err.Error() == "user not found" {
 			c.JSON(http.StatusNotFound, utils.Response{
@@ -442,6 +465,15 @@ func UpdateProfile(c *gin.Context) {
     return
   }
 
+  if req.Language != nil && !utils.EmailLanguageSupported(*req.Language) {
+    c.JSON(http.StatusBadRequest, utils.Response{
+      Success: false,
+      Message: "Unsupported language",
+      Errors:  "language must be one of: " + strings.Join(utils.EmailLanguages(), ", "),
+    })
+    return
+  }
+
   if req.OldPassword != nil && req.NewPassword == nil {
     c.JSON(http.StatusBadRequest, utils.Response{
       Success: false,
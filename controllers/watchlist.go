@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AddToWatchlist godoc
+// @Summary Add movie to watchlist
+// @Description Save a movie to your watchlist. You get an email when it becomes bookable or is released.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param movieId path int true "Movie ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /profile/watchlist/{movieId} [post]
+func AddToWatchlist(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	movieID, err := strconv.Atoi(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+		return
+	}
+
+	err = models.AddToWatchlist(userID, movieID)
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Movie not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to add to watchlist", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Added to watchlist"})
+}
+
+// RemoveFromWatchlist godoc
+// @Summary Remove movie from watchlist
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param movieId path int true "Movie ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /profile/watchlist/{movieId} [delete]
+func RemoveFromWatchlist(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	movieID, err := strconv.Atoi(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid movie ID"})
+		return
+	}
+
+	err = models.RemoveFromWatchlist(userID, movieID)
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Movie is not in your watchlist"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to remove from watchlist", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Removed from watchlist"})
+}
+
+// GetWatchlist godoc
+// @Summary Get my watchlist
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: addedAt, releaseDate, title (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.WatchlistItem}
+// @Failure 500 {object} utils.Response
+// @Router /profile/watchlist [get]
+func GetWatchlist(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	userID := int(claims["userId"].(float64))
+
+	q := utils.ParsePageQuery(c, 0)
+	items, page, err := models.GetWatchlist(userID, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch watchlist", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "Your watchlist",
+		Results:  items,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
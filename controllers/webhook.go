@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/models"
+	"be-tickitz/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// CreateWebhook godoc
+// @Summary Register a webhook
+// @Description Admin only. Subscribe a partner URL to events: transaction.created, transaction.paid, transaction.cancelled, transaction.refunded, movie.created, movie.updated. Deliveries are POSTed as JSON and signed in the X-Tickitz-Signature header as t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">. The signing secret is only returned here and when rotated
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWebhookRequest true "Webhook data"
+// @Success 200 {object} utils.Response{results=dto.Webhook}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can add webhooks"})
+		return
+	}
+
+	var input dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	webhook, err := models.CreateWebhook(auditActor(c), input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Failed to create webhook", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Webhook created", Results: webhook})
+}
+
+// GetAllWebhooks godoc
+// @Summary Get all webhooks
+// @Description Admin only. List webhooks with the status of their latest delivery
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param search query string false "Search URL or description"
+// @Param isActive query bool false "Filter by active flag"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page"
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, id (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.Webhook}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/webhooks [get]
+func GetAllWebhooks(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view webhooks"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 0)
+	webhooks, page, err := models.GetAllWebhooks(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch webhooks", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "All webhooks",
+		Results:  webhooks,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// GetWebhookByID godoc
+// @Summary Get webhook
+// @Description Admin only. Retrieve a webhook by ID
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} utils.Response{results=dto.Webhook}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id} [get]
+func GetWebhookByID(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view webhooks"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid webhook ID"})
+		return
+	}
+
+	webhook, err := models.GetWebhookByID(id)
+	if errors.Is(err, models.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch webhook", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Webhook details", Results: webhook})
+}
+
+// UpdateWebhook godoc
+// @Summary Update webhook
+// @Description Admin only. Change the fields that are sent. With rotateSecret a new signing secret is created and returned
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param request body dto.UpdateWebhookRequest true "Fields to change"
+// @Success 200 {object} utils.Response{results=dto.Webhook}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id} [patch]
+func UpdateWebhook(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can update webhooks"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid webhook ID"})
+		return
+	}
+
+	var input dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid input", Errors: err.Error()})
+		return
+	}
+
+	webhook, err := models.UpdateWebhook(auditActor(c), id, input)
+	if errors.Is(err, models.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Failed to update webhook", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Webhook updated", Results: webhook})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook
+// @Description Admin only. Delete a webhook together with its delivery log
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can delete webhooks"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid webhook ID"})
+		return
+	}
+
+	err = models.DeleteWebhook(auditActor(c), id)
+	if errors.Is(err, models.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to delete webhook", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Webhook deleted"})
+}
+
+// GetWebhookDeliveries godoc
+// @Summary Get webhook delivery log
+// @Description Admin only. Deliveries of a webhook, newest first, with the receiver's answer and the last error
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "queued, sending, delivered or failed"
+// @Param event query string false "Filter by event"
+// @Param page query int false "Page number (offset mode)"
+// @Param limit query int false "Items per page" default(20)
+// @Param cursor query string false "Cursor from pageInfo.nextCursor (cursor mode)"
+// @Param sort query string false "Sort by: createdAt, id (prefix - for descending)"
+// @Success 200 {object} utils.Response{results=[]dto.WebhookDelivery}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/webhooks/{id}/deliveries [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can view webhooks"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid webhook ID"})
+		return
+	}
+
+	q := utils.ParsePageQuery(c, 20)
+	deliveries, page, err := models.GetWebhookDeliveries(id, q)
+	if errors.Is(err, models.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to fetch deliveries", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{
+		Success:  true,
+		Message:  "Webhook deliveries",
+		Results:  deliveries,
+		PageInfo: utils.NewPageInfo(c, q, page),
+	})
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook event
+// @Description Admin only. Send a delivery again, as a new entry in the log with the same payload and event ID
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} utils.Response{results=dto.WebhookDelivery}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func RedeliverWebhook(c *gin.Context) {
+	claims := c.MustGet("user").(jwt.MapClaims)
+	if role, ok := claims["role"].(string); !ok || role != "admin" {
+		c.JSON(http.StatusForbidden, utils.Response{Success: false, Message: "Only admin can redeliver webhooks"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid webhook ID"})
+		return
+	}
+	deliveryID, err := strconv.Atoi(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Success: false, Message: "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := models.RedeliverWebhook(auditActor(c), id, deliveryID)
+	if errors.Is(err, models.ErrDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, utils.Response{Success: false, Message: "Delivery not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.Response{Success: false, Message: "Failed to redeliver", Errors: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.Response{Success: true, Message: "Delivery queued", Results: delivery})
+}
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditActor is the admin behind a change, as recorded in the audit log.
+type AuditActor struct {
+	UserID    int
+	IP        string
+	UserAgent string
+}
+
+// AuditEntry is a change recorded in the audit log. Before and After only
+// hold the fields that changed; a create has no Before and a delete no After.
+type AuditEntry struct {
+	ID         int             `json:"id"`
+	ActorID    *int            `json:"actorId"`
+	ActorEmail string          `json:"actorEmail,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityID   *int            `json:"entityId"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	IPAddress  string          `json:"ipAddress,omitempty"`
+	UserAgent  string          `json:"userAgent,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
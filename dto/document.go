@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// TransactionDocument is the data of a transaction's e-ticket and invoice.
+type TransactionDocument struct {
+	BookingEmail
+	Poster        string
+	SeatPrices    []SeatPrice
+	PaidAt        *time.Time
+	InvoiceNumber string
+	TaxRate       int
+	TaxBase       int
+	Tax           int
+}
+
+type SeatPrice struct {
+	Seat  string
+	Price int
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// OutboxEmail is a queued, sent or dead email as seen by admins.
+type OutboxEmail struct {
+	ID            int        `json:"id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"maxAttempts"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	LastError     *string    `json:"lastError,omitempty"`
+	SentAt        *time.Time `json:"sentAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
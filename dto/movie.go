@@ -12,6 +12,37 @@ type Movie struct {
 	GenreIDs        []int  `json:"genreIDs"`
 	DirectorIDs     []int  `json:"directorIDs"`
 	CastIDs         []int  `json:"castIDs"`
+	Crew            []CrewInput `json:"crew"`
+	Casts           []CastInput `json:"casts"`
+}
+
+// CastInput links an actor to a movie with the character played. When Order
+// is omitted the position in the request is used as billing order.
+type CastInput struct {
+	ActorID  int    `json:"actorId" binding:"required"`
+	RoleName string `json:"roleName"`
+	Order    *int   `json:"order"`
+}
+
+// CrewInput links a director (or other crew member) to a movie with a job title.
+type CrewInput struct {
+	DirectorID int    `json:"directorId" binding:"required"`
+	Job        string `json:"job"`
+	Order      *int   `json:"order"`
+}
+
+type CastCredit struct {
+	ActorID  int    `json:"actorId"`
+	Name     string `json:"name"`
+	RoleName string `json:"roleName"`
+	Order    int    `json:"order"`
+}
+
+type CrewCredit struct {
+	DirectorID int    `json:"directorId"`
+	Name       string `json:"name"`
+	Job        string `json:"job"`
+	Order      int    `json:"order"`
 }
 
 type MovieDetail struct {
@@ -23,8 +54,10 @@ type MovieDetail struct {
   Image           string    `json:"image"`
   HorizontalImage string    `json:"horizontalImage"`
   Genres          []string  `json:"genres"`
-  Directors       []string  `json:"directors"`
-  Casts           []string  `json:"casts"`
+  Directors       []CrewCredit `json:"directors"`
+  Casts           []CastCredit `json:"casts"`
+  Rating          RatingSummary `json:"rating"`
+  UpdatedAt       time.Time `json:"updatedAt"`
 }
 
 
@@ -39,16 +72,19 @@ type MovieResponse struct {
 	GenreIDs        []int     `json:"genreIDs"`
 	DirectorIDs     []int     `json:"directorIDs"`
 	CastIDs         []int     `json:"castIDs"`
+	Crew            []CrewInput `json:"crew,omitempty"`
+	Casts           []CastInput `json:"casts,omitempty"`
 }
 
 type MovieList struct {
-	ID              int       `json:"id"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	ReleaseDate     time.Time `json:"releaseDate"`
-	Duration        int       `json:"durationMinutes"`
-	Image           string    `json:"image"`
-	HorizontalImage string    `json:"horizontalImage"`
+	ID              int        `json:"id"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	ReleaseDate     time.Time  `json:"releaseDate"`
+	Duration        int        `json:"durationMinutes"`
+	Image           string     `json:"image"`
+	HorizontalImage string     `json:"horizontalImage"`
+	DeletedAt       *time.Time `json:"deletedAt,omitempty"`
 	// GenreIDs        []int     `json:"genreIDs"`
 	// DirectorIDs     []int     `json:"directorIDs"`
 	// CastIDs         []int     `json:"castIDs"`
@@ -70,5 +106,105 @@ type UpdateMovieInput struct {
   GenreIDs        *[]int    `json:"genreIDs"`
   DirectorIDs     *[]int    `json:"directorIDs"`
   CastIDs         *[]int    `json:"castIDs"`
+  Crew            *[]CrewInput `json:"crew"`
+  Casts           *[]CastInput `json:"casts"`
 }
 
+
+// RecommendedMovie is a now-showing movie scored against a user's booking
+// history. Reason is "history" when the score comes from the user's taste
+// and "popular" for the popularity fallback.
+type RecommendedMovie struct {
+  ID              int       `json:"id"`
+  Title           string    `json:"title"`
+  ReleaseDate     time.Time `json:"releaseDate"`
+  Duration        int       `json:"durationMinutes"`
+  Image           string    `json:"image"`
+  HorizontalImage string    `json:"horizontalImage"`
+  GenreIDs        []int     `json:"genreIds"`
+  Score           float64   `json:"score"`
+  Reason          string    `json:"reason"`
+}
+
+// MovieRecord is a movie as it is imported and exported. Genres, directors
+// and cast refer to existing entries by name and missing ones are created.
+// ExternalID identifies the movie across imports.
+type MovieRecord struct {
+	ExternalID      string         `json:"externalId"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	ReleaseDate     string         `json:"releaseDate" example:"2025-07-30"`
+	Duration        int            `json:"durationMinutes"`
+	Image           string         `json:"image"`
+	HorizontalImage string         `json:"horizontalImage"`
+	Genres          []string       `json:"genres"`
+	Directors       []CreditRecord `json:"directors"`
+	Cast            []CreditRecord `json:"cast"`
+}
+
+// CreditRecord names a director with their job, or an actor with the role
+// played.
+type CreditRecord struct {
+	Name string `json:"name"`
+	Job  string `json:"job,omitempty"`
+	Role string `json:"role,omitempty"`
+}
+
+// MovieImportRow is the outcome for one movie of an import file. Row counts
+// from 1 for the first movie. Action is create, update or unchanged, and is
+// left out when the row has errors.
+type MovieImportRow struct {
+	Row        int      `json:"row"`
+	ExternalID string   `json:"externalId"`
+	Title      string   `json:"title"`
+	Action     string   `json:"action,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+type MovieImportResult struct {
+	DryRun           bool             `json:"dryRun"`
+	Total            int              `json:"total"`
+	Created          int              `json:"created"`
+	Updated          int              `json:"updated"`
+	Unchanged        int              `json:"unchanged"`
+	Failed           int              `json:"failed"`
+	GenresCreated    int              `json:"genresCreated"`
+	DirectorsCreated int              `json:"directorsCreated"`
+	ActorsCreated    int              `json:"actorsCreated"`
+	Rows             []MovieImportRow `json:"rows"`
+}
+
+// TMDBMovie is a movie fetched from TMDB for cmd/seed_tmdb to sync. Movies
+// and people are matched by their TMDB ID, genres by name.
+type TMDBMovie struct {
+	TMDBID          int
+	Title           string
+	Description     string
+	ReleaseDate     string
+	Duration        int
+	Image           string
+	HorizontalImage string
+	Genres          []string
+	Directors       []TMDBCredit
+	Cast            []TMDBCredit
+}
+
+// TMDBCredit is a director with their job or an actor with the role played,
+// in billing order.
+type TMDBCredit struct {
+	TMDBID int
+	Name   string
+	Job    string
+	Role   string
+	Order  int
+}
+
+// TMDBSyncResult tells what syncing one movie did. Action is create, update
+// or unchanged.
+type TMDBSyncResult struct {
+	MovieID          int
+	Action           string
+	GenresCreated    int
+	DirectorsCreated int
+	ActorsCreated    int
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+// BookingEmail is the data of the booking confirmation, reminder,
+// cancellation and refund emails.
+type BookingEmail struct {
+	TransactionID  int
+	Name           string
+	Email          string
+	Language       string
+	MovieTitle     string
+	ShowDate       time.Time
+	ShowTime       string
+	StartsAt       time.Time
+	EndsAt         time.Time
+	Status         string
+	Location       string
+	Cinema         string
+	Seats          []string
+	PaymentMethod  string
+	Subtotal       int
+	PromoCode      string
+	Discount       int
+	PointsRedeemed int
+	PointsDiscount int
+	Total          int
+}
+
+type PasswordResetEmail struct {
+	Name             string
+	Token            string
+	ExpiresInMinutes int
+}
+
+type WatchlistEmail struct {
+	Name       string
+	MovieTitle string
+	Bookable   bool
+	Released   bool
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// PointsSummary is a user's loyalty standing. Tier comes from spend on paid
+// bookings over the last 12 months and multiplies the points earned.
+type PointsSummary struct {
+	Balance         int     `json:"balance"`
+	Tier            string  `json:"tier"`
+	Multiplier      float64 `json:"multiplier"`
+	YearlySpend     int     `json:"yearlySpend"`
+	NextTier        string  `json:"nextTier,omitempty"`
+	SpendToNextTier int     `json:"spendToNextTier,omitempty"`
+	EarnPerAmount   int     `json:"earnPerAmount"`
+	PointValue      int     `json:"pointValue"`
+}
+
+type PointsEntry struct {
+	ID            int       `json:"id"`
+	TransactionID *int      `json:"transactionId"`
+	Type          string    `json:"type"`
+	Points        int       `json:"points"`
+	Description   string    `json:"description"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type PointsResponse struct {
+	Summary PointsSummary `json:"summary"`
+	History []PointsEntry `json:"history"`
+}
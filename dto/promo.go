@@ -0,0 +1,79 @@
+package dto
+
+import "time"
+
+type CreatePromoRequest struct {
+	Code             string     `json:"code" binding:"required,max=50"`
+	Description      string     `json:"description"`
+	DiscountType     string     `json:"discountType" binding:"required,oneof=percent fixed"`
+	DiscountValue    int        `json:"discountValue" binding:"required,min=1"`
+	MaxDiscount      *int       `json:"maxDiscount" binding:"omitempty,min=1"`
+	MinSpend         int        `json:"minSpend" binding:"min=0"`
+	UsageLimit       *int       `json:"usageLimit" binding:"omitempty,min=1"`
+	PerUserLimit     *int       `json:"perUserLimit" binding:"omitempty,min=1"`
+	StartsAt         *time.Time `json:"startsAt"`
+	EndsAt           *time.Time `json:"endsAt"`
+	IsActive         *bool      `json:"isActive"`
+	MovieIDs         []int      `json:"movieIds"`
+	Cinemas          []string   `json:"cinemas"`
+	PaymentMethodIDs []int      `json:"paymentMethodIds"`
+}
+
+// UpdatePromoRequest changes only the fields that are sent. Sending a
+// restriction list replaces it; an empty list removes the restriction.
+type UpdatePromoRequest struct {
+	Code             *string    `json:"code" binding:"omitempty,max=50"`
+	Description      *string    `json:"description"`
+	DiscountType     *string    `json:"discountType" binding:"omitempty,oneof=percent fixed"`
+	DiscountValue    *int       `json:"discountValue" binding:"omitempty,min=1"`
+	MaxDiscount      *int       `json:"maxDiscount" binding:"omitempty,min=0"`
+	MinSpend         *int       `json:"minSpend" binding:"omitempty,min=0"`
+	UsageLimit       *int       `json:"usageLimit" binding:"omitempty,min=0"`
+	PerUserLimit     *int       `json:"perUserLimit" binding:"omitempty,min=0"`
+	StartsAt         *time.Time `json:"startsAt"`
+	EndsAt           *time.Time `json:"endsAt"`
+	IsActive         *bool      `json:"isActive"`
+	MovieIDs         *[]int     `json:"movieIds"`
+	Cinemas          *[]string  `json:"cinemas"`
+	PaymentMethodIDs *[]int     `json:"paymentMethodIds"`
+}
+
+// Promo is a discount code. MaxDiscount caps percentage discounts; nil limits
+// and empty restriction lists mean unlimited / not restricted.
+type Promo struct {
+	ID               int        `json:"id"`
+	Code             string     `json:"code"`
+	Description      string     `json:"description"`
+	DiscountType     string     `json:"discountType"`
+	DiscountValue    int        `json:"discountValue"`
+	MaxDiscount      *int       `json:"maxDiscount"`
+	MinSpend         int        `json:"minSpend"`
+	UsageLimit       *int       `json:"usageLimit"`
+	PerUserLimit     *int       `json:"perUserLimit"`
+	StartsAt         *time.Time `json:"startsAt"`
+	EndsAt           *time.Time `json:"endsAt"`
+	IsActive         bool       `json:"isActive"`
+	MovieIDs         []int      `json:"movieIds"`
+	Cinemas          []string   `json:"cinemas"`
+	PaymentMethodIDs []int      `json:"paymentMethodIds"`
+	UsedCount        int        `json:"usedCount"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+type ValidatePromoRequest struct {
+	Code          string   `json:"code" binding:"required"`
+	MovieID       int      `json:"movie_id" binding:"required"`
+	ShowDate      string   `json:"show_date" binding:"required"`
+	ShowTime      string   `json:"show_time" binding:"required"`
+	Location      string   `json:"location" binding:"required"`
+	Cinema        string   `json:"cinema" binding:"required"`
+	PaymentMethod int      `json:"payment_method"`
+	Seats         []string `json:"seats" binding:"required,min=1"`
+}
+
+type PromoQuote struct {
+	Code     string `json:"code"`
+	Subtotal int    `json:"subtotal"`
+	Discount int    `json:"discount"`
+	Total    int    `json:"total"`
+}
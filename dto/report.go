@@ -0,0 +1,41 @@
+package dto
+
+// SalesReportRow is one group of paid transactions in a sales report.
+type SalesReportRow struct {
+	Key          string `json:"key"`
+	Label        string `json:"label"`
+	Transactions int    `json:"transactions"`
+	Tickets      int    `json:"tickets"`
+	Revenue      int64  `json:"revenue"`
+	Discounts    int64  `json:"discounts"`
+}
+
+type SalesReport struct {
+	GroupBy string           `json:"groupBy"`
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Rows    []SalesReportRow `json:"rows"`
+	Totals  SalesReportRow   `json:"totals"`
+}
+
+type OccupancyRow struct {
+	ShowtimeID int     `json:"showtimeId"`
+	MovieID    int     `json:"movieId"`
+	MovieTitle string  `json:"movieTitle"`
+	Location   string  `json:"location"`
+	Cinema     string  `json:"cinema"`
+	ShowDate   string  `json:"showDate"`
+	ShowTime   string  `json:"showTime"`
+	Capacity   int     `json:"capacity"`
+	SeatsSold  int     `json:"seatsSold"`
+	Rate       float64 `json:"rate"`
+}
+
+type TopCustomer struct {
+	UserID       int    `json:"userId"`
+	Email        string `json:"email"`
+	FullName     string `json:"fullName"`
+	Transactions int    `json:"transactions"`
+	Tickets      int    `json:"tickets"`
+	Spend        int64  `json:"spend"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Review string `json:"review"`
+}
+
+type UpdateReviewRequest struct {
+	Rating *int    `json:"rating" binding:"omitempty,min=1,max=5"`
+	Review *string `json:"review"`
+}
+
+// ModerateReviewRequest sets a review's status. Flagged reviews stay public
+// but are queued for admins; hidden reviews are removed from public lists and
+// from the movie rating.
+type ModerateReviewRequest struct {
+	Status string `json:"status" binding:"required,oneof=visible flagged hidden"`
+	Note   string `json:"note"`
+}
+
+type Review struct {
+	ID             int       `json:"id"`
+	MovieID        int       `json:"movieId"`
+	UserID         int       `json:"userId"`
+	UserName       string    `json:"userName"`
+	Rating         int       `json:"rating"`
+	Review         string    `json:"review"`
+	Status         string    `json:"status"`
+	ModerationNote string    `json:"moderationNote,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// RatingSummary aggregates the ratings of a movie's reviews that are not hidden.
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
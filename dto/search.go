@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type MovieSearchResult struct {
+	ID             int       `json:"id"`
+	Title          string    `json:"title"`
+	TitleHighlight string    `json:"titleHighlight"`
+	Snippet        string    `json:"snippet"`
+	ReleaseDate    time.Time `json:"releaseDate"`
+	Image          string    `json:"image"`
+	Rank           float64   `json:"rank"`
+}
+
+type PersonSearchResult struct {
+	ID    int     `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+type SearchResults struct {
+	Movies    []MovieSearchResult  `json:"movies"`
+	Actors    []PersonSearchResult `json:"actors"`
+	Directors []PersonSearchResult `json:"directors"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+type CreateShowtimeRequest struct {
+	MovieID  int    `json:"movieId" binding:"required"`
+	Location string `json:"location" binding:"required"`
+	Cinema   string `json:"cinema" binding:"required"`
+	ShowDate string `json:"showDate" binding:"required"`
+	ShowTime string `json:"showTime" binding:"required"`
+	Price    int    `json:"price"`
+	Capacity int    `json:"capacity" binding:"omitempty,min=1"`
+}
+
+type Showtime struct {
+	ID       int    `json:"id"`
+	MovieID  int    `json:"movieId"`
+	Location string `json:"location"`
+	Cinema   string `json:"cinema"`
+	ShowDate string `json:"showDate"`
+	ShowTime string `json:"showTime"`
+	Price    int    `json:"price"`
+	Capacity int    `json:"capacity"`
+}
+
+// ShowtimeFilter narrows movie listings to what is bookable in a city or cinema.
+type ShowtimeFilter struct {
+	Location string
+	Cinema   string
+}
+
+type SeatHoldRequest struct {
+	Seats []string `json:"seats" binding:"required,min=1,dive,required,max=10"`
+}
+
+// SeatHold is what a customer currently holds on a showtime.
+type SeatHold struct {
+	ShowtimeID int       `json:"showtimeId"`
+	Seats      []string  `json:"seats"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// SeatSnapshot is the full seat map of a showtime, sent first on the seat
+// stream.
+type SeatSnapshot struct {
+	ShowtimeID int      `json:"showtimeId"`
+	Capacity   int      `json:"capacity"`
+	Taken      []string `json:"taken"`
+	Held       []string `json:"held"`
+}
+
+// SeatEvent is pushed on the seat stream when seats change. Type is
+// seat-taken, seat-held or seat-released.
+type SeatEvent struct {
+	Type       string     `json:"type"`
+	ShowtimeID int        `json:"showtimeId"`
+	Seats      []string   `json:"seats"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	At         time.Time  `json:"at"`
+}
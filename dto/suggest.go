@@ -0,0 +1,12 @@
+package dto
+
+type SuggestionItem struct {
+	ID    int    `json:"id"`
+	Label string `json:"label"`
+}
+
+type Suggestions struct {
+	Movies []SuggestionItem `json:"movies"`
+	Actors []SuggestionItem `json:"actors"`
+	Genres []SuggestionItem `json:"genres"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type CreateTransactionRequest struct {
   MovieID       int      `json:"movie_id"`
   ShowDate      string   `json:"show_date"` 
@@ -9,6 +11,8 @@ type CreateTransactionRequest struct {
   Seats         []string `json:"seats"`         
   PricePerSeat  int      `json:"price_per_seat"`
   PaymentMethod int      `json:"payment_method"` 
+  PromoCode     string   `json:"promo_code"`
+  RedeemPoints  int      `json:"redeem_points"`
 }
 
 
@@ -22,4 +26,16 @@ type TransactionSummary struct {
   Seats          []string `json:"seats"`
   TotalPrice     int      `json:"totalPrice"`
   PaymentMethod  string   `json:"paymentMethod"`
+  CreatedAt      time.Time `json:"createdAt"`
+  PromoCode      string   `json:"promoCode,omitempty"`
+  Discount       int      `json:"discount"`
+  PointsRedeemed int      `json:"pointsRedeemed"`
+  PointsDiscount int      `json:"pointsDiscount"`
+  Status         string   `json:"status"`
+  MovieID        int      `json:"movieId"`
+  UserEmail      string   `json:"userEmail,omitempty"`
+}
+
+type UpdateTransactionStatusRequest struct {
+  Status string `json:"status" binding:"required,oneof=paid cancelled refunded"`
 }
\ No newline at end of file
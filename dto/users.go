@@ -7,6 +7,7 @@ type UserListResponse struct {
   Role     string  `json:"role"`
   Phone    *string `json:"phoneNumber,omitempty"`
   Picture  *string `json:"profilePicture,omitempty"`
+  Language string  `json:"language,omitempty"`
 }
 
 type UpdateProfileRequest struct {
@@ -15,4 +16,5 @@ type UpdateProfileRequest struct {
   ProfilePicture *string `json:"profilePicture,omitempty"`
   OldPassword    *string `json:"oldPassword,omitempty"`
   NewPassword    *string `json:"newPassword,omitempty"`
+  Language       *string `json:"language,omitempty"`
 }
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type WatchlistItem struct {
+	MovieID         int       `json:"movieId"`
+	Title           string    `json:"title"`
+	Image           string    `json:"image"`
+	HorizontalImage string    `json:"horizontalImage"`
+	ReleaseDate     time.Time `json:"releaseDate"`
+	Bookable        bool      `json:"bookable"`
+	AddedAt         time.Time `json:"addedAt"`
+}
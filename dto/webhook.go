@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	IsActive    *bool    `json:"isActive"`
+}
+
+// UpdateWebhookRequest changes only the fields that are sent. RotateSecret
+// replaces the signing secret; the new one is returned once.
+type UpdateWebhookRequest struct {
+	URL          *string   `json:"url" binding:"omitempty,url"`
+	Description  *string   `json:"description"`
+	Events       *[]string `json:"events" binding:"omitempty,min=1"`
+	IsActive     *bool     `json:"isActive"`
+	RotateSecret bool      `json:"rotateSecret"`
+}
+
+// Webhook is an endpoint of a partner system subscribed to events. Secret is
+// only returned when it is created or rotated.
+type Webhook struct {
+	ID          int        `json:"id"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Events      []string   `json:"events"`
+	IsActive    bool       `json:"isActive"`
+	Secret      string     `json:"secret,omitempty"`
+	LastStatus  *string    `json:"lastDeliveryStatus"`
+	LastSentAt  *time.Time `json:"lastDeliveryAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// WebhookDelivery is one attempt history of sending an event to a webhook.
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhookId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"maxAttempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	ResponseStatus *int            `json:"responseStatus,omitempty"`
+	ResponseBody   *string         `json:"responseBody,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
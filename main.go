@@ -1,8 +1,11 @@
 package main
 
 import (
+	"be-tickitz/models"
 	"be-tickitz/routers"
+	"be-tickitz/utils"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 
@@ -36,11 +39,19 @@ func main() {
 		AllowOrigins:  []string{"*"},
 		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders: []string{"Content-Length"},
+		ExposeHeaders: []string{"Content-Length", "ETag", "Last-Modified", "Content-Disposition"},
 	}))
 
 	routers.CombineRouter(r)
 
 	godotenv.Load()
+	if err := utils.InitMailer(); err != nil {
+		log.Fatal("Mailer: ", err)
+	}
+	models.StartWatchlistNotifier()
+	models.StartMailWorkers()
+	models.StartWebhookWorkers()
+	models.StartBookingReminders()
+	models.StartSeatHoldSweeper()
 	r.Run(fmt.Sprintf("0.0.0.0:%s", os.Getenv("APP_PORT")))
 }
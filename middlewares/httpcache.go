@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"be-tickitz/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter holds the response back so its ETag can be computed before
+// anything is sent.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// HTTPCache adds a strong ETag (hash of the body), Last-Modified (when the
+// handler called utils.SetLastModified) and Cache-Control to successful GET
+// responses, and answers conditional requests with 304 Not Modified.
+func HTTPCache() gin.HandlerFunc {
+	maxAge := utils.GetEnvInt("HTTP_CACHE_MAX_AGE", 60)
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		if writer.status != http.StatusOK {
+			original.WriteHeader(writer.status)
+			original.Write(writer.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(writer.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+		header := original.Header()
+		header.Set("ETag", etag)
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d, must-revalidate", maxAge))
+
+		lastModified, hasLastModified := utils.GetLastModified(c)
+		if hasLastModified {
+			header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+
+		if notModified(c.Request, etag, lastModified, hasLastModified) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		original.WriteHeader(http.StatusOK)
+		original.Write(writer.body.Bytes())
+	}
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since only
+// when no If-None-Match was sent (RFC 9110 section 13.2.2).
+func notModified(r *http.Request, etag string, lastModified time.Time, hasLastModified bool) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && hasLastModified {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_movie_directors_movie_order;
+DROP INDEX IF EXISTS idx_movie_casts_movie_order;
+
+ALTER TABLE movie_directors
+DROP COLUMN credit_order,
+DROP COLUMN job;
+
+ALTER TABLE movie_casts
+DROP COLUMN billing_order,
+DROP COLUMN role_name;
@@ -0,0 +1,10 @@
+ALTER TABLE movie_casts
+ADD COLUMN role_name VARCHAR(255),
+ADD COLUMN billing_order INT NOT NULL DEFAULT 0;
+
+ALTER TABLE movie_directors
+ADD COLUMN job VARCHAR(100) NOT NULL DEFAULT 'Director',
+ADD COLUMN credit_order INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_movie_casts_movie_order ON movie_casts (id_movie, billing_order);
+CREATE INDEX idx_movie_directors_movie_order ON movie_directors (id_movie, credit_order);
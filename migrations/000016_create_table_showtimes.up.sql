@@ -0,0 +1,15 @@
+CREATE TABLE showtimes (
+  id SERIAL PRIMARY KEY,
+  id_movie INT REFERENCES movies(id) ON DELETE CASCADE,
+  location VARCHAR(255) NOT NULL,
+  cinema VARCHAR(255) NOT NULL,
+  show_date DATE NOT NULL,
+  show_time TIME NOT NULL,
+  price INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT showtimes_slot_unique UNIQUE (id_movie, location, cinema, show_date, show_time)
+);
+
+CREATE INDEX idx_showtimes_movie_date ON showtimes (id_movie, show_date);
+CREATE INDEX idx_showtimes_date ON showtimes (show_date);
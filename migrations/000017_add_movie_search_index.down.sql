@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_directors_name_trgm;
+DROP INDEX IF EXISTS idx_actors_name_trgm;
+DROP INDEX IF EXISTS idx_movies_title_trgm;
+DROP INDEX IF EXISTS idx_movies_search_vector;
+
+DROP TRIGGER IF EXISTS movie_casts_search_vector_update ON movie_casts;
+DROP TRIGGER IF EXISTS movie_directors_search_vector_update ON movie_directors;
+DROP TRIGGER IF EXISTS movie_genres_search_vector_update ON movie_genres;
+DROP TRIGGER IF EXISTS movies_search_vector_update ON movies;
+
+DROP FUNCTION IF EXISTS movie_credits_search_vector_trigger();
+DROP FUNCTION IF EXISTS movies_search_vector_trigger();
+DROP FUNCTION IF EXISTS movie_search_document(INT, TEXT, TEXT);
+
+ALTER TABLE movies DROP COLUMN search_vector;
@@ -0,0 +1,73 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE movies ADD COLUMN search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION movie_search_document(movie_id INT, movie_title TEXT, movie_description TEXT)
+RETURNS TSVECTOR AS $$
+  SELECT
+    setweight(to_tsvector('simple', COALESCE(movie_title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE((
+      SELECT STRING_AGG(g.genre_name, ' ')
+      FROM movie_genres mg JOIN genres g ON g.id = mg.id_genre
+      WHERE mg.id_movie = movie_id
+    ), '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE((
+      SELECT STRING_AGG(d.director_name, ' ')
+      FROM movie_directors md JOIN directors d ON d.id = md.id_director
+      WHERE md.id_movie = movie_id
+    ), '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE((
+      SELECT STRING_AGG(a.actor_name, ' ')
+      FROM movie_casts mc JOIN actors a ON a.id = mc.id_actor
+      WHERE mc.id_movie = movie_id
+    ), '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(movie_description, '')), 'C')
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION movies_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.search_vector := movie_search_document(NEW.id, NEW.title, NEW.description);
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER movies_search_vector_update
+BEFORE INSERT OR UPDATE OF title, description ON movies
+FOR EACH ROW EXECUTE FUNCTION movies_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION movie_credits_search_vector_trigger() RETURNS TRIGGER AS $$
+DECLARE
+  target_movie INT;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    target_movie := OLD.id_movie;
+  ELSE
+    target_movie := NEW.id_movie;
+  END IF;
+
+  UPDATE movies
+  SET search_vector = movie_search_document(id, title, description)
+  WHERE id = target_movie;
+
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER movie_genres_search_vector_update
+AFTER INSERT OR UPDATE OR DELETE ON movie_genres
+FOR EACH ROW EXECUTE FUNCTION movie_credits_search_vector_trigger();
+
+CREATE TRIGGER movie_directors_search_vector_update
+AFTER INSERT OR UPDATE OR DELETE ON movie_directors
+FOR EACH ROW EXECUTE FUNCTION movie_credits_search_vector_trigger();
+
+CREATE TRIGGER movie_casts_search_vector_update
+AFTER INSERT OR UPDATE OR DELETE ON movie_casts
+FOR EACH ROW EXECUTE FUNCTION movie_credits_search_vector_trigger();
+
+UPDATE movies SET search_vector = movie_search_document(id, title, description);
+
+CREATE INDEX idx_movies_search_vector ON movies USING GIN (search_vector);
+CREATE INDEX idx_movies_title_trgm ON movies USING GIN (title gin_trgm_ops);
+CREATE INDEX idx_actors_name_trgm ON actors USING GIN (actor_name gin_trgm_ops);
+CREATE INDEX idx_directors_name_trgm ON directors USING GIN (director_name gin_trgm_ops);
@@ -0,0 +1,16 @@
+CREATE TABLE reviews (
+  id SERIAL PRIMARY KEY,
+  id_user INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  id_movie INT NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+  rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+  review TEXT NOT NULL DEFAULT '',
+  status VARCHAR(20) NOT NULL DEFAULT 'visible' CHECK (status IN ('visible', 'flagged', 'hidden')),
+  moderation_note TEXT,
+  moderated_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT reviews_user_movie_unique UNIQUE (id_user, id_movie)
+);
+
+CREATE INDEX idx_reviews_movie_status ON reviews (id_movie, status);
+CREATE INDEX idx_reviews_status ON reviews (status);
@@ -0,0 +1,12 @@
+CREATE TABLE watchlists (
+  id SERIAL PRIMARY KEY,
+  id_user INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  id_movie INT NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+  notified_showtime_at TIMESTAMP,
+  notified_release_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  CONSTRAINT watchlists_user_movie_unique UNIQUE (id_user, id_movie)
+);
+
+CREATE INDEX idx_watchlists_pending ON watchlists (id_movie)
+  WHERE notified_showtime_at IS NULL OR notified_release_at IS NULL;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_transactions_promo_user;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS discount_amount,
+DROP COLUMN IF EXISTS promo_code,
+DROP COLUMN IF EXISTS id_promo;
+
+DROP TABLE IF EXISTS promo_payment_methods;
+DROP TABLE IF EXISTS promo_cinemas;
+DROP TABLE IF EXISTS promo_movies;
+DROP TABLE IF EXISTS promos;
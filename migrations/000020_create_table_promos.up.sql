@@ -0,0 +1,43 @@
+CREATE TABLE promos (
+  id SERIAL PRIMARY KEY,
+  code VARCHAR(50) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+  discount_value INT NOT NULL CHECK (discount_value > 0),
+  max_discount INT CHECK (max_discount > 0),
+  min_spend INT NOT NULL DEFAULT 0,
+  usage_limit INT CHECK (usage_limit > 0),
+  per_user_limit INT CHECK (per_user_limit > 0),
+  starts_at TIMESTAMP,
+  ends_at TIMESTAMP,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX promos_code_unique ON promos (UPPER(code));
+
+CREATE TABLE promo_movies (
+  id_promo INT NOT NULL REFERENCES promos(id) ON DELETE CASCADE,
+  id_movie INT NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+  PRIMARY KEY (id_promo, id_movie)
+);
+
+CREATE TABLE promo_cinemas (
+  id_promo INT NOT NULL REFERENCES promos(id) ON DELETE CASCADE,
+  cinema VARCHAR(255) NOT NULL,
+  PRIMARY KEY (id_promo, cinema)
+);
+
+CREATE TABLE promo_payment_methods (
+  id_promo INT NOT NULL REFERENCES promos(id) ON DELETE CASCADE,
+  id_payment_method INT NOT NULL REFERENCES payment_method(id) ON DELETE CASCADE,
+  PRIMARY KEY (id_promo, id_payment_method)
+);
+
+ALTER TABLE transactions
+ADD COLUMN id_promo INT REFERENCES promos(id) ON DELETE SET NULL,
+ADD COLUMN promo_code VARCHAR(50),
+ADD COLUMN discount_amount INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_transactions_promo_user ON transactions (id_promo, id_user);
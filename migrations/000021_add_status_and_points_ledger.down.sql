@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS points_ledger;
+
+DROP INDEX IF EXISTS idx_transactions_user_status;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS points_discount,
+DROP COLUMN IF EXISTS points_redeemed,
+DROP COLUMN IF EXISTS paid_at,
+DROP COLUMN IF EXISTS status;
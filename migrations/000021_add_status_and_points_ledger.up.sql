@@ -0,0 +1,23 @@
+ALTER TABLE transactions
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'paid' CHECK (status IN ('pending', 'paid', 'cancelled', 'refunded')),
+ADD COLUMN paid_at TIMESTAMP,
+ADD COLUMN points_redeemed INT NOT NULL DEFAULT 0,
+ADD COLUMN points_discount INT NOT NULL DEFAULT 0;
+
+UPDATE transactions SET paid_at = created_at WHERE status = 'paid';
+
+CREATE INDEX idx_transactions_user_status ON transactions (id_user, status);
+
+CREATE TABLE points_ledger (
+  id SERIAL PRIMARY KEY,
+  id_user INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  id_transaction INT REFERENCES transactions(id) ON DELETE SET NULL,
+  entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('earn', 'redeem', 'reversal', 'refund', 'adjust')),
+  points INT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_points_ledger_user ON points_ledger (id_user, created_at DESC);
+CREATE UNIQUE INDEX points_ledger_transaction_entry_unique ON points_ledger (id_transaction, entry_type)
+  WHERE id_transaction IS NOT NULL AND entry_type <> 'adjust';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_showtime_slot;
+DROP INDEX IF EXISTS idx_transactions_created_status;
+
+ALTER TABLE showtimes DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,5 @@
+ALTER TABLE showtimes
+ADD COLUMN capacity INT NOT NULL DEFAULT 100 CHECK (capacity > 0);
+
+CREATE INDEX idx_transactions_created_status ON transactions (created_at, status);
+CREATE INDEX idx_transactions_showtime_slot ON transactions (id_movie, show_date, show_time);
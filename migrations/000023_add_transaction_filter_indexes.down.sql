@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_email_lower;
+DROP INDEX IF EXISTS idx_transactions_status_created;
+DROP INDEX IF EXISTS idx_transactions_location_cinema;
+DROP INDEX IF EXISTS idx_transactions_payment_method;
+DROP INDEX IF EXISTS idx_transactions_total_price;
+DROP INDEX IF EXISTS idx_transactions_show_date;
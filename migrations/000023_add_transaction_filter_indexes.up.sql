@@ -0,0 +1,6 @@
+CREATE INDEX idx_transactions_show_date ON transactions (show_date);
+CREATE INDEX idx_transactions_total_price ON transactions (total_price);
+CREATE INDEX idx_transactions_payment_method ON transactions (payment_method);
+CREATE INDEX idx_transactions_location_cinema ON transactions (LOWER(location), LOWER(cinema));
+CREATE INDEX idx_transactions_status_created ON transactions (status, created_at DESC);
+CREATE INDEX idx_users_email_lower ON users (LOWER(email));
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_reminder_due;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS reminder_sent_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS language;
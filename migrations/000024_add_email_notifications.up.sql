@@ -0,0 +1,8 @@
+ALTER TABLE users
+ADD COLUMN language VARCHAR(8) NOT NULL DEFAULT 'en';
+
+ALTER TABLE transactions
+ADD COLUMN reminder_sent_at TIMESTAMP;
+
+CREATE INDEX idx_transactions_reminder_due ON transactions (show_date, show_time)
+WHERE status = 'paid' AND reminder_sent_at IS NULL;
@@ -0,0 +1,19 @@
+CREATE TABLE email_outbox (
+  id SERIAL PRIMARY KEY,
+  recipient VARCHAR(255) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  html_body TEXT NOT NULL,
+  text_body TEXT NOT NULL DEFAULT '',
+  status VARCHAR(10) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sending', 'sent', 'dead')),
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL DEFAULT 5,
+  next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  last_error TEXT,
+  sent_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_outbox_due ON email_outbox (next_attempt_at)
+WHERE status IN ('queued', 'sending');
+CREATE INDEX idx_email_outbox_status ON email_outbox (status, created_at DESC);
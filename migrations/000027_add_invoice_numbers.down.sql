@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+DROP COLUMN invoice_number,
+DROP COLUMN invoice_tax_rate;
+
+DROP SEQUENCE invoice_number_seq;
@@ -0,0 +1,21 @@
+CREATE SEQUENCE invoice_number_seq;
+
+ALTER TABLE transactions
+ADD COLUMN invoice_number VARCHAR(20) UNIQUE,
+ADD COLUMN invoice_tax_rate INT;
+
+-- Transactions paid before invoices existed are numbered in payment order,
+-- with the default PPN rate.
+UPDATE transactions t SET
+  invoice_number = 'INV-' || to_char(t.paid_at, 'YYYY') || '-' || lpad(n.num::text, 6, '0'),
+  invoice_tax_rate = 11
+FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY paid_at, id) AS num
+  FROM transactions
+  WHERE paid_at IS NOT NULL
+) n
+WHERE n.id = t.id;
+
+SELECT setval('invoice_number_seq', GREATEST(COUNT(*), 1), COUNT(*) > 0)
+FROM transactions
+WHERE invoice_number IS NOT NULL;
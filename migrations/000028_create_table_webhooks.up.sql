@@ -0,0 +1,31 @@
+CREATE TABLE webhooks (
+  id SERIAL PRIMARY KEY,
+  url TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  secret VARCHAR(100) NOT NULL,
+  events TEXT[] NOT NULL,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE webhook_deliveries (
+  id SERIAL PRIMARY KEY,
+  id_webhook INT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  event VARCHAR(50) NOT NULL,
+  payload JSONB NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sending', 'delivered', 'failed')),
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL DEFAULT 8,
+  next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  response_status INT,
+  response_body TEXT,
+  last_error TEXT,
+  delivered_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at)
+WHERE status IN ('queued', 'sending');
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (id_webhook, created_at DESC);
@@ -0,0 +1,10 @@
+CREATE TABLE seat_holds (
+  id_showtime INT NOT NULL REFERENCES showtimes(id) ON DELETE CASCADE,
+  seat VARCHAR(10) NOT NULL,
+  id_user INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT NOW(),
+  PRIMARY KEY (id_showtime, seat)
+);
+
+CREATE INDEX idx_seat_holds_expires_at ON seat_holds (expires_at);
@@ -0,0 +1,16 @@
+CREATE TABLE audit_log (
+  id BIGSERIAL PRIMARY KEY,
+  id_actor INT,
+  action VARCHAR(50) NOT NULL,
+  entity_type VARCHAR(50) NOT NULL,
+  entity_id INT,
+  before JSONB,
+  after JSONB,
+  ip_address VARCHAR(45),
+  user_agent TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX idx_audit_log_actor ON audit_log (id_actor);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE movies DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE genres DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE directors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE actors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE payment_method DROP COLUMN IF EXISTS deleted_at;
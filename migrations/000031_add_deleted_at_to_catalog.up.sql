@@ -0,0 +1,5 @@
+ALTER TABLE movies ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE genres ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE directors ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE actors ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE payment_method ADD COLUMN deleted_at TIMESTAMP;
@@ -0,0 +1,2 @@
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_external_id_unique;
+ALTER TABLE movies DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,2 @@
+ALTER TABLE movies ADD COLUMN external_id VARCHAR(100) NOT NULL DEFAULT gen_random_uuid()::text;
+ALTER TABLE movies ADD CONSTRAINT movies_external_id_unique UNIQUE (external_id);
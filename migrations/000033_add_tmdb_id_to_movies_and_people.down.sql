@@ -0,0 +1,6 @@
+ALTER TABLE actors DROP CONSTRAINT IF EXISTS actors_tmdb_id_unique;
+ALTER TABLE actors DROP COLUMN IF EXISTS tmdb_id;
+ALTER TABLE directors DROP CONSTRAINT IF EXISTS directors_tmdb_id_unique;
+ALTER TABLE directors DROP COLUMN IF EXISTS tmdb_id;
+ALTER TABLE movies DROP CONSTRAINT IF EXISTS movies_tmdb_id_unique;
+ALTER TABLE movies DROP COLUMN IF EXISTS tmdb_id;
@@ -0,0 +1,6 @@
+ALTER TABLE movies ADD COLUMN tmdb_id INT;
+ALTER TABLE movies ADD CONSTRAINT movies_tmdb_id_unique UNIQUE (tmdb_id);
+ALTER TABLE directors ADD COLUMN tmdb_id INT;
+ALTER TABLE directors ADD CONSTRAINT directors_tmdb_id_unique UNIQUE (tmdb_id);
+ALTER TABLE actors ADD COLUMN tmdb_id INT;
+ALTER TABLE actors ADD CONSTRAINT actors_tmdb_id_unique UNIQUE (tmdb_id);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS actors_search_vector_update ON actors;
+DROP TRIGGER IF EXISTS directors_search_vector_update ON directors;
+DROP TRIGGER IF EXISTS genres_search_vector_update ON genres;
+
+DROP FUNCTION IF EXISTS catalog_name_search_vector_trigger();
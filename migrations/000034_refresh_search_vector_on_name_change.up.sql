@@ -0,0 +1,33 @@
+CREATE OR REPLACE FUNCTION catalog_name_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_TABLE_NAME = 'genres' THEN
+    UPDATE movies SET search_vector = movie_search_document(id, title, description)
+    WHERE id IN (SELECT id_movie FROM movie_genres WHERE id_genre = NEW.id);
+  ELSIF TG_TABLE_NAME = 'directors' THEN
+    UPDATE movies SET search_vector = movie_search_document(id, title, description)
+    WHERE id IN (SELECT id_movie FROM movie_directors WHERE id_director = NEW.id);
+  ELSE
+    UPDATE movies SET search_vector = movie_search_document(id, title, description)
+    WHERE id IN (SELECT id_movie FROM movie_casts WHERE id_actor = NEW.id);
+  END IF;
+
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER genres_search_vector_update
+AFTER UPDATE OF genre_name ON genres
+FOR EACH ROW WHEN (OLD.genre_name IS DISTINCT FROM NEW.genre_name)
+EXECUTE FUNCTION catalog_name_search_vector_trigger();
+
+CREATE TRIGGER directors_search_vector_update
+AFTER UPDATE OF director_name ON directors
+FOR EACH ROW WHEN (OLD.director_name IS DISTINCT FROM NEW.director_name)
+EXECUTE FUNCTION catalog_name_search_vector_trigger();
+
+CREATE TRIGGER actors_search_vector_update
+AFTER UPDATE OF actor_name ON actors
+FOR EACH ROW WHEN (OLD.actor_name IS DISTINCT FROM NEW.actor_name)
+EXECUTE FUNCTION catalog_name_search_vector_trigger();
+
+UPDATE movies SET search_vector = movie_search_document(id, title, description);
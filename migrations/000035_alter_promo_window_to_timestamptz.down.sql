@@ -0,0 +1,3 @@
+ALTER TABLE promos
+ALTER COLUMN starts_at TYPE TIMESTAMP USING starts_at AT TIME ZONE 'UTC',
+ALTER COLUMN ends_at TYPE TIMESTAMP USING ends_at AT TIME ZONE 'UTC';
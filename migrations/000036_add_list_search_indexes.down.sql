@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_webhooks_description_trgm;
+DROP INDEX IF EXISTS idx_webhooks_url_trgm;
+DROP INDEX IF EXISTS idx_email_outbox_subject_trgm;
+DROP INDEX IF EXISTS idx_email_outbox_recipient_trgm;
+DROP INDEX IF EXISTS idx_promos_description_trgm;
+DROP INDEX IF EXISTS idx_promos_code_trgm;
+DROP INDEX IF EXISTS idx_reviews_review_trgm;
+DROP INDEX IF EXISTS idx_users_full_name_trgm;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_genres_name_trgm;
+DROP INDEX IF EXISTS idx_movies_external_id_trgm;
+
+DROP INDEX IF EXISTS idx_transactions_cinema_lower;
@@ -0,0 +1,15 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_transactions_cinema_lower ON transactions (LOWER(cinema));
+
+CREATE INDEX idx_movies_external_id_trgm ON movies USING GIN (external_id gin_trgm_ops);
+CREATE INDEX idx_genres_name_trgm ON genres USING GIN (genre_name gin_trgm_ops);
+CREATE INDEX idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX idx_users_full_name_trgm ON users USING GIN (full_name gin_trgm_ops);
+CREATE INDEX idx_reviews_review_trgm ON reviews USING GIN (review gin_trgm_ops);
+CREATE INDEX idx_promos_code_trgm ON promos USING GIN (code gin_trgm_ops);
+CREATE INDEX idx_promos_description_trgm ON promos USING GIN (description gin_trgm_ops);
+CREATE INDEX idx_email_outbox_recipient_trgm ON email_outbox USING GIN (recipient gin_trgm_ops);
+CREATE INDEX idx_email_outbox_subject_trgm ON email_outbox USING GIN (subject gin_trgm_ops);
+CREATE INDEX idx_webhooks_url_trgm ON webhooks USING GIN (url gin_trgm_ops);
+CREATE INDEX idx_webhooks_description_trgm ON webhooks USING GIN (description gin_trgm_ops);
@@ -4,6 +4,8 @@ import (
   "be-tickitz/dto"
   "be-tickitz/utils"
   "context"
+  "strconv"
+  "time"
 
   "github.com/jackc/pgx/v5"
 )
@@ -11,49 +13,82 @@ import (
 type Actor struct {
   ID         int    `json:"id"`
   ActorName  string `json:"actorName" db:"actor_name"`
+  DeletedAt  *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 }
 
-func CreateActor(input dto.Actor) (Actor, error) {
-  conn, err := utils.ConnectDB()
-  if err != nil {
-    return Actor{}, err
-  }
-  defer conn.Release()
-
+func CreateActor(admin dto.AuditActor, input dto.Actor) (Actor, error) {
   var actor Actor
-  err = conn.QueryRow(context.Background(), `
-    INSERT INTO actors (actor_name)
-    VALUES ($1)
-    RETURNING id, actor_name
-  `, input.ActorName).Scan(&actor.ID, &actor.ActorName)
+  _, err := withAudit(admin, "create", "actor", 0, func(tx pgx.Tx) (int, error) {
+    err := tx.QueryRow(context.Background(), `
+      INSERT INTO actors (actor_name)
+      VALUES ($1)
+      RETURNING id, actor_name, deleted_at
+    `, input.ActorName).Scan(&actor.ID, &actor.ActorName, &actor.DeletedAt)
+    return actor.ID, err
+  })
+  if err == nil {
+    invalidateCache(CacheTagPeople)
+    RefreshSuggestIndexAsync()
+  }
 
   return actor, err
 }
 
-func GetAllActors() ([]Actor, error) {
+var actorListSpec = listSpec{
+  Sorts: map[string]sortColumn{
+    "id":   {Column: "id", Cast: "int"},
+    "name": {Column: "actor_name", Cast: "text"},
+  },
+  DefaultSort:   "name",
+  SearchColumns: []string{"actor_name"},
+}
+
+// GetAllActors lists actors; public listings use ScopeLive.
+func GetAllActors(q utils.PageQuery, scope CatalogScope) ([]Actor, utils.PageResult, error) {
   conn, err := utils.ConnectDB()
   if err != nil {
-    return nil, err
+    return nil, utils.PageResult{}, err
   }
   defer conn.Release()
 
-  rows, err := conn.Query(context.Background(), `
-    SELECT id, actor_name FROM actors ORDER BY actor_name ASC
-  `)
+  rows, total, err := queryPage(conn, `
+    SELECT id, actor_name, deleted_at FROM actors
+    WHERE `+scope.sql("deleted_at")+`
+  `, nil, q, actorListSpec)
   if err != nil {
-    return nil, err
+    return nil, utils.PageResult{}, err
   }
 
-  return pgx.CollectRows(rows, pgx.RowToStructByName[Actor])
+  actors, err := pgx.CollectRows(rows, pgx.RowToStructByName[Actor])
+  if err != nil {
+    return nil, utils.PageResult{}, err
+  }
+
+  key, _ := actorListSpec.sortKey(q)
+  actors, nextCursor := trimPage(actors, q, func(a Actor) (string, int) {
+    if key == "id" {
+      return strconv.Itoa(a.ID), a.ID
+    }
+    return a.ActorName, a.ID
+  })
+
+  return actors, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
-func DeleteActor(id string) error {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return err
+func DeleteActor(admin dto.AuditActor, id int) error {
+	err := softDelete(admin, "actor", id, ErrActorNotFound)
+	if err == nil {
+		invalidateCache(CacheTagPeople)
+		RefreshSuggestIndexAsync()
 	}
-	defer conn.Release()
+	return err
+}
 
-	_, err = conn.Exec(context.Background(), `DELETE FROM actors WHERE id = $1`, id)
+func RestoreActor(admin dto.AuditActor, id int) error {
+	err := restoreDeleted(admin, "actor", id, ErrActorNotFound)
+	if err == nil {
+		invalidateCache(CacheTagPeople)
+		RefreshSuggestIndexAsync()
+	}
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,243 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// auditEntity describes how an audited entity is recorded: its table, the
+// columns only logged as a fingerprint, and related data (a jsonb expression
+// over the row aliased x) recorded with the row.
+type auditEntity struct {
+	Table    string
+	Redacted []string
+	Extra    string
+}
+
+var auditEntities = map[string]auditEntity{
+	"movie": {Table: "movies", Extra: `jsonb_build_object(
+      'genre_ids', ARRAY(SELECT id_genre FROM movie_genres WHERE id_movie = x.id ORDER BY id_genre),
+      'crew', COALESCE((SELECT jsonb_agg(jsonb_build_object('director_id', id_director, 'job', job, 'order', credit_order)
+        ORDER BY credit_order, id) FROM movie_directors WHERE id_movie = x.id), '[]'),
+      'cast', COALESCE((SELECT jsonb_agg(jsonb_build_object('actor_id', id_actor, 'role', role_name, 'order', billing_order)
+        ORDER BY billing_order, id) FROM movie_casts WHERE id_movie = x.id), '[]'))`},
+	"genre":          {Table: "genres"},
+	"director":       {Table: "directors"},
+	"actor":          {Table: "actors"},
+	"payment_method": {Table: "payment_method"},
+	"promo": {Table: "promos", Extra: `jsonb_build_object(
+      'movie_ids', ARRAY(SELECT id_movie FROM promo_movies WHERE id_promo = x.id ORDER BY id_movie),
+      'cinemas', ARRAY(SELECT cinema FROM promo_cinemas WHERE id_promo = x.id ORDER BY cinema),
+      'payment_method_ids', ARRAY(SELECT id_payment_method FROM promo_payment_methods WHERE id_promo = x.id ORDER BY id_payment_method))`},
+	"review":           {Table: "reviews"},
+	"showtime":         {Table: "showtimes"},
+	"transaction":      {Table: "transactions"},
+	"user":             {Table: "users", Redacted: []string{"password"}},
+	"email":            {Table: "email_outbox", Redacted: []string{"html_body", "text_body", "attachments"}},
+	"webhook":          {Table: "webhooks", Redacted: []string{"secret"}},
+	"webhook_delivery": {Table: "webhook_deliveries", Redacted: []string{"payload", "response_body"}},
+}
+
+// auditSnapshot returns an entity's row as JSON, or nil when there is none.
+// Redacted columns are replaced by a fingerprint, so the log shows that they
+// changed without revealing them.
+func auditSnapshot(db querier, entityType string, id int) (map[string]any, error) {
+	entity, ok := auditEntities[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unknown audit entity %q", entityType)
+	}
+	row := `to_jsonb(x)`
+	if entity.Extra != "" {
+		row += ` || ` + entity.Extra
+	}
+
+	var snapshot map[string]any
+	err := db.QueryRow(context.Background(), `
+    SELECT `+row+` FROM `+entity.Table+` x WHERE x.id = $1
+  `, id).Scan(&snapshot)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s %d for the audit log: %v", entityType, id, err)
+	}
+
+	for _, column := range entity.Redacted {
+		if value, ok := snapshot[column]; ok && value != nil {
+			raw, _ := json.Marshal(value)
+			sum := sha256.Sum256(raw)
+			snapshot[column] = "sha256:" + hex.EncodeToString(sum[:6])
+		}
+	}
+	return snapshot, nil
+}
+
+// auditDiff keeps only the fields that differ between before and after. A
+// create or delete is kept whole.
+func auditDiff(before, after map[string]any) (map[string]any, map[string]any) {
+	if before == nil || after == nil {
+		return before, after
+	}
+	changedBefore, changedAfter := map[string]any{}, map[string]any{}
+	for key, value := range before {
+		if !reflect.DeepEqual(value, after[key]) {
+			changedBefore[key] = value
+		}
+	}
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(value, old) {
+			changedAfter[key] = value
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+func auditJSON(value map[string]any) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+// recordAudit logs a change made by an admin. before is the entity as it was
+// (nil for a create) and the current state is read from db, so it must be
+// called in the change's transaction after the change. Nothing is logged when
+// the entity neither existed nor exists.
+func recordAudit(db querier, admin dto.AuditActor, action, entityType string, id int, before map[string]any) error {
+	after, err := auditSnapshot(db, entityType, id)
+	if err != nil {
+		return err
+	}
+	if before == nil && after == nil {
+		return nil
+	}
+	before, after = auditDiff(before, after)
+
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(context.Background(), `
+    INSERT INTO audit_log (id_actor, action, entity_type, entity_id, before, after, ip_address, user_agent)
+    VALUES (NULLIF($1, 0), $2, $3, $4, $5::jsonb, $6::jsonb, NULLIF($7, ''), NULLIF($8, ''))
+  `, admin.UserID, action, entityType, id, beforeJSON, afterJSON, admin.IP, admin.UserAgent)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %v", err)
+	}
+	return nil
+}
+
+// withAudit runs change in a transaction and logs it there, so the change and
+// its audit entry are committed together. id is the entity changed, 0 for a
+// create; change returns the ID of the entity it touched.
+func withAudit(admin dto.AuditActor, action, entityType string, id int, change func(tx pgx.Tx) (int, error)) (int, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(context.Background())
+
+	var before map[string]any
+	if id > 0 {
+		if before, err = auditSnapshot(tx, entityType, id); err != nil {
+			return 0, err
+		}
+	}
+
+	id, err = change(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordAudit(tx, admin, action, entityType, id, before); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(context.Background()); err != nil {
+		return 0, fmt.Errorf("commit failed: %v", err)
+	}
+	return id, nil
+}
+
+var auditListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":        {Column: "id", Cast: "int"},
+		"createdAt": {Column: "created_at", Cast: "timestamp"},
+	},
+	DefaultSort:   "createdAt",
+	DefaultDesc:   true,
+	SearchColumns: []string{"actor_email"},
+	Filters: map[string]filterColumn{
+		"actorId":    {Column: "id_actor", Kind: filterInt},
+		"action":     {Column: "action", Kind: filterCode},
+		"entityType": {Column: "entity_type", Kind: filterCode},
+		"entityId":   {Column: "entity_id", Kind: filterInt},
+	},
+	Ranges: []rangeFilter{
+		{Column: "created_date", Cast: "date", MinParam: "from", MaxParam: "to"},
+	},
+}
+
+// GetAuditLog lists audit log entries, newest first by default.
+func GetAuditLog(q utils.PageQuery) ([]dto.AuditEntry, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, `
+    SELECT a.id, a.id_actor, COALESCE(u.email, '') AS actor_email, a.action, a.entity_type,
+      a.entity_id, a.before, a.after, COALESCE(a.ip_address, '') AS ip_address,
+      COALESCE(a.user_agent, '') AS user_agent, a.created_at, a.created_at::date AS created_date
+    FROM audit_log a
+    LEFT JOIN users u ON u.id = a.id_actor
+  `, nil, q, auditListSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	entries := []dto.AuditEntry{}
+	for rows.Next() {
+		var e dto.AuditEntry
+		var createdDate any
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.ActorEmail, &e.Action, &e.EntityType, &e.EntityID,
+			&e.Before, &e.After, &e.IPAddress, &e.UserAgent, &e.CreatedAt, &createdDate); err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := auditListSpec.sortKey(q)
+	entries, nextCursor := trimPage(entries, q, func(e dto.AuditEntry) (string, int) {
+		if key == "createdAt" {
+			return e.CreatedAt.Format("2006-01-02 15:04:05.999999"), e.ID
+		}
+		return strconv.Itoa(e.ID), e.ID
+	})
+	return entries, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
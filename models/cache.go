@@ -0,0 +1,25 @@
+package models
+
+import (
+	"be-tickitz/utils"
+	"context"
+	"fmt"
+)
+
+// Cache tags used by the catalog endpoints. Every mutation invalidates the
+// tags of the data it touches; see utils.CacheRemember.
+const (
+	CacheTagMovies    = "movies"
+	CacheTagGenres    = "genres"
+	CacheTagPeople    = "people"
+	CacheTagShowtimes = "showtimes"
+	CacheTagReviews   = "reviews"
+)
+
+func MovieCacheTag(id int) string {
+	return fmt.Sprintf("movie:%d", id)
+}
+
+func invalidateCache(tags ...string) {
+	utils.CacheInvalidate(context.Background(), tags...)
+}
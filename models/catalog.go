@@ -0,0 +1,88 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Movies, genres, directors, actors and payment methods are soft deleted:
+// deleted_at hides them from public endpoints while transactions, reports and
+// admins still see them, and a delete can be undone with a restore.
+
+var (
+	ErrGenreNotFound         = errors.New("genre not found")
+	ErrDirectorNotFound      = errors.New("director not found")
+	ErrActorNotFound         = errors.New("actor not found")
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+)
+
+// CatalogScope selects which catalog rows a listing returns.
+type CatalogScope string
+
+const (
+	ScopeLive    CatalogScope = "live"
+	ScopeDeleted CatalogScope = "deleted"
+	ScopeAll     CatalogScope = "all"
+)
+
+// sql is the condition on the deleted_at column for the scope.
+func (s CatalogScope) sql(column string) string {
+	switch s {
+	case ScopeAll:
+		return `TRUE`
+	case ScopeDeleted:
+		return column + ` IS NOT NULL`
+	default:
+		return column + ` IS NULL`
+	}
+}
+
+// requireLive returns notFound unless the catalog entity exists and is not
+// deleted.
+func requireLive(db querier, entityType string, id int, notFound error) error {
+	var live bool
+	err := db.QueryRow(context.Background(), `
+    SELECT EXISTS (SELECT 1 FROM `+auditEntities[entityType].Table+` WHERE id = $1 AND deleted_at IS NULL)
+  `, id).Scan(&live)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %v", entityType, err)
+	}
+	if !live {
+		return notFound
+	}
+	return nil
+}
+
+// softDelete marks a catalog entity deleted, or returns notFound when it does
+// not exist or is already deleted.
+func softDelete(admin dto.AuditActor, entityType string, id int, notFound error) error {
+	return setDeleted(admin, "delete", entityType, id, true, notFound)
+}
+
+// restoreDeleted brings a soft deleted catalog entity back, or returns
+// notFound when there is no deleted entity with that ID.
+func restoreDeleted(admin dto.AuditActor, entityType string, id int, notFound error) error {
+	return setDeleted(admin, "restore", entityType, id, false, notFound)
+}
+
+func setDeleted(admin dto.AuditActor, action, entityType string, id int, deleted bool, notFound error) error {
+	_, err := withAudit(admin, action, entityType, id, func(tx pgx.Tx) (int, error) {
+		tag, err := tx.Exec(context.Background(), `
+      UPDATE `+auditEntities[entityType].Table+`
+      SET deleted_at = CASE WHEN $2 THEN NOW() END, updated_at = NOW()
+      WHERE id = $1 AND (deleted_at IS NULL) = $2
+    `, id, deleted)
+		if err != nil {
+			return 0, fmt.Errorf("failed to %s %s: %v", action, entityType, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, notFound
+		}
+		return id, nil
+	})
+	return err
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// mergeCrew combines the legacy directorIDs list with structured crew input.
+// Plain IDs are credited as "Director" and billed before the structured entries.
+func mergeCrew(directorIDs []int, crew []dto.CrewInput) []dto.CrewInput {
+	merged := make([]dto.CrewInput, 0, len(directorIDs)+len(crew))
+	for _, id := range directorIDs {
+		merged = append(merged, dto.CrewInput{DirectorID: id})
+	}
+	return append(merged, crew...)
+}
+
+// mergeCasts combines the legacy castIDs list with structured cast input.
+func mergeCasts(castIDs []int, casts []dto.CastInput) []dto.CastInput {
+	merged := make([]dto.CastInput, 0, len(castIDs)+len(casts))
+	for _, id := range castIDs {
+		merged = append(merged, dto.CastInput{ActorID: id})
+	}
+	return append(merged, casts...)
+}
+
+func insertMovieCrew(tx pgx.Tx, movieID int, crew []dto.CrewInput) error {
+	for i, c := range crew {
+		job := c.Job
+		if job == "" {
+			job = "Director"
+		}
+		order := i
+		if c.Order != nil {
+			order = *c.Order
+		}
+
+		_, err := tx.Exec(context.Background(), `
+      INSERT INTO movie_directors (id_movie, id_director, job, credit_order)
+      VALUES ($1, $2, $3, $4)
+    `, movieID, c.DirectorID, job, order)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertMovieCasts(tx pgx.Tx, movieID int, casts []dto.CastInput) error {
+	for i, c := range casts {
+		order := i
+		if c.Order != nil {
+			order = *c.Order
+		}
+
+		var roleName *string
+		if c.RoleName != "" {
+			roleName = &c.RoleName
+		}
+
+		_, err := tx.Exec(context.Background(), `
+      INSERT INTO movie_casts (id_movie, id_actor, role_name, billing_order)
+      VALUES ($1, $2, $3, $4)
+    `, movieID, c.ActorID, roleName, order)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
   "be-tickitz/dto"
   "be-tickitz/utils"
   "context"
+  "strconv"
+  "time"
 
   "github.com/jackc/pgx/v5"
 )
@@ -11,49 +13,79 @@ import (
 type Director struct {
   ID           int    `json:"id"`
   DirectorName string `json:"directorName" db:"director_name"`
+  DeletedAt    *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 }
 
-func CreateDirector(input dto.Director) (Director, error) {
-  conn, err := utils.ConnectDB()
-  if err != nil {
-    return Director{}, err
-  }
-  defer conn.Release()
-
+func CreateDirector(admin dto.AuditActor, input dto.Director) (Director, error) {
   var director Director
-  err = conn.QueryRow(context.Background(), `
-    INSERT INTO directors (director_name)
-    VALUES ($1)
-    RETURNING id, director_name
-  `, input.DirectorName).Scan(&director.ID, &director.DirectorName)
+  _, err := withAudit(admin, "create", "director", 0, func(tx pgx.Tx) (int, error) {
+    err := tx.QueryRow(context.Background(), `
+      INSERT INTO directors (director_name)
+      VALUES ($1)
+      RETURNING id, director_name, deleted_at
+    `, input.DirectorName).Scan(&director.ID, &director.DirectorName, &director.DeletedAt)
+    return director.ID, err
+  })
+  if err == nil {
+    invalidateCache(CacheTagPeople)
+  }
 
   return director, err
 }
 
-func GetAllDirectors() ([]Director, error) {
+var directorListSpec = listSpec{
+  Sorts: map[string]sortColumn{
+    "id":   {Column: "id", Cast: "int"},
+    "name": {Column: "director_name", Cast: "text"},
+  },
+  DefaultSort:   "name",
+  SearchColumns: []string{"director_name"},
+}
+
+// GetAllDirectors lists directors; public listings use ScopeLive.
+func GetAllDirectors(q utils.PageQuery, scope CatalogScope) ([]Director, utils.PageResult, error) {
   conn, err := utils.ConnectDB()
   if err != nil {
-    return nil, err
+    return nil, utils.PageResult{}, err
   }
   defer conn.Release()
 
-  rows, err := conn.Query(context.Background(), `
-    SELECT id, director_name FROM directors ORDER BY director_name ASC
-  `)
+  rows, total, err := queryPage(conn, `
+    SELECT id, director_name, deleted_at FROM directors
+    WHERE `+scope.sql("deleted_at")+`
+  `, nil, q, directorListSpec)
   if err != nil {
-    return nil, err
+    return nil, utils.PageResult{}, err
   }
 
-  return pgx.CollectRows(rows, pgx.RowToStructByName[Director])
+  directors, err := pgx.CollectRows(rows, pgx.RowToStructByName[Director])
+  if err != nil {
+    return nil, utils.PageResult{}, err
+  }
+
+  key, _ := directorListSpec.sortKey(q)
+  directors, nextCursor := trimPage(directors, q, func(d Director) (string, int) {
+    if key == "id" {
+      return strconv.Itoa(d.ID), d.ID
+    }
+    return d.DirectorName, d.ID
+  })
+
+  return directors, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
-func DeleteDirector(id string) error {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return err
+func DeleteDirector(admin dto.AuditActor, id int) error {
+	err := softDelete(admin, "director", id, ErrDirectorNotFound)
+	if err == nil {
+		invalidateCache(CacheTagPeople)
 	}
-	defer conn.Release()
+	return err
+}
 
-	_, err = conn.Exec(context.Background(), `DELETE FROM directors WHERE id = $1`, id)
+func RestoreDirector(admin dto.AuditActor, id int) error {
+	err := restoreDeleted(admin, "director", id, ErrDirectorNotFound)
+	if err == nil {
+		invalidateCache(CacheTagPeople)
+	}
 	return err
-}
\ No newline at end of file
+}
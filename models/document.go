@@ -0,0 +1,364 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+var (
+	ErrTicketUnavailable  = errors.New("tickets are only available for paid bookings")
+	ErrInvoiceUnavailable = errors.New("no invoice was issued for this transaction")
+)
+
+// GetTransactionDocument loads one of the user's transactions for its e-ticket
+// and invoice.
+func GetTransactionDocument(transactionID, userID int) (dto.TransactionDocument, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.TransactionDocument{}, err
+	}
+	defer conn.Release()
+
+	if err := checkTransactionOwner(conn, transactionID, userID); err != nil {
+		return dto.TransactionDocument{}, err
+	}
+
+	booking, err := loadBookingEmail(conn, transactionID)
+	if err != nil {
+		return dto.TransactionDocument{}, err
+	}
+
+	doc := dto.TransactionDocument{BookingEmail: booking}
+	var taxRate *int
+	err = conn.QueryRow(context.Background(), `
+    SELECT COALESCE(m.image, ''), t.paid_at, COALESCE(t.invoice_number, ''), t.invoice_tax_rate
+    FROM transactions t
+    JOIN movies m ON m.id = t.id_movie
+    WHERE t.id = $1
+  `, transactionID).Scan(&doc.Poster, &doc.PaidAt, &doc.InvoiceNumber, &taxRate)
+	if err != nil {
+		return doc, fmt.Errorf("failed to load transaction %d: %v", transactionID, err)
+	}
+
+	rows, err := conn.Query(context.Background(), `
+    SELECT seat, price FROM transaction_details
+    WHERE transaction_id = $1
+    ORDER BY seat
+  `, transactionID)
+	if err != nil {
+		return doc, fmt.Errorf("failed to load seats: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seat dto.SeatPrice
+		if err := rows.Scan(&seat.Seat, &seat.Price); err != nil {
+			return doc, err
+		}
+		doc.SeatPrices = append(doc.SeatPrices, seat)
+	}
+	if err := rows.Err(); err != nil {
+		return doc, err
+	}
+
+	// Ticket prices include tax, so the invoice only shows how much of the
+	// total it was.
+	doc.TaxRate = TaxRatePercent()
+	if taxRate != nil {
+		doc.TaxRate = *taxRate
+	}
+	doc.TaxBase = int(math.Round(float64(doc.Total) * 100 / float64(100+doc.TaxRate)))
+	doc.Tax = doc.Total - doc.TaxBase
+	return doc, nil
+}
+
+// TicketFilename and InvoiceFilename are the names the documents are
+// downloaded under.
+func TicketFilename(doc dto.TransactionDocument) string {
+	return fmt.Sprintf("tickitz-ticket-%d.pdf", doc.TransactionID)
+}
+
+func InvoiceFilename(doc dto.TransactionDocument) string {
+	return fmt.Sprintf("tickitz-%s.pdf", strings.ToLower(doc.InvoiceNumber))
+}
+
+const documentFont = "Helvetica"
+
+var documentBrand = [3]int{95, 46, 234}
+
+func newDocumentPDF(size, title string) (*fpdf.Fpdf, func(string) string) {
+	pdf := fpdf.New("P", "mm", size, "")
+	pdf.SetTitle(title, true)
+	pdf.SetAuthor("Tickitz", false)
+	pdf.SetCreator("Tickitz", false)
+	pdf.SetAutoPageBreak(true, 20)
+	// The core fonts only cover Windows-1252; other characters print as ?.
+	return pdf, pdf.UnicodeTranslatorFromDescriptor("")
+}
+
+// documentHeader draws the brand band across the top of the page.
+func documentHeader(pdf *fpdf.Fpdf, tr func(string) string, title, subtitle string) {
+	pageW, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+
+	pdf.SetFillColor(documentBrand[0], documentBrand[1], documentBrand[2])
+	pdf.Rect(0, 0, pageW, 28, "F")
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetXY(left, 9)
+	pdf.SetFont(documentFont, "B", 20)
+	pdf.CellFormat(60, 10, "Tickitz", "", 0, "L", false, 0, "")
+
+	pdf.SetXY(pageW-right-80, 8)
+	pdf.SetFont(documentFont, "B", 13)
+	pdf.CellFormat(80, 7, tr(title), "", 2, "R", false, 0, "")
+	pdf.SetFont(documentFont, "", 9)
+	pdf.CellFormat(80, 5, tr(subtitle), "", 0, "R", false, 0, "")
+	pdf.SetTextColor(30, 30, 30)
+}
+
+// documentField writes a small grey label with its value underneath.
+func documentField(pdf *fpdf.Fpdf, tr func(string) string, x, w float64, label, value string) {
+	pdf.SetX(x)
+	pdf.SetFont(documentFont, "", 8)
+	pdf.SetTextColor(120, 120, 120)
+	pdf.CellFormat(w, 4, tr(label), "", 2, "L", false, 0, "")
+	pdf.SetFont(documentFont, "B", 10)
+	pdf.SetTextColor(30, 30, 30)
+	pdf.MultiCell(w, 5, tr(value), "", "L", false)
+	pdf.Ln(1.5)
+}
+
+// documentPoster draws the movie poster w wide at x, y and returns its
+// height. A poster that cannot be fetched is left out instead of failing the
+// whole document, in which case the height is 0.
+func documentPoster(pdf *fpdf.Fpdf, url string, x, y, w float64) float64 {
+	if url == "" {
+		return 0
+	}
+	img, err := utils.FetchImage(url)
+	if err != nil {
+		log.Println("Document poster:", err.Error())
+		return 0
+	}
+
+	opt := fpdf.ImageOptions{ImageType: img.Type}
+	info := pdf.RegisterImageOptionsReader("poster", opt, bytes.NewReader(img.Data))
+	if pdf.Err() || info == nil || info.Width() == 0 {
+		log.Println("Document poster:", pdf.Error())
+		pdf.ClearError()
+		return 0
+	}
+	h := w * info.Height() / info.Width()
+	pdf.ImageOptions("poster", x, y, w, h, false, opt, 0, "")
+	return h
+}
+
+// documentQRCode draws the ticket code of the transaction as a size x size
+// QR code at x, y.
+func documentQRCode(pdf *fpdf.Fpdf, transactionID int, x, y, size float64) error {
+	png, err := utils.QRCodePNG(utils.TicketCode(transactionID), 512)
+	if err != nil {
+		return fmt.Errorf("failed to create QR code: %v", err)
+	}
+	opt := fpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("qr", opt, bytes.NewReader(png))
+	pdf.ImageOptions("qr", x, y, size, size, false, opt, 0, "")
+	return nil
+}
+
+func documentDate(doc dto.TransactionDocument) string {
+	return doc.ShowDate.Format("Monday, January 2, 2006")
+}
+
+// WriteTicketPDF renders the e-ticket of a paid booking: the show, the seats
+// and a QR code with the signed ticket code to scan at the entrance.
+func WriteTicketPDF(w io.Writer, doc dto.TransactionDocument) error {
+	if doc.Status != "paid" {
+		return ErrTicketUnavailable
+	}
+
+	pdf, tr := newDocumentPDF("A5", fmt.Sprintf("Tickitz e-ticket #%d", doc.TransactionID))
+	pdf.SetMargins(12, 12, 12)
+	pdf.AddPage()
+	documentHeader(pdf, tr, "E-TICKET", fmt.Sprintf("Booking #%d", doc.TransactionID))
+
+	pageW, pageH := pdf.GetPageSize()
+	width := pageW - 24
+	top := 38.0
+
+	textX := 12.0
+	posterH := documentPoster(pdf, doc.Poster, 12, top, 36)
+	if posterH > 0 {
+		textX = 54
+	}
+	textW := pageW - 12 - textX
+
+	pdf.SetXY(textX, top)
+	pdf.SetFont(documentFont, "B", 15)
+	pdf.MultiCell(textW, 7, tr(doc.MovieTitle), "", "L", false)
+	pdf.Ln(2)
+	documentField(pdf, tr, textX, textW, "Date", documentDate(doc))
+	documentField(pdf, tr, textX, textW, "Time", doc.ShowTime)
+	documentField(pdf, tr, textX, textW, "Cinema", doc.Cinema)
+	documentField(pdf, tr, textX, textW, "Location", doc.Location)
+	documentField(pdf, tr, textX, textW, "Seats", strings.Join(doc.Seats, ", "))
+	documentField(pdf, tr, textX, textW, "Tickets", strconv.Itoa(len(doc.Seats)))
+
+	y := max(pdf.GetY(), top+posterH) + 4
+	pdf.SetDrawColor(190, 190, 190)
+	pdf.SetDashPattern([]float64{2, 1.5}, 0)
+	pdf.Line(12, y, pageW-12, y)
+	pdf.SetDashPattern([]float64{}, 0)
+
+	qrSize := 48.0
+	if err := documentQRCode(pdf, doc.TransactionID, (pageW-qrSize)/2, y+5, qrSize); err != nil {
+		return err
+	}
+	pdf.SetXY(12, y+5+qrSize+1)
+	pdf.SetFont(documentFont, "B", 10)
+	pdf.CellFormat(width, 5, utils.TicketCode(doc.TransactionID), "", 2, "C", false, 0, "")
+	pdf.SetFont(documentFont, "", 8)
+	pdf.SetTextColor(120, 120, 120)
+	pdf.CellFormat(width, 4, "Show this code at the cinema entrance.", "", 2, "C", false, 0, "")
+
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetXY(12, pageH-18)
+	pdf.SetFont(documentFont, "", 9)
+	pdf.SetTextColor(30, 30, 30)
+	pdf.CellFormat(width/2, 5, tr("Paid with "+doc.PaymentMethod), "T", 0, "L", false, 0, "")
+	pdf.SetFont(documentFont, "B", 9)
+	pdf.CellFormat(width/2, 5, tr("Total "+utils.FormatRupiah(doc.Total)), "T", 0, "R", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// WriteInvoicePDF renders the invoice of a transaction that was paid: every
+// seat, the discounts and the tax included in the total.
+func WriteInvoicePDF(w io.Writer, doc dto.TransactionDocument) error {
+	if doc.InvoiceNumber == "" {
+		return ErrInvoiceUnavailable
+	}
+
+	pdf, tr := newDocumentPDF("A4", "Tickitz invoice "+doc.InvoiceNumber)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+	documentHeader(pdf, tr, "INVOICE", doc.InvoiceNumber)
+
+	pageW, pageH := pdf.GetPageSize()
+	width := pageW - 30
+	top := 38.0
+
+	billedTo := strings.TrimSpace(doc.Name + "\n" + doc.Email)
+	pdf.SetXY(15, top)
+	documentField(pdf, tr, 15, 85, "Billed to", billedTo)
+	leftY := pdf.GetY()
+
+	issued := ""
+	if doc.PaidAt != nil {
+		issued = doc.PaidAt.Format("January 2, 2006")
+	}
+	pdf.SetXY(115, top)
+	documentField(pdf, tr, 115, 80, "Invoice number", doc.InvoiceNumber)
+	documentField(pdf, tr, 115, 80, "Invoice date", issued)
+	documentField(pdf, tr, 115, 80, "Booking", fmt.Sprintf("#%d", doc.TransactionID))
+	documentField(pdf, tr, 115, 80, "Payment method", doc.PaymentMethod)
+	documentField(pdf, tr, 115, 80, "Status", strings.ToUpper(doc.Status))
+
+	y := max(leftY, pdf.GetY()) + 4
+	posterH := documentPoster(pdf, doc.Poster, 15, y, 24)
+	textX := 15.0
+	if posterH > 0 {
+		textX = 45
+	}
+	pdf.SetXY(textX, y)
+	pdf.SetFont(documentFont, "B", 13)
+	pdf.MultiCell(pageW-15-textX, 6, tr(doc.MovieTitle), "", "L", false)
+	pdf.SetX(textX)
+	pdf.SetFont(documentFont, "", 10)
+	pdf.MultiCell(pageW-15-textX, 5, tr(fmt.Sprintf("%s, %s\n%s, %s",
+		documentDate(doc), doc.ShowTime, doc.Cinema, doc.Location)), "", "L", false)
+	y = max(pdf.GetY(), y+posterH) + 8
+
+	if doc.Status != "paid" {
+		pdf.SetXY(15, y)
+		pdf.SetFont(documentFont, "B", 10)
+		pdf.SetTextColor(200, 30, 30)
+		pdf.CellFormat(width, 6, tr(fmt.Sprintf("This transaction was %s.", doc.Status)), "", 2, "L", false, 0, "")
+		pdf.SetTextColor(30, 30, 30)
+		y = pdf.GetY() + 2
+	}
+
+	cols := []float64{100, 15, 32.5, 32.5}
+	pdf.SetXY(15, y)
+	pdf.SetFont(documentFont, "B", 9)
+	pdf.SetFillColor(240, 240, 245)
+	for i, title := range []string{"Description", "Qty", "Unit price", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(cols[i], 8, title, "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(documentFont, "", 9)
+	for _, seat := range doc.SeatPrices {
+		pdf.CellFormat(cols[0], 7, tr("Movie ticket, seat "+seat.Seat), "", 0, "L", false, 0, "")
+		pdf.CellFormat(cols[1], 7, "1", "", 0, "R", false, 0, "")
+		pdf.CellFormat(cols[2], 7, utils.FormatRupiah(seat.Price), "", 0, "R", false, 0, "")
+		pdf.CellFormat(cols[3], 7, utils.FormatRupiah(seat.Price), "", 1, "R", false, 0, "")
+	}
+
+	labelW := width - cols[3]
+	line := func(label, value, style string) {
+		pdf.SetFont(documentFont, style, 9)
+		pdf.CellFormat(labelW, 6, tr(label), "", 0, "R", false, 0, "")
+		pdf.CellFormat(cols[3], 6, value, "", 1, "R", false, 0, "")
+	}
+	pdf.Line(15, pdf.GetY()+1, pageW-15, pdf.GetY()+1)
+	pdf.Ln(2)
+	line("Subtotal", utils.FormatRupiah(doc.Subtotal), "")
+	if doc.Discount > 0 {
+		line("Promo "+doc.PromoCode, utils.FormatRupiah(-doc.Discount), "")
+	}
+	if doc.PointsDiscount > 0 {
+		line(fmt.Sprintf("%d loyalty points", doc.PointsRedeemed), utils.FormatRupiah(-doc.PointsDiscount), "")
+	}
+	line("Total", utils.FormatRupiah(doc.Total), "B")
+	pdf.Ln(2)
+	pdf.SetTextColor(120, 120, 120)
+	line(fmt.Sprintf("Tax base (total excluding PPN %d%%)", doc.TaxRate), utils.FormatRupiah(doc.TaxBase), "")
+	line(fmt.Sprintf("PPN %d%% included", doc.TaxRate), utils.FormatRupiah(doc.Tax), "")
+	pdf.SetTextColor(30, 30, 30)
+
+	if doc.Status == "paid" {
+		y = pdf.GetY() + 8
+		if err := documentQRCode(pdf, doc.TransactionID, 15, y, 28); err != nil {
+			return err
+		}
+		pdf.SetXY(47, y+9)
+		pdf.SetFont(documentFont, "B", 9)
+		pdf.CellFormat(100, 5, utils.TicketCode(doc.TransactionID), "", 2, "L", false, 0, "")
+		pdf.SetFont(documentFont, "", 8)
+		pdf.CellFormat(100, 4, "Ticket code, scan at the cinema entrance.", "", 2, "L", false, 0, "")
+	}
+
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetXY(15, pageH-20)
+	pdf.SetFont(documentFont, "", 8)
+	pdf.SetTextColor(120, 120, 120)
+	pdf.MultiCell(width, 4, "Thank you for booking with Tickitz. This invoice was issued electronically "+
+		"and is valid without a signature.", "T", "C", false)
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteDocumentPDFs(t *testing.T) {
+	paidAt := time.Date(2025, 7, 30, 10, 0, 0, 0, time.UTC)
+	doc := dto.TransactionDocument{
+		BookingEmail: dto.BookingEmail{
+			TransactionID: 42,
+			Name:          "Sri Rahayu",
+			Email:         "sri@example.com",
+			MovieTitle:    "Pengabdi Setan 2: Communion — Ünïcode",
+			ShowDate:      time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+			ShowTime:      "19:30",
+			Status:        "paid",
+			Location:      "Jakarta",
+			Cinema:        "CineOne21",
+			Seats:         []string{"A1", "A2"},
+			PaymentMethod: "BCA",
+			Subtotal:      100000,
+			Discount:      10000,
+			Total:         90000,
+		},
+		SeatPrices:    []dto.SeatPrice{{Seat: "A1", Price: 50000}, {Seat: "A2", Price: 50000}},
+		PaidAt:        &paidAt,
+		InvoiceNumber: "INV-2025-000001",
+		TaxRate:       11,
+		TaxBase:       81081,
+		Tax:           8919,
+	}
+
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		"ticket":  func(b *bytes.Buffer) error { return WriteTicketPDF(b, doc) },
+		"invoice": func(b *bytes.Buffer) error { return WriteInvoicePDF(b, doc) },
+	} {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+			t.Fatalf("%s is not a PDF", name)
+		}
+	}
+
+	if got := TicketFilename(doc); got != "tickitz-ticket-42.pdf" {
+		t.Errorf("TicketFilename = %q", got)
+	}
+	if got := InvoiceFilename(doc); got != "tickitz-inv-2025-000001.pdf" {
+		t.Errorf("InvoiceFilename = %q", got)
+	}
+}
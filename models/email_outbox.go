@@ -0,0 +1,252 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrEmailNotFound = errors.New("email not found")
+
+// outboxWake nudges the local workers when an email is queued, so it goes out
+// right away instead of on the next poll.
+var outboxWake = make(chan struct{}, 1)
+
+// outboxStuckAfter is how long an email may stay in "sending" before it is
+// considered abandoned by a crashed worker and picked up again.
+const outboxStuckAfter = 10 * time.Minute
+
+// QueueEmail stores a rendered email in the outbox for the mail workers to
+// deliver. db may be a transaction, so the email is only sent if it commits;
+// the caller then wakes the workers with wakeMailWorkers, as they would not
+// see the email before the commit.
+func QueueEmail(db querier, to string, msg utils.EmailMessage) error {
+	attachments := msg.Attachments
+	if attachments == nil {
+		attachments = []utils.EmailAttachment{}
+	}
+	_, err := db.Exec(context.Background(), `
+    INSERT INTO email_outbox (recipient, subject, html_body, text_body, attachments, max_attempts)
+    VALUES ($1, $2, $3, $4, $5, $6)
+  `, to, msg.Subject, msg.HTML, msg.Text, attachments, mailMaxAttempts())
+	if err != nil {
+		return fmt.Errorf("failed to queue email: %v", err)
+	}
+
+	if _, inTx := db.(pgx.Tx); !inTx {
+		wakeMailWorkers()
+	}
+	return nil
+}
+
+// wakeMailWorkers nudges the local workers to look for queued emails.
+func wakeMailWorkers() {
+	select {
+	case outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// QueueTemplateEmail renders a template and queues the result.
+func QueueTemplateEmail(db querier, to, name, lang string, data any) error {
+	msg, err := utils.RenderEmail(name, lang, data)
+	if err != nil {
+		return err
+	}
+	return QueueEmail(db, to, msg)
+}
+
+func mailMaxAttempts() int {
+	return max(utils.GetEnvInt("MAIL_MAX_ATTEMPTS", 5), 1)
+}
+
+// mailRetryDelay is the wait after the given failed attempt, starting at
+// MAIL_RETRY_BASE_SECONDS and at most an hour.
+func mailRetryDelay(attempt int) time.Duration {
+	base := time.Duration(utils.GetEnvInt("MAIL_RETRY_BASE_SECONDS", 30)) * time.Second
+	return retryDelay(base, attempt, time.Hour)
+}
+
+// StartMailWorkers runs MAIL_WORKERS background workers delivering the
+// outbox. They poll every MAIL_POLL_SECONDS and are woken early by QueueEmail.
+func StartMailWorkers() {
+	poll := time.Duration(max(utils.GetEnvInt("MAIL_POLL_SECONDS", 5), 1)) * time.Second
+	startWorkers("Mail worker", utils.GetEnvInt("MAIL_WORKERS", 2), poll, outboxWake, DeliverNextEmail)
+
+	go func() {
+		for {
+			if err := purgeSentEmails(); err != nil {
+				log.Println("Mail outbox cleanup:", err.Error())
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+// DeliverNextEmail claims one due email and tries to send it. It reports
+// whether an email was claimed, so workers keep going while the queue is not
+// empty. A failed email is retried with backoff until it runs out of
+// attempts and becomes dead.
+func DeliverNextEmail() (bool, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+
+	var id, attempts, maxAttempts int
+	var msg utils.EmailMessage
+	var to string
+	err = conn.QueryRow(context.Background(), `
+    UPDATE email_outbox SET status = 'sending', attempts = attempts + 1, updated_at = NOW()
+    WHERE id = (
+      SELECT id FROM email_outbox
+      WHERE (status = 'queued' AND next_attempt_at <= NOW())
+        OR (status = 'sending' AND updated_at < NOW() - make_interval(secs => $1))
+      ORDER BY next_attempt_at, id
+      LIMIT 1
+      FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, recipient, subject, html_body, text_body, attachments, attempts, max_attempts
+  `, outboxStuckAfter.Seconds()).Scan(&id, &to, &msg.Subject, &msg.HTML, &msg.Text, &msg.Attachments, &attempts, &maxAttempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim email: %v", err)
+	}
+
+	sendErr := utils.SendEmailMessage(to, msg)
+	if sendErr == nil {
+		_, err = conn.Exec(context.Background(), `
+      UPDATE email_outbox SET status = 'sent', sent_at = NOW(), last_error = NULL, updated_at = NOW()
+      WHERE id = $1
+    `, id)
+		return true, err
+	}
+
+	status := "queued"
+	if attempts >= maxAttempts {
+		status = "dead"
+		log.Printf("Email %d to %s is dead after %d attempts: %v", id, to, attempts, sendErr)
+	}
+	_, err = conn.Exec(context.Background(), `
+    UPDATE email_outbox SET
+      status = $2,
+      next_attempt_at = NOW() + make_interval(secs => $3),
+      last_error = $4,
+      updated_at = NOW()
+    WHERE id = $1
+  `, id, status, mailRetryDelay(attempts).Seconds(), sendErr.Error())
+	return true, err
+}
+
+// purgeSentEmails drops delivered emails older than MAIL_OUTBOX_RETENTION_DAYS.
+// Dead emails are kept until an admin retries them.
+func purgeSentEmails() error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(context.Background(), `
+    DELETE FROM email_outbox
+    WHERE status = 'sent' AND sent_at < NOW() - make_interval(days => $1)
+  `, utils.GetEnvInt("MAIL_OUTBOX_RETENTION_DAYS", 30))
+	return err
+}
+
+var outboxListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":        {Column: "id", Cast: "int"},
+		"createdAt": {Column: "created_at", Cast: "timestamp"},
+	},
+	DefaultSort:   "createdAt",
+	DefaultDesc:   true,
+	SearchColumns: []string{"recipient", "subject"},
+	Filters:       map[string]filterColumn{"status": {Column: "status", Kind: filterCode}},
+}
+
+// GetOutboxEmails lists outbox emails. Without a status filter it shows the
+// dead letters.
+func GetOutboxEmails(q utils.PageQuery) ([]dto.OutboxEmail, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	if q.Filters.Get("status") == "" {
+		filters := maps.Clone(q.Filters)
+		if filters == nil {
+			filters = url.Values{}
+		}
+		filters.Set("status", "dead")
+		q.Filters = filters
+	}
+
+	rows, total, err := queryPage(conn, `
+    SELECT id, recipient, subject, status, attempts, max_attempts, next_attempt_at,
+      last_error, sent_at, created_at
+    FROM email_outbox
+  `, nil, q, outboxListSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	emails := []dto.OutboxEmail{}
+	for rows.Next() {
+		var e dto.OutboxEmail
+		if err := rows.Scan(&e.ID, &e.Recipient, &e.Subject, &e.Status, &e.Attempts, &e.MaxAttempts,
+			&e.NextAttemptAt, &e.LastError, &e.SentAt, &e.CreatedAt); err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		emails = append(emails, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := outboxListSpec.sortKey(q)
+	emails, nextCursor := trimPage(emails, q, func(e dto.OutboxEmail) (string, int) {
+		if key == "createdAt" {
+			return e.CreatedAt.Format("2006-01-02 15:04:05.999999"), e.ID
+		}
+		return strconv.Itoa(e.ID), e.ID
+	})
+	return emails, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
+
+// RetryEmail puts a dead email back in the queue with a fresh set of attempts.
+func RetryEmail(admin dto.AuditActor, id int) error {
+	_, err := withAudit(admin, "retry", "email", id, func(tx pgx.Tx) (int, error) {
+		tag, err := tx.Exec(context.Background(), `
+      UPDATE email_outbox SET status = 'queued', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+      WHERE id = $1 AND status = 'dead'
+    `, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to retry email: %v", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, ErrEmailNotFound
+		}
+		return id, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	wakeMailWorkers()
+	return nil
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// Outbox tests run only with DB_TESTS=1. They queue mail to testOutboxDomain
+// and delete it again, and skip when other mail is due, since
+// DeliverNextEmail would hand that to the capture mailer.
+const testOutboxDomain = "@outbox.tickitz.test"
+
+type testOutboxRow struct {
+	Status    string
+	Attempts  int
+	LastError *string
+	DueIn     time.Duration
+}
+
+func requireOutbox(t *testing.T) *utils.CaptureMailer {
+	t.Helper()
+	if os.Getenv("DB_TESTS") != "1" {
+		t.Skip("set DB_TESTS=1 to run against the database configured in .env")
+	}
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		t.Skip("database unavailable:", err)
+	}
+	defer conn.Release()
+
+	ctx := context.Background()
+	var pending int
+	err = conn.QueryRow(ctx, `
+    SELECT COUNT(*) FROM email_outbox
+    WHERE status IN ('queued', 'sending') AND recipient NOT LIKE '%' || $1
+  `, testOutboxDomain).Scan(&pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending > 0 {
+		t.Skipf("%d emails are waiting in the outbox", pending)
+	}
+
+	cleanup := func() {
+		conn, err := utils.ConnectDB()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Release()
+		if _, err := conn.Exec(ctx, `DELETE FROM email_outbox WHERE recipient LIKE '%' || $1`, testOutboxDomain); err != nil {
+			t.Error(err)
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	mailer := &utils.CaptureMailer{}
+	utils.SetMailer(mailer)
+	t.Cleanup(func() { utils.SetMailer(nil) })
+	return mailer
+}
+
+func queueTestEmail(t *testing.T, to string) {
+	t.Helper()
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Release()
+	msg := utils.EmailMessage{Subject: "Your tickets", HTML: "<p>Enjoy the movie</p>", Text: "Enjoy the movie"}
+	if err := QueueEmail(conn, to, msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func outboxRow(t *testing.T, to string) testOutboxRow {
+	t.Helper()
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Release()
+	var row testOutboxRow
+	var dueIn float64
+	err = conn.QueryRow(context.Background(), `
+    SELECT status, attempts, last_error, EXTRACT(EPOCH FROM next_attempt_at - NOW())
+    FROM email_outbox WHERE recipient = $1
+  `, to).Scan(&row.Status, &row.Attempts, &row.LastError, &dueIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row.DueIn = time.Duration(dueIn * float64(time.Second))
+	return row
+}
+
+func deliverOne(t *testing.T, wantClaimed bool) {
+	t.Helper()
+	claimed, err := DeliverNextEmail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed != wantClaimed {
+		t.Fatalf("DeliverNextEmail claimed = %v, want %v", claimed, wantClaimed)
+	}
+}
+
+func TestDeliverNextEmail(t *testing.T) {
+	mailer := requireOutbox(t)
+	to := "sent" + testOutboxDomain
+	queueTestEmail(t, to)
+
+	deliverOne(t, true)
+	sent := mailer.Sent()
+	if len(sent) != 1 || sent[0].To != to || sent[0].Message.Subject != "Your tickets" || sent[0].Message.Text != "Enjoy the movie" {
+		t.Fatalf("sent = %+v, want the queued email", sent)
+	}
+	if row := outboxRow(t, to); row.Status != "sent" || row.Attempts != 1 || row.LastError != nil {
+		t.Errorf("row = %+v, want sent after one attempt", row)
+	}
+
+	deliverOne(t, false)
+	if len(mailer.Sent()) != 1 {
+		t.Error("a sent email should not be delivered again")
+	}
+}
+
+func TestDeliverNextEmailRetriesThenDies(t *testing.T) {
+	mailer := requireOutbox(t)
+	mailer.Err = errors.New("smtp unavailable")
+	t.Setenv("MAIL_MAX_ATTEMPTS", "2")
+	t.Setenv("MAIL_RETRY_BASE_SECONDS", "60")
+	to := "retry" + testOutboxDomain
+	queueTestEmail(t, to)
+
+	deliverOne(t, true)
+	row := outboxRow(t, to)
+	if row.Status != "queued" || row.Attempts != 1 || row.LastError == nil || *row.LastError != "smtp unavailable" {
+		t.Fatalf("row = %+v, want queued for a retry with the error", row)
+	}
+	if row.DueIn < 50*time.Second || row.DueIn > 70*time.Second {
+		t.Errorf("retry due in %v, want about a minute", row.DueIn)
+	}
+	deliverOne(t, false)
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.Exec(context.Background(), `UPDATE email_outbox SET next_attempt_at = NOW() WHERE recipient = $1`, to)
+	conn.Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deliverOne(t, true)
+	if row := outboxRow(t, to); row.Status != "dead" || row.Attempts != 2 {
+		t.Errorf("row = %+v, want dead after the last attempt", row)
+	}
+	deliverOne(t, false)
+	if len(mailer.Sent()) != 0 {
+		t.Error("a failing mailer should not have captured anything")
+	}
+}
@@ -4,83 +4,116 @@ import (
 	"be-tickitz/dto"
 	"be-tickitz/utils"
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type Genre struct {
 	ID        int    `json:"id"`
-	GenreName string `json:"genreName" db:"genre_name"`
+	GenreName string     `json:"genreName" db:"genre_name"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 }
 
-func CreateGenre(input dto.Genre) (Genre, error) {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return Genre{}, err
-	}
-	defer conn.Release()
-
+func CreateGenre(admin dto.AuditActor, input dto.Genre) (Genre, error) {
 	var genre Genre
-	err = conn.QueryRow(context.Background(),`
-	INSERT INTO genres (genre_name)
-	VALUES ($1)
-	RETURNING id, genre_name
-	`,
-	input.GenreName,
-).Scan(
-	&genre.ID,
-	&genre.GenreName,
-)
+	_, err := withAudit(admin, "create", "genre", 0, func(tx pgx.Tx) (int, error) {
+		err := tx.QueryRow(context.Background(), `
+		INSERT INTO genres (genre_name)
+		VALUES ($1)
+		RETURNING id, genre_name, deleted_at
+		`,
+			input.GenreName,
+		).Scan(
+			&genre.ID,
+			&genre.GenreName,
+			&genre.DeletedAt,
+		)
+		return genre.ID, err
+	})
+	if err == nil {
+		invalidateCache(CacheTagGenres)
+		RefreshSuggestIndexAsync()
+	}
 
 	return genre, err
 
 }
 
-func AddGenretoMovie(movieID, genreID int) error { //add genre ke movie secara manual melalui hit API
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return err
+func AddGenretoMovie(admin dto.AuditActor, movieID, genreID int) error { //add genre ke movie secara manual melalui hit API
+	_, err := withAudit(admin, "add_genre", "movie", movieID, func(tx pgx.Tx) (int, error) {
+		_, err := tx.Exec(context.Background(), `
+		INSERT INTO movie_genres (id_movie, id_genre)
+		VALUES ($1, $2)
+		`,
+			movieID, genreID,
+		)
+		return movieID, err
+	})
+	if err == nil {
+		invalidateCache(CacheTagMovies, MovieCacheTag(movieID))
 	}
-	defer conn.Release()
-
-	_, err = conn.Exec(context.Background(), `
-	INSERT INTO movie_genres (id_movie, id_genre)
-	VALUES ($1, $2)
-	`,
-	movieID, genreID,
-	)
 return err
 }
 
-func GetAllGenres() ([]Genre, error){
+var genreListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":   {Column: "id", Cast: "int"},
+		"name": {Column: "genre_name", Cast: "text"},
+	},
+	DefaultSort:   "name",
+	SearchColumns: []string{"genre_name"},
+}
+
+// GetAllGenres lists genres; public listings use ScopeLive.
+func GetAllGenres(q utils.PageQuery, scope CatalogScope) ([]Genre, utils.PageResult, error){
 		conn, err := utils.ConnectDB()
 	if err != nil {
-		return []Genre{}, err
+		return []Genre{}, utils.PageResult{}, err
 	}
 	defer conn.Release()
 
 	
-	rows, err := conn.Query(context.Background(), `
-		SELECT id, genre_name
+	rows, total, err := queryPage(conn, `
+		SELECT id, genre_name, deleted_at
 		FROM genres
-		ORDER BY genre_name ASC
-	`)
+		WHERE `+scope.sql("deleted_at")+`
+	`, nil, q, genreListSpec)
 	if err != nil {
-		return nil, err
+		return nil, utils.PageResult{}, err
 	}
 
 	genre,err := pgx.CollectRows(rows, pgx.RowToStructByName[Genre])
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
 
-	return genre, err
+	key, _ := genreListSpec.sortKey(q)
+	genre, nextCursor := trimPage(genre, q, func(g Genre) (string, int) {
+		if key == "id" {
+			return strconv.Itoa(g.ID), g.ID
+		}
+		return g.GenreName, g.ID
+	})
+
+	return genre, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
-func DeleteGenre(id string) error {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return err
+func DeleteGenre(admin dto.AuditActor, id int) error {
+	err := softDelete(admin, "genre", id, ErrGenreNotFound)
+	if err == nil {
+		invalidateCache(CacheTagGenres, CacheTagMovies)
+		RefreshSuggestIndexAsync()
 	}
-	defer conn.Release()
+	return err
+}
 
-	_, err = conn.Exec(context.Background(), `DELETE FROM genres WHERE id = $1`, id)
+func RestoreGenre(admin dto.AuditActor, id int) error {
+	err := restoreDeleted(admin, "genre", id, ErrGenreNotFound)
+	if err == nil {
+		invalidateCache(CacheTagGenres, CacheTagMovies)
+		RefreshSuggestIndexAsync()
+	}
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPointsInvalid is wrapped with the reason points cannot be redeemed.
+var ErrPointsInvalid = errors.New("points cannot be redeemed")
+
+type loyaltyTier struct {
+	Name       string
+	MinSpend   int
+	Multiplier float64
+}
+
+// PointsEarnPerAmount is the amount paid that earns one point before the
+// tier multiplier, configured with POINTS_EARN_PER_AMOUNT.
+func PointsEarnPerAmount() int {
+	if v := utils.GetEnvInt("POINTS_EARN_PER_AMOUNT", 1000); v > 0 {
+		return v
+	}
+	return 1000
+}
+
+// PointValue is how much one point takes off the price when redeemed,
+// configured with POINTS_REDEEM_VALUE.
+func PointValue() int {
+	if v := utils.GetEnvInt("POINTS_REDEEM_VALUE", 10); v > 0 {
+		return v
+	}
+	return 10
+}
+
+// loyaltyTiers lists the tiers from lowest to highest. The spend thresholds
+// are configured with LOYALTY_SILVER_SPEND and LOYALTY_GOLD_SPEND.
+func loyaltyTiers() []loyaltyTier {
+	return []loyaltyTier{
+		{Name: "Bronze", MinSpend: 0, Multiplier: 1},
+		{Name: "Silver", MinSpend: utils.GetEnvInt("LOYALTY_SILVER_SPEND", 500000), Multiplier: 1.25},
+		{Name: "Gold", MinSpend: utils.GetEnvInt("LOYALTY_GOLD_SPEND", 1500000), Multiplier: 1.5},
+	}
+}
+
+// tierFor returns the tier reached with spend and the next one, if any.
+func tierFor(spend int) (loyaltyTier, *loyaltyTier) {
+	tiers := loyaltyTiers()
+	current := tiers[0]
+	for i, tier := range tiers {
+		if spend < tier.MinSpend {
+			return current, &tiers[i]
+		}
+		current = tier
+	}
+	return current, nil
+}
+
+func yearlySpend(db querier, userID int) (int, error) {
+	var spend int
+	err := db.QueryRow(context.Background(), `
+    SELECT COALESCE(SUM(total_price), 0)
+    FROM transactions
+    WHERE id_user = $1 AND status = 'paid'
+      AND paid_at >= NOW() - INTERVAL '1 year'
+  `, userID).Scan(&spend)
+	return spend, err
+}
+
+func pointsBalance(db querier, userID int) (int, error) {
+	var balance int
+	err := db.QueryRow(context.Background(), `
+    SELECT COALESCE(SUM(points), 0) FROM points_ledger WHERE id_user = $1
+  `, userID).Scan(&balance)
+	return balance, err
+}
+
+// redeemablePoints locks the user's ledger for the rest of tx and returns how
+// many of the requested points can be used on amountDue, and the discount
+// they give. Points are never worth more than the amount due.
+func redeemablePoints(tx pgx.Tx, userID, requested, amountDue int) (int, int, error) {
+	_, err := tx.Exec(context.Background(), `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	balance, err := pointsBalance(tx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if requested > balance {
+		return 0, 0, fmt.Errorf("%w: balance is %d points", ErrPointsInvalid, balance)
+	}
+
+	value := PointValue()
+	points := min(requested, amountDue/value)
+	return points, points * value, nil
+}
+
+// creditTransactionPoints adds the points earned by a paid transaction. It is
+// idempotent per transaction.
+func creditTransactionPoints(tx pgx.Tx, transactionID int) error {
+	var userID, paid int
+	err := tx.QueryRow(context.Background(), `
+    SELECT id_user, total_price FROM transactions WHERE id = $1
+  `, transactionID).Scan(&userID, &paid)
+	if err != nil {
+		return err
+	}
+
+	spend, err := yearlySpend(tx, userID)
+	if err != nil {
+		return err
+	}
+	tier, _ := tierFor(spend)
+	points := int(math.Floor(float64(paid/PointsEarnPerAmount()) * tier.Multiplier))
+	if points <= 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(context.Background(), `
+    INSERT INTO points_ledger (id_user, id_transaction, entry_type, points, description)
+    VALUES ($1, $2, 'earn', $3, $4)
+    ON CONFLICT DO NOTHING
+  `, userID, transactionID, points, fmt.Sprintf("Earned on booking #%d (%s)", transactionID, tier.Name))
+	return err
+}
+
+// reverseTransactionPoints takes back the points earned by a transaction and
+// returns the points redeemed on it. It is idempotent per transaction.
+func reverseTransactionPoints(tx pgx.Tx, transactionID int) error {
+	_, err := tx.Exec(context.Background(), `
+    INSERT INTO points_ledger (id_user, id_transaction, entry_type, points, description)
+    SELECT id_user, id_transaction, 'reversal', -points, 'Reversed: booking #' || id_transaction || ' was cancelled'
+    FROM points_ledger
+    WHERE id_transaction = $1 AND entry_type = 'earn'
+    ON CONFLICT DO NOTHING
+  `, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to reverse earned points: %v", err)
+	}
+
+	_, err = tx.Exec(context.Background(), `
+    INSERT INTO points_ledger (id_user, id_transaction, entry_type, points, description)
+    SELECT id_user, id_transaction, 'refund', -points, 'Returned: booking #' || id_transaction || ' was cancelled'
+    FROM points_ledger
+    WHERE id_transaction = $1 AND entry_type = 'redeem'
+    ON CONFLICT DO NOTHING
+  `, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to return redeemed points: %v", err)
+	}
+	return nil
+}
+
+func GetPointsSummary(userID int) (dto.PointsSummary, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.PointsSummary{}, err
+	}
+	defer conn.Release()
+
+	balance, err := pointsBalance(conn, userID)
+	if err != nil {
+		return dto.PointsSummary{}, err
+	}
+	spend, err := yearlySpend(conn, userID)
+	if err != nil {
+		return dto.PointsSummary{}, err
+	}
+
+	tier, next := tierFor(spend)
+	summary := dto.PointsSummary{
+		Balance:       balance,
+		Tier:          tier.Name,
+		Multiplier:    tier.Multiplier,
+		YearlySpend:   spend,
+		EarnPerAmount: PointsEarnPerAmount(),
+		PointValue:    PointValue(),
+	}
+	if next != nil {
+		summary.NextTier = next.Name
+		summary.SpendToNextTier = next.MinSpend - spend
+	}
+	return summary, nil
+}
+
+var pointsHistorySpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id": {Column: "id", Cast: "int"},
+	},
+	DefaultSort: "id",
+	DefaultDesc: true,
+	Filters:     map[string]filterColumn{"type": {Column: "entry_type", Kind: filterCode}},
+}
+
+func GetPointsHistory(userID int, q utils.PageQuery) ([]dto.PointsEntry, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, `
+    SELECT id, id_transaction, entry_type, points, description, created_at
+    FROM points_ledger
+    WHERE id_user = $1
+  `, []any{userID}, q, pointsHistorySpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	entries := []dto.PointsEntry{}
+	for rows.Next() {
+		var e dto.PointsEntry
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.Type, &e.Points, &e.Description, &e.CreatedAt); err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	entries, nextCursor := trimPage(entries, q, func(e dto.PointsEntry) (string, int) {
+		return strconv.Itoa(e.ID), e.ID
+	})
+	return entries, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
@@ -4,7 +4,10 @@ import (
 	"be-tickitz/dto"
 	"be-tickitz/utils"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -19,9 +22,11 @@ type Movie struct {
 	Image           string    `json:"image"`
 	HorizontalImage string    `json:"horizontalImage" db:"horizontal_image"`
 	GenreIDs        []int     `json:"genre_ids"`
+	Rating          *dto.RatingSummary `json:"rating,omitempty" db:"-"`
+	DeletedAt       *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 }
 
-func CreateMovie(input dto.Movie) (Movie, error) {
+func CreateMovie(admin dto.AuditActor, input dto.Movie) (Movie, error) {
 	conn, err := utils.ConnectDB()
 	if err != nil {
 		return Movie{}, err
@@ -74,99 +79,179 @@ func CreateMovie(input dto.Movie) (Movie, error) {
 		}
 	}
 
-	for _, directorID := range input.DirectorIDs {
-		_, err := tx.Exec(context.Background(), `
-      INSERT INTO movie_directors (id_movie, id_director)
-      VALUES ($1, $2)
-    `, movie.ID, directorID)
-		if err != nil {
-			return Movie{}, fmt.Errorf("failed to insert director: %v", err)
-		}
+	if err := insertMovieCrew(tx, movie.ID, mergeCrew(input.DirectorIDs, input.Crew)); err != nil {
+		return Movie{}, fmt.Errorf("failed to insert director: %v", err)
 	}
 
-	for _, actorID := range input.CastIDs {
-		_, err := tx.Exec(context.Background(), `
-      INSERT INTO movie_casts (id_movie, id_actor)
-      VALUES ($1, $2)
-    `, movie.ID, actorID)
-		if err != nil {
-			return Movie{}, fmt.Errorf("failed to insert cast: %v", err)
-		}
+	if err := insertMovieCasts(tx, movie.ID, mergeCasts(input.CastIDs, input.Casts)); err != nil {
+		return Movie{}, fmt.Errorf("failed to insert cast: %v", err)
+	}
+
+	if err := queueMovieEvent(tx, movie.ID, "movie.created"); err != nil {
+		return Movie{}, err
+	}
+
+	if err := recordAudit(tx, admin, "create", "movie", movie.ID, nil); err != nil {
+		return Movie{}, err
 	}
 
 	if err := tx.Commit(context.Background()); err != nil {
 		return Movie{}, fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
-
-	utils.DeleteKeysByPrefix(context.Background(), "/movies")
+	invalidateCache(CacheTagMovies)
+	RefreshSuggestIndexAsync()
+	wakeWebhookWorkers()
 
 	return movie, nil
 }
 
-func GetAllMovies() ([]Movie, error) {
-	conn, err := utils.ConnectDB()
+// queueMovieEvent sends the movie with its genre IDs to the webhooks
+// subscribed to event.
+func queueMovieEvent(db querier, id int, event string) error {
+	var movie Movie
+	err := db.QueryRow(context.Background(), `
+    SELECT id, title, description, release_date, duration_minutes, image, horizontal_image,
+      COALESCE((SELECT ARRAY_AGG(id_genre ORDER BY id_genre) FROM movie_genres WHERE id_movie = movies.id), '{}')
+    FROM movies WHERE id = $1
+  `, id).Scan(
+		&movie.ID,
+		&movie.Title,
+		&movie.Description,
+		&movie.ReleaseDate,
+		&movie.Duration,
+		&movie.Image,
+		&movie.HorizontalImage,
+		&movie.GenreIDs,
+	)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to load movie for %s: %v", event, err)
 	}
-	defer conn.Release()
+	return QueueWebhookEvent(db, event, movie)
+}
 
-	rows, err := conn.Query(context.Background(), `
-    SELECT id, title, description, release_date, duration_minutes, image, horizontal_image
-    FROM movies
-    ORDER BY id ASC
-  `)
-	if err != nil {
-		return nil, err
+var movieListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":          {Column: "id", Cast: "int"},
+		"title":       {Column: "title", Cast: "text"},
+		"releaseDate": {Column: "release_date", Cast: "date", Nullable: true},
+		"duration":    {Column: "duration_minutes", Cast: "int", Nullable: true},
+		"relevance":   {Column: "relevance", Cast: "real"},
+	},
+	DefaultSort: "id",
+}
+
+// movieListSpecFor returns the movie list spec with the given default sort.
+// Searches default to relevance order.
+func movieListSpecFor(q utils.PageQuery, defaultSort string, defaultDesc bool) listSpec {
+	spec := movieListSpec
+	spec.Sorts = maps.Clone(movieListSpec.Sorts)
+	spec.DefaultSort, spec.DefaultDesc = defaultSort, defaultDesc
+	if q.Search != "" {
+		spec.DefaultSort, spec.DefaultDesc = "relevance", true
+	}
+	return spec
+}
+
+func movieSortValue(m Movie, relevance float32, key string) string {
+	switch key {
+	case "title":
+		return m.Title
+	case "releaseDate":
+		if m.ReleaseDate.IsZero() {
+			return ""
+		}
+		return m.ReleaseDate.Format("2006-01-02")
+	case "duration":
+		return strconv.Itoa(m.Duration)
+	case "relevance":
+		return strconv.FormatFloat(float64(relevance), 'g', -1, 32)
+	default:
+		return strconv.Itoa(m.ID)
 	}
+}
+
+// movieListSelect is the column list shared by movie list queries; relevance
+// is the search rank bound to $1, or 0 when not searching.
+func movieListSelect(searching bool) string {
+	relevance := `0::real`
+	if searching {
+		relevance = movieRankSQL(1) + `::real`
+	}
+	return `
+    SELECT m.id, m.title, m.description, m.release_date, m.duration_minutes, m.image, m.horizontal_image,
+           m.deleted_at, ` + relevance + ` AS relevance
+    FROM movies m`
+}
+
+// collectMoviePage scans rows produced from movieListSelect and trims them to
+// the requested page.
+func collectMoviePage(rows pgx.Rows, q utils.PageQuery, spec listSpec) ([]Movie, string, error) {
 	defer rows.Close()
 
-	var movies []Movie
+	type rankedMovie struct {
+		movie     Movie
+		relevance float32
+	}
+
+	var ranked []rankedMovie
 	for rows.Next() {
-		var m Movie
-		err := rows.Scan(&m.ID, &m.Title, &m.Description, &m.ReleaseDate, &m.Duration, &m.Image, &m.HorizontalImage)
+		var r rankedMovie
+		m := &r.movie
+		err := rows.Scan(&m.ID, &m.Title, &m.Description, &m.ReleaseDate, &m.Duration, &m.Image, &m.HorizontalImage, &m.DeletedAt, &r.relevance)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		movies = append(movies, m)
+		ranked = append(ranked, r)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	key, _ := spec.sortKey(q)
+	ranked, nextCursor := trimPage(ranked, q, func(r rankedMovie) (string, int) {
+		return movieSortValue(r.movie, r.relevance, key), r.movie.ID
+	})
 
-	return movies, nil
+	movies := make([]Movie, 0, len(ranked))
+	for _, r := range ranked {
+		movies = append(movies, r.movie)
+	}
+	return movies, nextCursor, nil
 }
 
-func SearchMovies(search string) ([]Movie, error) {
+// GetAllMovies lists movies, the public listing uses ScopeLive. When q.Search
+// is set the list is narrowed with full-text and fuzzy matching and ordered by
+// relevance by default.
+func GetAllMovies(q utils.PageQuery, scope CatalogScope) ([]Movie, utils.PageResult, error) {
 	conn, err := utils.ConnectDB()
 	if err != nil {
-		return nil, err
+		return nil, utils.PageResult{}, err
 	}
 	defer conn.Release()
 
-	query := `
-    SELECT id, title, description, release_date, duration_minutes, image, horizontal_image
-    FROM movies
-    WHERE title ILIKE '%' || $1 || '%'
-    ORDER BY id ASC
-  `
-	rows, err := conn.Query(context.Background(), query, search)
+	query := movieListSelect(q.Search != "") + ` WHERE ` + scope.sql("m.deleted_at")
+	params := []any{}
+	if q.Search != "" {
+		params = append(params, q.Search)
+		query += ` AND ` + movieMatchSQL(1)
+	}
+
+	spec := movieListSpecFor(q, "id", false)
+	rows, total, err := queryPage(conn, query, params, q, spec)
 	if err != nil {
-		return nil, err
+		return nil, utils.PageResult{}, err
 	}
-	defer rows.Close()
 
-	var movies []Movie
-	for rows.Next() {
-		var m Movie
-		err := rows.Scan(&m.ID, &m.Title, &m.Description, &m.ReleaseDate, &m.Duration, &m.Image, &m.HorizontalImage)
-		if err != nil {
-			return nil, err
-		}
-		movies = append(movies, m)
+	movies, nextCursor, err := collectMoviePage(rows, q, spec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
 	}
 
-	return movies, nil
+	return movies, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
-func GetMovieByID(id string) (dto.MovieDetail, error) {
+func GetMovieByID(id int) (dto.MovieDetail, error) {
 	conn, err := utils.ConnectDB()
 	if err != nil {
 		return dto.MovieDetail{}, err
@@ -175,9 +260,10 @@ func GetMovieByID(id string) (dto.MovieDetail, error) {
 
 	var movie dto.MovieDetail
 	err = conn.QueryRow(context.Background(), `
-    SELECT id, title, description, release_date, duration_minutes, image, horizontal_image
+    SELECT id, title, description, release_date, duration_minutes, image, horizontal_image,
+      COALESCE(updated_at, created_at, NOW())
     FROM movies
-    WHERE id = $1
+    WHERE id = $1 AND deleted_at IS NULL
   `, id).Scan(
 		&movie.ID,
 		&movie.Title,
@@ -186,7 +272,11 @@ func GetMovieByID(id string) (dto.MovieDetail, error) {
 		&movie.Duration,
 		&movie.Image,
 		&movie.HorizontalImage,
+		&movie.UpdatedAt,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.MovieDetail{}, ErrMovieNotFound
+	}
 	if err != nil {
 		return dto.MovieDetail{}, err
 	}
@@ -195,7 +285,7 @@ func GetMovieByID(id string) (dto.MovieDetail, error) {
     SELECT g.genre_name
     FROM genres g
     JOIN movie_genres mg ON g.id = mg.id_genre
-    WHERE mg.id_movie = $1
+    WHERE mg.id_movie = $1 AND g.deleted_at IS NULL
   `, id)
 	if err == nil {
 		for rows.Next() {
@@ -204,123 +294,98 @@ func GetMovieByID(id string) (dto.MovieDetail, error) {
 				movie.Genres = append(movie.Genres, name)
 			}
 		}
+		rows.Close()
 	}
 
 	rows, err = conn.Query(context.Background(), `
-    SELECT d.director_name
+    SELECT d.id, d.director_name, md.job, md.credit_order
     FROM directors d
     JOIN movie_directors md ON d.id = md.id_director
-    WHERE md.id_movie = $1
+    WHERE md.id_movie = $1 AND d.deleted_at IS NULL
+    ORDER BY md.credit_order ASC, md.id ASC
   `, id)
 	if err == nil {
 		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err == nil {
-				movie.Directors = append(movie.Directors, name)
+			var credit dto.CrewCredit
+			if err := rows.Scan(&credit.DirectorID, &credit.Name, &credit.Job, &credit.Order); err == nil {
+				movie.Directors = append(movie.Directors, credit)
 			}
 		}
+		rows.Close()
 	}
 
 	rows, err = conn.Query(context.Background(), `
-    SELECT a.actor_name
+    SELECT a.id, a.actor_name, COALESCE(mc.role_name, ''), mc.billing_order
     FROM actors a
     JOIN movie_casts mc ON a.id = mc.id_actor
-    WHERE mc.id_movie = $1
+    WHERE mc.id_movie = $1 AND a.deleted_at IS NULL
+    ORDER BY mc.billing_order ASC, mc.id ASC
   `, id)
 	if err == nil {
 		for rows.Next() {
-			var name string
-			if err := rows.Scan(&name); err == nil {
-				movie.Casts = append(movie.Casts, name)
+			var credit dto.CastCredit
+			if err := rows.Scan(&credit.ActorID, &credit.Name, &credit.RoleName, &credit.Order); err == nil {
+				movie.Casts = append(movie.Casts, credit)
 			}
 		}
+		rows.Close()
+	}
+
+	ratings, err := ratingSummaries(conn, []int{movie.ID})
+	if err == nil {
+		movie.Rating = ratings[movie.ID]
 	}
 
 	return movie, nil
 }
 
-func GetNowShowing(search string, genres []int, sort string, page int, limit int) ([]Movie, int, error) {
+func GetNowShowing(genres []int, filter dto.ShowtimeFilter, q utils.PageQuery) ([]Movie, utils.PageResult, error) {
 	conn, err := utils.ConnectDB()
 	if err != nil {
-		return nil, 0, err
+		return nil, utils.PageResult{}, err
 	}
 	defer conn.Release()
 
-	// Query utama untuk movies
-	query := `
-    SELECT id, title, description, release_date, duration_minutes, image, horizontal_image
-    FROM movies
-    WHERE release_date <= NOW()
-  `
-	countQuery := `
-    SELECT COUNT(id)
-    FROM movies
-    WHERE release_date <= NOW()
-  `
-	params := []interface{}{}
-
-	if search != "" {
-		query += ` AND title ILIKE '%' || $1 || '%'`
-		countQuery += ` AND title ILIKE '%' || $1 || '%'`
-		params = append(params, search)
+	params := []any{}
+	query := movieListSelect(q.Search != "")
+	if q.Search != "" {
+		params = append(params, q.Search)
 	}
 
-	if len(genres) > 0 {
-		query += fmt.Sprintf(` AND id IN (
-      SELECT id_movie FROM movie_genres WHERE id_genre = ANY($%d)
-    )`, len(params)+1)
-		countQuery += fmt.Sprintf(` AND id IN (
-      SELECT id_movie FROM movie_genres WHERE id_genre = ANY($%d)
-    )`, len(params)+1)
-		params = append(params, genres)
-	}
+	// Movie dianggap "now showing" jika punya jadwal tayang di dalam run window
+	where, params := nowShowingSQL(filter, params)
+	query += ` WHERE m.deleted_at IS NULL AND ` + where
 
-	if sort == "latest" {
-		query += ` ORDER BY release_date DESC`
-	} else if sort == "name-asc" {
-		query += ` ORDER BY title ASC`
-	} else if sort == "name-desc" {
-		query += ` ORDER BY title DESC`
-	} else {
-		query += ` ORDER BY id ASC`
+	if q.Search != "" {
+		query += ` AND ` + movieMatchSQL(1)
 	}
 
-	if page > 0 && limit > 0 {
-		query += fmt.Sprintf(` OFFSET $%d LIMIT $%d`, len(params)+1, len(params)+2)
-		params = append(params, (page-1)*limit, limit)
+	if len(genres) > 0 {
+		params = append(params, genres)
+		query += fmt.Sprintf(` AND m.id IN (
+      SELECT id_movie FROM movie_genres WHERE id_genre = ANY($%d)
+    )`, len(params))
 	}
 
-	// Hitung total rows
-	var totalRows int
-	err = conn.QueryRow(context.Background(), countQuery, params[:len(params)-2]...).Scan(&totalRows)
+	spec := movieListSpecFor(q, "releaseDate", true)
+	rows, total, err := queryPage(conn, query, params, q, spec)
 	if err != nil {
-		return nil, 0, err
+		return nil, utils.PageResult{}, err
 	}
 
-	// Ambil movies
-	rows, err := conn.Query(context.Background(), query, params...)
+	movies, nextCursor, err := collectMoviePage(rows, q, spec)
 	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	var movies []Movie
-	for rows.Next() {
-		var m Movie
-		err := rows.Scan(&m.ID, &m.Title, &m.Description, &m.ReleaseDate, &m.Duration, &m.Image, &m.HorizontalImage)
-		if err != nil {
-			return nil, 0, err
-		}
-		movies = append(movies, m)
+		return nil, utils.PageResult{}, err
 	}
 
 	// Ambil genre_ids untuk setiap movie
 	for i, m := range movies {
 		var genreIDs []int
 		rows, err := conn.Query(context.Background(), `
-      SELECT id_genre
-      FROM movie_genres
-      WHERE id_movie = $1
+      SELECT mg.id_genre
+      FROM movie_genres mg
+      JOIN genres g ON g.id = mg.id_genre AND g.deleted_at IS NULL
+      WHERE mg.id_movie = $1
     `, m.ID)
 		if err == nil {
 			for rows.Next() {
@@ -334,48 +399,85 @@ func GetNowShowing(search string, genres []int, sort string, page int, limit int
 		movies[i].GenreIDs = genreIDs
 	}
 
-	return movies, totalRows, nil
+	ids := make([]int, len(movies))
+	for i, m := range movies {
+		ids[i] = m.ID
+	}
+	ratings, err := ratingSummaries(conn, ids)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	for i, m := range movies {
+		rating := ratings[m.ID]
+		movies[i].Rating = &rating
+	}
+
+	return movies, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
-func GetUpcoming() ([]Movie, error) {
+func GetUpcoming(filter dto.ShowtimeFilter, q utils.PageQuery) ([]Movie, utils.PageResult, error) {
 	conn, err := utils.ConnectDB()
 	if err != nil {
-		return nil, err
+		return nil, utils.PageResult{}, err
 	}
 	defer conn.Release()
 
-	rows, err := conn.Query(context.Background(), `
+	where, params := upcomingSQL(filter, []any{})
+
+	spec := movieListSpecFor(q, "releaseDate", false)
+	spec.SearchColumns = []string{"title"}
+	delete(spec.Sorts, "relevance")
+	if q.Search != "" {
+		spec.DefaultSort, spec.DefaultDesc = "releaseDate", false
+	}
+
+	rows, total, err := queryPage(conn, `
     SELECT m.id, m.title, m.description, m.release_date, m.duration_minutes, m.image, m.horizontal_image,
-           COALESCE(ARRAY_AGG(mg.id_genre) FILTER (WHERE mg.id_genre IS NOT NULL), '{}') as genre_ids
+           m.deleted_at,
+           COALESCE(ARRAY_AGG(g.id) FILTER (WHERE g.id IS NOT NULL), '{}') as genre_ids
     FROM movies m
     LEFT JOIN movie_genres mg ON m.id = mg.id_movie
-    WHERE m.release_date > NOW()
+    LEFT JOIN genres g ON g.id = mg.id_genre AND g.deleted_at IS NULL
+    WHERE m.deleted_at IS NULL AND `+where+`
     GROUP BY m.id
-  `)
+  `, params, q, spec)
 	if err != nil {
-		return nil, err
+		return nil, utils.PageResult{}, err
 	}
 	defer rows.Close()
 
 	movies, err := pgx.CollectRows(rows, pgx.RowToStructByName[Movie])
-	return movies, err
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := spec.sortKey(q)
+	movies, nextCursor := trimPage(movies, q, func(m Movie) (string, int) {
+		return movieSortValue(m, 0, key), m.ID
+	})
+
+	return movies, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
-func DeleteMovie(id string) error {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return err
+func DeleteMovie(admin dto.AuditActor, id int) error {
+	err := softDelete(admin, "movie", id, ErrMovieNotFound)
+	if err == nil {
+		invalidateCache(CacheTagMovies, MovieCacheTag(id))
+		RefreshSuggestIndexAsync()
 	}
-	defer conn.Release()
+	return err
+}
 
-	_, err = conn.Exec(context.Background(), `DELETE FROM movies WHERE id = $1`, id)
+func RestoreMovie(admin dto.AuditActor, id int) error {
+	err := restoreDeleted(admin, "movie", id, ErrMovieNotFound)
 	if err == nil {
-		utils.DeleteKeysByPrefix(context.Background(), "/movies")
+		invalidateCache(CacheTagMovies, MovieCacheTag(id))
+		RefreshSuggestIndexAsync()
 	}
 	return err
 }
 
-func UpdateMovie(id int, input dto.UpdateMovieInput) error {
+func UpdateMovie(admin dto.AuditActor, id int, input dto.UpdateMovieInput) error {
 	conn, err := utils.ConnectDB()
 	if err != nil {
 		return err
@@ -405,6 +507,11 @@ func UpdateMovie(id int, input dto.UpdateMovieInput) error {
 		return fmt.Errorf("movie not found: %v", err)
 	}
 
+	before, err := auditSnapshot(tx, "movie", id)
+	if err != nil {
+		return err
+	}
+
 	title := old.Title
 	if input.Title != nil {
 		title = *input.Title
@@ -470,41 +577,55 @@ func UpdateMovie(id int, input dto.UpdateMovieInput) error {
 		}
 	}
 
-	if input.DirectorIDs != nil {
+	if input.DirectorIDs != nil || input.Crew != nil {
 		_, err := tx.Exec(context.Background(), `DELETE FROM movie_directors WHERE id_movie = $1`, id)
 		if err != nil {
 			return err
 		}
-		for _, directorID := range *input.DirectorIDs {
-			_, err := tx.Exec(context.Background(), `
-        INSERT INTO movie_directors (id_movie, id_director)
-        VALUES ($1, $2)
-      `, id, directorID)
-			if err != nil {
-				return err
-			}
+		var directorIDs []int
+		var crew []dto.CrewInput
+		if input.DirectorIDs != nil {
+			directorIDs = *input.DirectorIDs
+		}
+		if input.Crew != nil {
+			crew = *input.Crew
+		}
+		if err := insertMovieCrew(tx, id, mergeCrew(directorIDs, crew)); err != nil {
+			return err
 		}
 	}
 
-	if input.CastIDs != nil {
+	if input.CastIDs != nil || input.Casts != nil {
 		_, err := tx.Exec(context.Background(), `DELETE FROM movie_casts WHERE id_movie = $1`, id)
 		if err != nil {
 			return err
 		}
-		for _, actorID := range *input.CastIDs {
-			_, err := tx.Exec(context.Background(), `
-        INSERT INTO movie_casts (id_movie, id_actor)
-        VALUES ($1, $2)
-      `, id, actorID)
-			if err != nil {
-				return err
-			}
+		var castIDs []int
+		var casts []dto.CastInput
+		if input.CastIDs != nil {
+			castIDs = *input.CastIDs
+		}
+		if input.Casts != nil {
+			casts = *input.Casts
 		}
+		if err := insertMovieCasts(tx, id, mergeCasts(castIDs, casts)); err != nil {
+			return err
+		}
+	}
+
+	if err := queueMovieEvent(tx, id, "movie.updated"); err != nil {
+		return err
+	}
+
+	if err := recordAudit(tx, admin, "update", "movie", id, before); err != nil {
+		return err
 	}
 
 	err = tx.Commit(context.Background())
 	if err == nil {
-		utils.DeleteKeysByPrefix(context.Background(), "/movies")
+		invalidateCache(CacheTagMovies, MovieCacheTag(id))
+		RefreshSuggestIndexAsync()
+		wakeWebhookWorkers()
 	}
 	return err
 }
@@ -0,0 +1,667 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrImportInvalid = errors.New("invalid import file")
+
+// MovieFileFormats lists the formats movies are imported and exported in.
+var MovieFileFormats = []string{"json", "csv"}
+
+// maxMovieImportRows caps the movies of one import, which runs in a single
+// transaction.
+const maxMovieImportRows = 2000
+
+// movieCSVColumns are the columns of a CSV movie file, named after the JSON
+// fields. Genres, directors and cast are separated by "|" and a credit is
+// written "Name:Job" for directors and "Name:Role" for cast.
+var movieCSVColumns = []string{
+	"externalId", "title", "description", "releaseDate", "durationMinutes",
+	"image", "horizontalImage", "genres", "directors", "cast",
+}
+
+// importRecord is a movie read from an import file with the problems found in
+// it.
+type importRecord struct {
+	dto.MovieRecord
+	errors []string
+}
+
+func (r *importRecord) fail(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// ImportMovies creates or updates the movies of a JSON or CSV file, matching
+// existing movies by external ID. Every row is checked first and nothing is
+// written when any of them is invalid; the result then lists the errors of
+// each row. A dry run goes through the whole import and rolls it back.
+func ImportMovies(admin dto.AuditActor, r io.Reader, format string, dryRun bool) (dto.MovieImportResult, error) {
+	records, err := readMovieFile(r, format)
+	if err != nil {
+		return dto.MovieImportResult{}, err
+	}
+
+	result := dto.MovieImportResult{DryRun: dryRun, Total: len(records), Rows: make([]dto.MovieImportRow, len(records))}
+	firstRow := map[string]int{}
+	for i := range records {
+		rec := &records[i]
+		validateMovieRecord(rec)
+		if row, ok := firstRow[rec.ExternalID]; ok && rec.ExternalID != "" {
+			rec.fail("externalId is already used on row %d", row)
+		} else {
+			firstRow[rec.ExternalID] = i + 1
+		}
+
+		result.Rows[i] = dto.MovieImportRow{Row: i + 1, ExternalID: rec.ExternalID, Title: rec.Title, Errors: rec.errors}
+		if len(rec.errors) > 0 {
+			result.Failed++
+		}
+	}
+	if result.Failed > 0 {
+		return result, nil
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return result, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(context.Background())
+
+	if err := requireNewMovieFields(tx, records); err != nil {
+		return result, err
+	}
+	for i, rec := range records {
+		if len(rec.errors) > 0 {
+			result.Rows[i].Errors = rec.errors
+			result.Failed++
+		}
+	}
+	if result.Failed > 0 {
+		return result, nil
+	}
+
+	names := &importNames{admin: admin, ids: map[string]int{}, created: map[string]int{}}
+	var changed []string
+	for i, rec := range records {
+		action, id, err := importMovie(tx, admin, names, rec.MovieRecord)
+		if err != nil {
+			return result, fmt.Errorf("row %d: %v", i+1, err)
+		}
+		result.Rows[i].Action = action
+		switch action {
+		case "create":
+			result.Created++
+		case "update":
+			result.Updated++
+			changed = append(changed, MovieCacheTag(id))
+		default:
+			result.Unchanged++
+		}
+	}
+	result.GenresCreated = names.created["genre"]
+	result.DirectorsCreated = names.created["director"]
+	result.ActorsCreated = names.created["actor"]
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(context.Background()); err != nil {
+		return result, fmt.Errorf("failed to commit import: %v", err)
+	}
+
+	if result.Created+result.Updated > 0 {
+		invalidateCache(append(changed, CacheTagMovies, CacheTagGenres, CacheTagPeople)...)
+		RefreshSuggestIndexAsync()
+		wakeWebhookWorkers()
+	}
+	return result, nil
+}
+
+// requireNewMovieFields fails the records of movies not stored yet that leave
+// out the release date or duration.
+func requireNewMovieFields(tx pgx.Tx, records []importRecord) error {
+	externalIDs := make([]string, len(records))
+	for i, rec := range records {
+		externalIDs[i] = rec.ExternalID
+	}
+	rows, err := tx.Query(context.Background(), `SELECT external_id FROM movies WHERE external_id = ANY($1)`, externalIDs)
+	if err != nil {
+		return fmt.Errorf("failed to look up movies: %v", err)
+	}
+	defer rows.Close()
+
+	stored := map[string]bool{}
+	for rows.Next() {
+		var externalID string
+		if err := rows.Scan(&externalID); err != nil {
+			return err
+		}
+		stored[externalID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to look up movies: %v", err)
+	}
+
+	for i := range records {
+		rec := &records[i]
+		if stored[rec.ExternalID] {
+			continue
+		}
+		if rec.ReleaseDate == "" {
+			rec.fail("releaseDate is required for a new movie")
+		}
+		if rec.Duration == 0 {
+			rec.fail("durationMinutes is required for a new movie")
+		}
+	}
+	return nil
+}
+
+// importMovie writes one movie, replacing the genres and credits of an
+// existing one, and tells whether it was created, updated or left unchanged.
+// An existing movie that already matches the record is not written to, and
+// keeps its release date and duration when the record leaves them empty.
+func importMovie(tx pgx.Tx, admin dto.AuditActor, names *importNames, rec dto.MovieRecord) (string, int, error) {
+	ctx := context.Background()
+	var releaseDate *time.Time
+	if rec.ReleaseDate != "" {
+		date, _ := time.Parse("2006-01-02", rec.ReleaseDate)
+		releaseDate = &date
+	}
+	var duration *int
+	if rec.Duration > 0 {
+		duration = &rec.Duration
+	}
+
+	genreIDs := make([]int, len(rec.Genres))
+	for i, name := range rec.Genres {
+		id, err := names.id(tx, "genre", name)
+		if err != nil {
+			return "", 0, err
+		}
+		genreIDs[i] = id
+	}
+	crew := make([]dto.CrewInput, len(rec.Directors))
+	for i, d := range rec.Directors {
+		id, err := names.id(tx, "director", d.Name)
+		if err != nil {
+			return "", 0, err
+		}
+		crew[i] = dto.CrewInput{DirectorID: id, Job: cmp.Or(d.Job, "Director")}
+	}
+	casts := make([]dto.CastInput, len(rec.Cast))
+	for i, a := range rec.Cast {
+		id, err := names.id(tx, "actor", a.Name)
+		if err != nil {
+			return "", 0, err
+		}
+		casts[i] = dto.CastInput{ActorID: id, RoleName: a.Role}
+	}
+
+	var id int
+	var before map[string]any
+	err := tx.QueryRow(ctx, `SELECT id FROM movies WHERE external_id = $1 FOR UPDATE`, rec.ExternalID).Scan(&id)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		err = tx.QueryRow(ctx, `
+      INSERT INTO movies (external_id, title, description, release_date, duration_minutes, image, horizontal_image)
+      VALUES ($1, $2, $3, $4, $5, $6, $7)
+      RETURNING id
+    `, rec.ExternalID, rec.Title, rec.Description, releaseDate, duration, rec.Image, rec.HorizontalImage).Scan(&id)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to insert movie: %v", err)
+		}
+	case err != nil:
+		return "", 0, fmt.Errorf("failed to look up movie: %v", err)
+	default:
+		differs, err := movieDiffers(tx, id, rec, releaseDate, duration, genreIDs, crew, casts)
+		if err != nil {
+			return "", 0, err
+		}
+		if !differs {
+			return "unchanged", id, nil
+		}
+
+		if before, err = auditSnapshot(tx, "movie", id); err != nil {
+			return "", 0, err
+		}
+		_, err = tx.Exec(ctx, `
+      UPDATE movies SET title = $2, description = $3, release_date = COALESCE($4, release_date),
+        duration_minutes = COALESCE($5, duration_minutes), image = $6, horizontal_image = $7, updated_at = NOW()
+      WHERE id = $1
+    `, id, rec.Title, rec.Description, releaseDate, duration, rec.Image, rec.HorizontalImage)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to update movie: %v", err)
+		}
+		for _, table := range []string{"movie_genres", "movie_directors", "movie_casts"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE id_movie = $1`, id); err != nil {
+				return "", 0, fmt.Errorf("failed to clear %s: %v", table, err)
+			}
+		}
+	}
+
+	for _, genreID := range genreIDs {
+		if _, err := tx.Exec(ctx, `INSERT INTO movie_genres (id_movie, id_genre) VALUES ($1, $2)`, id, genreID); err != nil {
+			return "", 0, fmt.Errorf("failed to insert genre: %v", err)
+		}
+	}
+	if err := insertMovieCrew(tx, id, crew); err != nil {
+		return "", 0, fmt.Errorf("failed to insert director: %v", err)
+	}
+	if err := insertMovieCasts(tx, id, casts); err != nil {
+		return "", 0, fmt.Errorf("failed to insert cast: %v", err)
+	}
+
+	action, event := "create", "movie.created"
+	if before != nil {
+		action, event = "update", "movie.updated"
+	}
+	if err := queueMovieEvent(tx, id, event); err != nil {
+		return "", 0, err
+	}
+	if err := recordAudit(tx, admin, "import", "movie", id, before); err != nil {
+		return "", 0, err
+	}
+	return action, id, nil
+}
+
+// movieDiffers reports whether importing a record would change a stored
+// movie. Genres are compared as a set and credits in billing order, as export
+// files do not carry the order numbers. A missing description or image is the
+// same as an empty one.
+func movieDiffers(tx pgx.Tx, id int, rec dto.MovieRecord, releaseDate *time.Time, duration *int,
+	genreIDs []int, crew []dto.CrewInput, casts []dto.CastInput) (bool, error) {
+	genres := slices.Sorted(slices.Values(genreIDs))
+	directors := make([]string, len(crew))
+	for i, c := range crew {
+		directors[i] = fmt.Sprintf("%d:%s", c.DirectorID, c.Job)
+	}
+	actors := make([]string, len(casts))
+	for i, c := range casts {
+		actors[i] = fmt.Sprintf("%d:%s", c.ActorID, c.RoleName)
+	}
+
+	var differs bool
+	err := tx.QueryRow(context.Background(), `
+    SELECT title <> $2 OR COALESCE(description, '') <> $3
+      OR ($4::date IS NOT NULL AND release_date IS DISTINCT FROM $4)
+      OR ($5::int IS NOT NULL AND duration_minutes IS DISTINCT FROM $5)
+      OR COALESCE(image, '') <> $6 OR COALESCE(horizontal_image, '') <> $7
+      OR ARRAY(SELECT id_genre FROM movie_genres WHERE id_movie = $1 ORDER BY id_genre) <> $8::int[]
+      OR ARRAY(SELECT id_director || ':' || job FROM movie_directors
+        WHERE id_movie = $1 ORDER BY credit_order, id) <> $9::text[]
+      OR ARRAY(SELECT id_actor || ':' || COALESCE(role_name, '') FROM movie_casts
+        WHERE id_movie = $1 ORDER BY billing_order, id) <> $10::text[]
+    FROM movies WHERE id = $1
+  `, id, rec.Title, rec.Description, releaseDate, duration, rec.Image, rec.HorizontalImage,
+		genres, directors, actors).Scan(&differs)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare movie: %v", err)
+	}
+	return differs, nil
+}
+
+// importNames finds genres, directors and actors by name for an import,
+// creating the missing ones like cmd/seed_tmdb does, and remembers them for
+// the rest of the file. A name that matches a deleted entry links to it
+// without restoring it.
+type importNames struct {
+	admin   dto.AuditActor
+	ids     map[string]int
+	created map[string]int
+}
+
+var importNameColumns = map[string]string{
+	"genre":    "genre_name",
+	"director": "director_name",
+	"actor":    "actor_name",
+}
+
+func (n *importNames) id(tx pgx.Tx, entityType, name string) (int, error) {
+	key := entityType + ":" + name
+	if id, ok := n.ids[key]; ok {
+		return id, nil
+	}
+
+	table, column := auditEntities[entityType].Table, importNameColumns[entityType]
+	var id int
+	err := tx.QueryRow(context.Background(), `
+    INSERT INTO `+table+` (`+column+`) VALUES ($1)
+    ON CONFLICT (`+column+`) DO NOTHING
+    RETURNING id
+  `, name).Scan(&id)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		err = tx.QueryRow(context.Background(), `SELECT id FROM `+table+` WHERE `+column+` = $1`, name).Scan(&id)
+	case err == nil:
+		n.created[entityType]++
+		err = recordAudit(tx, n.admin, "create", entityType, id, nil)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find or create %s %q: %v", entityType, name, err)
+	}
+
+	n.ids[key] = id
+	return id, nil
+}
+
+// validateMovieRecord trims the fields of a record and notes what is wrong
+// with them. The release date and duration may be left empty, as exports do
+// for movies without them, but a new movie needs both; see
+// requireNewMovieFields.
+func validateMovieRecord(rec *importRecord) {
+	m := &rec.MovieRecord
+	m.ExternalID = strings.TrimSpace(m.ExternalID)
+	m.Title = strings.TrimSpace(m.Title)
+	m.Description = strings.TrimSpace(m.Description)
+	m.ReleaseDate = strings.TrimSpace(m.ReleaseDate)
+	m.Image = strings.TrimSpace(m.Image)
+	m.HorizontalImage = strings.TrimSpace(m.HorizontalImage)
+
+	switch {
+	case m.ExternalID == "":
+		rec.fail("externalId is required")
+	case len(m.ExternalID) > 100:
+		rec.fail("externalId must be at most 100 characters")
+	}
+	switch {
+	case m.Title == "":
+		rec.fail("title is required")
+	case len(m.Title) > 255:
+		rec.fail("title must be at most 255 characters")
+	}
+	if _, err := time.Parse("2006-01-02", m.ReleaseDate); m.ReleaseDate != "" && err != nil {
+		rec.fail("releaseDate must be a date like 2025-07-30")
+	}
+	if m.Duration < 0 {
+		rec.fail("durationMinutes must be a positive number")
+	}
+	if len(m.Image) > 255 || len(m.HorizontalImage) > 255 {
+		rec.fail("image URLs must be at most 255 characters")
+	}
+
+	genres := []string{}
+	for _, name := range m.Genres {
+		name = strings.TrimSpace(name)
+		if checkImportName(rec, "genre", name, false) && !slices.Contains(genres, name) {
+			genres = append(genres, name)
+		}
+	}
+	m.Genres = genres
+
+	for i := range m.Directors {
+		d := &m.Directors[i]
+		d.Name, d.Job, d.Role = strings.TrimSpace(d.Name), strings.TrimSpace(d.Job), ""
+		checkImportName(rec, "director", d.Name, true)
+		if len(d.Job) > 100 {
+			rec.fail("job of director %q must be at most 100 characters", d.Name)
+		}
+	}
+	for i := range m.Cast {
+		a := &m.Cast[i]
+		a.Name, a.Role, a.Job = strings.TrimSpace(a.Name), strings.TrimSpace(a.Role), ""
+		checkImportName(rec, "actor", a.Name, true)
+		if len(a.Role) > 255 {
+			rec.fail("role of actor %q must be at most 255 characters", a.Name)
+		}
+	}
+}
+
+// checkImportName checks a genre or person name. Names may not contain the
+// separators of the CSV format, so every import can be exported again.
+func checkImportName(rec *importRecord, kind, name string, credit bool) bool {
+	switch {
+	case name == "":
+		rec.fail("%s names must not be empty", kind)
+	case len(name) > 255:
+		rec.fail("%s %q must be at most 255 characters", kind, name)
+	case strings.Contains(name, "|") || (credit && strings.Contains(name, ":")):
+		rec.fail("%s %q must not contain | or :", kind, name)
+	default:
+		return true
+	}
+	return false
+}
+
+// readMovieFile reads the movies of an import file.
+func readMovieFile(r io.Reader, format string) ([]importRecord, error) {
+	var records []importRecord
+	switch format {
+	case "json":
+		var movies []dto.MovieRecord
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&movies); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrImportInvalid, err)
+		}
+		for _, m := range movies {
+			records = append(records, importRecord{MovieRecord: m})
+		}
+	case "csv":
+		var err error
+		if records, err = readMovieCSV(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrImportInvalid, format)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: the file has no movies", ErrImportInvalid)
+	}
+	if len(records) > maxMovieImportRows {
+		return nil, fmt.Errorf("%w: at most %d movies can be imported at once", ErrImportInvalid, maxMovieImportRows)
+	}
+	return records, nil
+}
+
+func readMovieCSV(r io.Reader) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportInvalid, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !slices.Contains(movieCSVColumns, name) {
+			return nil, fmt.Errorf("%w: unknown column %q, expected %s", ErrImportInvalid, name, strings.Join(movieCSVColumns, ", "))
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"externalId", "title"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrImportInvalid, name)
+		}
+	}
+
+	var records []importRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrImportInvalid, err)
+		}
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return csvValue(row[i])
+			}
+			return ""
+		}
+
+		var rec importRecord
+		rec.ExternalID = cell("externalId")
+		rec.Title = cell("title")
+		rec.Description = cell("description")
+		rec.ReleaseDate = cell("releaseDate")
+		rec.Image = cell("image")
+		rec.HorizontalImage = cell("horizontalImage")
+		if duration := strings.TrimSpace(cell("durationMinutes")); duration != "" {
+			if rec.Duration, err = strconv.Atoi(duration); err != nil {
+				rec.fail("durationMinutes must be a whole number")
+			}
+		}
+		rec.Genres = splitCSVList(cell("genres"))
+		for _, item := range splitCSVList(cell("directors")) {
+			name, job, _ := strings.Cut(item, ":")
+			rec.Directors = append(rec.Directors, dto.CreditRecord{Name: name, Job: job})
+		}
+		for _, item := range splitCSVList(cell("cast")) {
+			name, role, _ := strings.Cut(item, ":")
+			rec.Cast = append(rec.Cast, dto.CreditRecord{Name: name, Role: role})
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// csvValue undoes the quote exports put in front of text that looks like a
+// spreadsheet formula.
+func csvValue(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+func splitCSVList(cell string) []string {
+	items := []string{}
+	for _, item := range strings.Split(cell, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// movieCSVRow lays a movie out in movieCSVColumns order. A movie without a
+// duration gets an empty cell, like one without a release date.
+func movieCSVRow(m dto.MovieRecord) []any {
+	var duration any
+	if m.Duration > 0 {
+		duration = m.Duration
+	}
+	return []any{m.ExternalID, m.Title, m.Description, m.ReleaseDate, duration, m.Image,
+		m.HorizontalImage, strings.Join(m.Genres, "|"), joinCredits(m.Directors), joinCredits(m.Cast)}
+}
+
+func joinCredits(credits []dto.CreditRecord) string {
+	items := make([]string, len(credits))
+	for i, c := range credits {
+		items[i] = c.Name
+		if detail := c.Job + c.Role; detail != "" {
+			items[i] += ":" + detail
+		}
+	}
+	return strings.Join(items, "|")
+}
+
+var movieExportSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":    {Column: "id", Cast: "int"},
+		"title": {Column: "title", Cast: "text"},
+	},
+	DefaultSort:   "id",
+	SearchColumns: []string{"title", "external_id"},
+}
+
+// ExportMovies writes the movies in scope to w in the format ImportMovies
+// reads, so an export can be edited and imported again.
+func ExportMovies(w io.Writer, format string, scope CatalogScope, q utils.PageQuery) error {
+	var write func(m dto.MovieRecord) error
+	var finish func() error
+	switch format {
+	case "csv":
+		cw, err := utils.NewExportWriter(w, "csv")
+		if err != nil {
+			return err
+		}
+		header := make([]any, len(movieCSVColumns))
+		for i, name := range movieCSVColumns {
+			header[i] = name
+		}
+		if err := cw.WriteRow(header...); err != nil {
+			return err
+		}
+		write = func(m dto.MovieRecord) error {
+			return cw.WriteRow(movieCSVRow(m)...)
+		}
+		finish = cw.Close
+	case "json":
+		enc := json.NewEncoder(w)
+		sep := "["
+		write = func(m dto.MovieRecord) error {
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+			sep = ","
+			return enc.Encode(m)
+		}
+		finish = func() error {
+			if sep == "[" {
+				_, err := io.WriteString(w, "[]\n")
+				return err
+			}
+			_, err := io.WriteString(w, "]\n")
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	err := streamList(`
+    SELECT m.id, m.external_id, m.title, COALESCE(m.description, '') AS description, m.release_date,
+      COALESCE(m.duration_minutes, 0) AS duration_minutes, COALESCE(m.image, '') AS image,
+      COALESCE(m.horizontal_image, '') AS horizontal_image,
+      ARRAY(SELECT g.genre_name FROM movie_genres mg JOIN genres g ON g.id = mg.id_genre
+        WHERE mg.id_movie = m.id ORDER BY mg.id) AS genres,
+      COALESCE((SELECT jsonb_agg(jsonb_build_object('name', d.director_name, 'job', NULLIF(md.job, 'Director'))
+        ORDER BY md.credit_order, md.id)
+        FROM movie_directors md JOIN directors d ON d.id = md.id_director WHERE md.id_movie = m.id), '[]') AS directors,
+      COALESCE((SELECT jsonb_agg(jsonb_build_object('name', a.actor_name, 'role', mc.role_name)
+        ORDER BY mc.billing_order, mc.id)
+        FROM movie_casts mc JOIN actors a ON a.id = mc.id_actor WHERE mc.id_movie = m.id), '[]') AS cast_credits
+    FROM movies m
+    WHERE `+scope.sql("m.deleted_at")+`
+  `, nil, q, movieExportSpec, func(rows pgx.Rows) error {
+		var id int
+		var m dto.MovieRecord
+		var releaseDate *time.Time
+		if err := rows.Scan(&id, &m.ExternalID, &m.Title, &m.Description, &releaseDate, &m.Duration,
+			&m.Image, &m.HorizontalImage, &m.Genres, &m.Directors, &m.Cast); err != nil {
+			return err
+		}
+		if releaseDate != nil {
+			m.ReleaseDate = releaseDate.Format("2006-01-02")
+		}
+		return write(m)
+	})
+	if err != nil {
+		return err
+	}
+	return finish()
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadMovieFileJSON(t *testing.T) {
+	records, err := readMovieFile(strings.NewReader(`[
+		{"externalId": "m-1", "title": "Dune", "releaseDate": "2021-10-22", "durationMinutes": 155,
+		 "genres": ["Sci-Fi"], "directors": [{"name": "Denis Villeneuve"}], "cast": [{"name": "Zendaya", "role": "Chani"}]}
+	]`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := dto.MovieRecord{
+		ExternalID: "m-1", Title: "Dune", ReleaseDate: "2021-10-22", Duration: 155,
+		Genres:    []string{"Sci-Fi"},
+		Directors: []dto.CreditRecord{{Name: "Denis Villeneuve"}},
+		Cast:      []dto.CreditRecord{{Name: "Zendaya", Role: "Chani"}},
+	}
+	if len(records) != 1 || !reflect.DeepEqual(records[0].MovieRecord, want) {
+		t.Fatalf("records = %+v, want %+v", records, want)
+	}
+
+	for name, body := range map[string]string{
+		"unknown field": `[{"externalId": "m-1", "rating": 5}]`,
+		"not an array":  `{"externalId": "m-1"}`,
+		"empty":         `[]`,
+	} {
+		if _, err := readMovieFile(strings.NewReader(body), "json"); !errors.Is(err, ErrImportInvalid) {
+			t.Errorf("%s: err = %v, want ErrImportInvalid", name, err)
+		}
+	}
+	if _, err := readMovieFile(strings.NewReader(`[]`), "xml"); !errors.Is(err, ErrImportInvalid) {
+		t.Errorf("xml: err = %v, want ErrImportInvalid", err)
+	}
+}
+
+func TestReadMovieFileCSV(t *testing.T) {
+	file := "\ufeffexternalId,title,durationMinutes,genres,directors,cast\n" +
+		"m-1,'=Dune,155,Sci-Fi| Drama ||,Denis Villeneuve:Director,Zendaya:Chani|Timothée Chalamet\n" +
+		"m-2,Blank,,,,\n" +
+		"m-3,Bad,long,,,\n"
+	records, err := readMovieFile(strings.NewReader(file), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	first := records[0]
+	if first.Title != "=Dune" || first.Duration != 155 {
+		t.Errorf("title, duration = %q, %d, want =Dune, 155", first.Title, first.Duration)
+	}
+	if !slices.Equal(first.Genres, []string{"Sci-Fi", "Drama"}) {
+		t.Errorf("genres = %q", first.Genres)
+	}
+	if want := []dto.CreditRecord{{Name: "Denis Villeneuve", Job: "Director"}}; !reflect.DeepEqual(first.Directors, want) {
+		t.Errorf("directors = %+v, want %+v", first.Directors, want)
+	}
+	if want := []dto.CreditRecord{{Name: "Zendaya", Role: "Chani"}, {Name: "Timothée Chalamet"}}; !reflect.DeepEqual(first.Cast, want) {
+		t.Errorf("cast = %+v, want %+v", first.Cast, want)
+	}
+	if blank := records[1]; blank.Duration != 0 || len(blank.Genres) != 0 || len(blank.errors) != 0 {
+		t.Errorf("blank row = %+v, want empty fields and no errors", blank)
+	}
+	if len(records[2].errors) != 1 {
+		t.Errorf("row with a text duration has errors %q, want one", records[2].errors)
+	}
+
+	for name, file := range map[string]string{
+		"unknown column": "externalId,title,rating\nm-1,Dune,5\n",
+		"missing title":  "externalId,description\nm-1,Sand\n",
+		"no rows":        "externalId,title\n",
+	} {
+		if _, err := readMovieFile(strings.NewReader(file), "csv"); !errors.Is(err, ErrImportInvalid) {
+			t.Errorf("%s: err = %v, want ErrImportInvalid", name, err)
+		}
+	}
+}
+
+func TestCSVValue(t *testing.T) {
+	tests := map[string]string{
+		"'=SUM(A1)": "=SUM(A1)",
+		"'-5":       "-5",
+		"'@home":    "@home",
+		"'quoted'":  "'quoted'",
+		"'":         "'",
+		"plain":     "plain",
+	}
+	for cell, want := range tests {
+		if got := csvValue(cell); got != want {
+			t.Errorf("csvValue(%q) = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestSplitCSVList(t *testing.T) {
+	if got := splitCSVList(" Action | |Drama|"); !slices.Equal(got, []string{"Action", "Drama"}) {
+		t.Errorf("splitCSVList = %q", got)
+	}
+	if got := splitCSVList(""); got == nil || len(got) != 0 {
+		t.Errorf("splitCSVList(\"\") = %#v, want an empty list", got)
+	}
+}
+
+func TestValidateMovieRecord(t *testing.T) {
+	valid := func() importRecord {
+		return importRecord{MovieRecord: dto.MovieRecord{
+			ExternalID: " m-1 ", Title: " Dune ", ReleaseDate: "2021-10-22", Duration: 155,
+			Genres:    []string{"Sci-Fi", " Sci-Fi", "Drama"},
+			Directors: []dto.CreditRecord{{Name: " Denis Villeneuve ", Role: "ignored"}},
+			Cast:      []dto.CreditRecord{{Name: "Zendaya", Role: " Chani ", Job: "ignored"}},
+		}}
+	}
+
+	rec := valid()
+	validateMovieRecord(&rec)
+	if len(rec.errors) != 0 {
+		t.Fatalf("errors = %q, want none", rec.errors)
+	}
+	if rec.ExternalID != "m-1" || rec.Title != "Dune" || !slices.Equal(rec.Genres, []string{"Sci-Fi", "Drama"}) {
+		t.Errorf("record not trimmed and deduplicated: %+v", rec.MovieRecord)
+	}
+	if rec.Directors[0] != (dto.CreditRecord{Name: "Denis Villeneuve"}) || rec.Cast[0] != (dto.CreditRecord{Name: "Zendaya", Role: "Chani"}) {
+		t.Errorf("credits = %+v %+v", rec.Directors, rec.Cast)
+	}
+
+	// Exports leave these empty for movies without them.
+	rec = valid()
+	rec.ReleaseDate, rec.Duration = "", 0
+	validateMovieRecord(&rec)
+	if len(rec.errors) != 0 {
+		t.Errorf("empty release date and duration: errors = %q, want none", rec.errors)
+	}
+
+	tests := map[string]func(*importRecord){
+		"no external ID":     func(r *importRecord) { r.ExternalID = " " },
+		"no title":           func(r *importRecord) { r.Title = "" },
+		"bad release date":   func(r *importRecord) { r.ReleaseDate = "22/10/2021" },
+		"negative duration":  func(r *importRecord) { r.Duration = -1 },
+		"long image URL":     func(r *importRecord) { r.Image = strings.Repeat("x", 256) },
+		"separator in genre": func(r *importRecord) { r.Genres = []string{"Sci|Fi"} },
+		"colon in actor":     func(r *importRecord) { r.Cast[0].Name = "Zendaya: Chani" },
+		"empty director":     func(r *importRecord) { r.Directors[0].Name = "" },
+	}
+	for name, change := range tests {
+		rec := valid()
+		change(&rec)
+		validateMovieRecord(&rec)
+		if len(rec.errors) != 1 {
+			t.Errorf("%s: errors = %q, want one", name, rec.errors)
+		}
+	}
+}
+
+func TestMovieCSVRoundTrip(t *testing.T) {
+	movies := []dto.MovieRecord{
+		{
+			ExternalID: "m-1", Title: "=Dune", Description: "Spice, \"sand\"\nand worms", ReleaseDate: "2021-10-22",
+			Duration: 155, Image: "https://img/dune.jpg", Genres: []string{"Sci-Fi", "Drama"},
+			Directors: []dto.CreditRecord{{Name: "Denis Villeneuve"}, {Name: "Someone", Job: "Co-Director"}},
+			Cast:      []dto.CreditRecord{{Name: "Zendaya", Role: "Chani"}, {Name: "Extra"}},
+		},
+		// A movie stored without a release date or duration.
+		{ExternalID: "m-2", Title: "Untitled", Genres: []string{}},
+	}
+
+	var buf bytes.Buffer
+	w, err := utils.NewExportWriter(&buf, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]any, len(movieCSVColumns))
+	for i, name := range movieCSVColumns {
+		header[i] = name
+	}
+	w.WriteRow(header...)
+	for _, m := range movies {
+		w.WriteRow(movieCSVRow(m)...)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := readMovieFile(&buf, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range records {
+		validateMovieRecord(&records[i])
+		if len(records[i].errors) != 0 {
+			t.Errorf("row %d: errors = %q, want none", i+1, records[i].errors)
+		}
+		if got := records[i].MovieRecord; !reflect.DeepEqual(got, movies[i]) {
+			t.Errorf("row %d:\n got %+v\nwant %+v", i+1, got, movies[i])
+		}
+	}
+}
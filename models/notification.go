@@ -0,0 +1,246 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// bookingEmails maps a transaction status to the email telling the customer
+// their booking reached it.
+var bookingEmails = map[string]string{
+	"paid":      "booking_confirmation",
+	"cancelled": "booking_cancelled",
+	"refunded":  "booking_refunded",
+}
+
+// defaultShowMinutes is how long a show is assumed to run when its movie has
+// no duration.
+const defaultShowMinutes = 120
+
+// loadBookingEmail loads a booking for its emails and calendar event. The show
+// is stored in local time, so like SendBookingReminders it is read in the
+// database's time zone.
+func loadBookingEmail(db querier, transactionID int) (dto.BookingEmail, error) {
+	var b dto.BookingEmail
+	var showTime time.Time
+	var duration int
+	err := db.QueryRow(context.Background(), `
+    SELECT t.id, COALESCE(u.full_name, ''), u.email, u.language, m.title,
+      t.show_date, t.show_time, (t.show_date + t.show_time)::timestamptz,
+      COALESCE(m.duration_minutes, $2), t.status, t.location, t.cinema,
+      COALESCE((SELECT ARRAY_AGG(td.seat ORDER BY td.seat) FROM transaction_details td
+        WHERE td.transaction_id = t.id), '{}'),
+      COALESCE(pm.payment_name, ''), COALESCE(t.promo_code, ''),
+      t.discount_amount, t.points_redeemed, t.points_discount, t.total_price
+    FROM transactions t
+    JOIN users u ON u.id = t.id_user
+    JOIN movies m ON m.id = t.id_movie
+    LEFT JOIN payment_method pm ON pm.id = t.payment_method
+    WHERE t.id = $1
+  `, transactionID, defaultShowMinutes).Scan(&b.TransactionID, &b.Name, &b.Email, &b.Language, &b.MovieTitle,
+		&b.ShowDate, &showTime, &b.StartsAt, &duration, &b.Status, &b.Location, &b.Cinema, &b.Seats,
+		&b.PaymentMethod, &b.PromoCode, &b.Discount, &b.PointsRedeemed, &b.PointsDiscount, &b.Total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return b, ErrTransactionNotFound
+	}
+	if err != nil {
+		return b, fmt.Errorf("failed to load booking %d: %v", transactionID, err)
+	}
+	if duration <= 0 {
+		duration = defaultShowMinutes
+	}
+	b.ShowTime = showTime.Format("15:04")
+	b.EndsAt = b.StartsAt.Add(time.Duration(duration) * time.Minute)
+	b.Subtotal = b.Total + b.Discount + b.PointsDiscount
+	return b, nil
+}
+
+// bookingCalendarStatus maps a transaction status to the iCalendar event status.
+var bookingCalendarStatus = map[string]string{
+	"pending":   "TENTATIVE",
+	"paid":      "CONFIRMED",
+	"cancelled": "CANCELLED",
+	"refunded":  "CANCELLED",
+}
+
+// bookingCalendar renders the booking as an iCalendar event. The UID stays the
+// same for a booking, so importing it again updates the existing event.
+func bookingCalendar(b dto.BookingEmail) []byte {
+	return utils.NewICS(utils.CalendarEvent{
+		UID:      fmt.Sprintf("booking-%d@tickitz", b.TransactionID),
+		Start:    b.StartsAt,
+		End:      b.EndsAt,
+		Summary:  b.MovieTitle,
+		Location: b.Cinema + ", " + b.Location,
+		Description: fmt.Sprintf("Booking #%d\nCinema: %s\nSeats: %s",
+			b.TransactionID, b.Cinema, strings.Join(b.Seats, ", ")),
+		Status: bookingCalendarStatus[b.Status],
+	})
+}
+
+// BookingCalendarFilename is the name the booking's .ics file is sent under.
+func BookingCalendarFilename(transactionID int) string {
+	return fmt.Sprintf("tickitz-booking-%d.ics", transactionID)
+}
+
+// checkTransactionOwner reports someone else's transaction as not found, so
+// users cannot probe for other people's bookings.
+func checkTransactionOwner(db querier, transactionID, userID int) error {
+	var owner int
+	err := db.QueryRow(context.Background(), `
+    SELECT id_user FROM transactions WHERE id = $1
+  `, transactionID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && owner != userID) {
+		return ErrTransactionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find transaction: %v", err)
+	}
+	return nil
+}
+
+// GetBookingCalendar returns the iCalendar event of one of the user's bookings.
+func GetBookingCalendar(transactionID, userID int) ([]byte, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	if err := checkTransactionOwner(conn, transactionID, userID); err != nil {
+		return nil, err
+	}
+
+	booking, err := loadBookingEmail(conn, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return bookingCalendar(booking), nil
+}
+
+// QueueBookingEmail renders the template called name for a transaction and
+// queues it for the customer in their language. The confirmation carries the
+// show as a calendar invite.
+func QueueBookingEmail(db querier, transactionID int, name string) error {
+	booking, err := loadBookingEmail(db, transactionID)
+	if err != nil {
+		return err
+	}
+	msg, err := utils.RenderEmail(name, booking.Language, booking)
+	if err != nil {
+		return err
+	}
+	if name == "booking_confirmation" {
+		msg.Attachments = append(msg.Attachments, utils.EmailAttachment{
+			Filename:    BookingCalendarFilename(transactionID),
+			ContentType: "text/calendar; charset=utf-8; method=PUBLISH",
+			Content:     bookingCalendar(booking),
+		})
+	}
+	return QueueEmail(db, booking.Email, msg)
+}
+
+// BookingReminderLead is how long before the show the reminder goes out,
+// configured with BOOKING_REMINDER_MINUTES_BEFORE.
+func BookingReminderLead() time.Duration {
+	return time.Duration(utils.GetEnvInt("BOOKING_REMINDER_MINUTES_BEFORE", 180)) * time.Minute
+}
+
+// StartBookingReminders runs SendBookingReminders in the background every
+// BOOKING_REMINDER_INTERVAL_MINUTES.
+func StartBookingReminders() {
+	interval := time.Duration(utils.GetEnvInt("BOOKING_REMINDER_INTERVAL_MINUTES", 5)) * time.Minute
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if sent, err := SendBookingReminders(); err != nil {
+				log.Println("Booking reminders:", err.Error())
+			} else if sent > 0 {
+				log.Printf("Booking reminders: queued %d emails", sent)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// SendBookingReminders queues reminders for customers whose paid show starts
+// within BookingReminderLead. Bookings made inside that window already got
+// their confirmation and are skipped. Like NotifyWatchers, due rows are
+// claimed with SKIP LOCKED and released again when queuing fails.
+func SendBookingReminders() (int, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	lead := int(BookingReminderLead().Minutes())
+	rows, err := conn.Query(context.Background(), `
+    WITH due AS (
+      SELECT id FROM transactions
+      WHERE status = 'paid' AND reminder_sent_at IS NULL
+        AND (show_date + show_time) > LOCALTIMESTAMP
+        AND (show_date + show_time) <= LOCALTIMESTAMP + make_interval(mins => $1)
+        AND created_at < (show_date + show_time) - make_interval(mins => $1)
+      FOR UPDATE SKIP LOCKED
+    )
+    UPDATE transactions t SET reminder_sent_at = NOW()
+    FROM due
+    WHERE t.id = due.id
+    RETURNING t.id
+  `, lead)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim reminders: %v", err)
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, id := range ids {
+		if err := QueueBookingEmail(conn, id, "booking_reminder"); err != nil {
+			log.Printf("Failed to queue reminder for transaction %d: %v", id, err)
+			_, err = conn.Exec(context.Background(), `
+        UPDATE transactions SET reminder_sent_at = NULL WHERE id = $1
+      `, id)
+			if err != nil {
+				log.Println("Failed to release reminder:", err.Error())
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// QueuePasswordResetEmail queues the reset token email, so ForgotPassword
+// answers without waiting on the mail server.
+func QueuePasswordResetEmail(to, lang string, data dto.PasswordResetEmail) error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	return QueueTemplateEmail(conn, to, "password_reset", lang, data)
+}
@@ -0,0 +1,298 @@
+package models
+
+import (
+	"be-tickitz/utils"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// sortColumn maps a public sort key to an output column of the base query.
+// Cast is the SQL type used to compare cursor values against the column.
+// NULLs of a Nullable column sort last in both directions and are written
+// as an empty cursor value.
+type sortColumn struct {
+	Column   string
+	Cast     string
+	Nullable bool
+}
+
+// orderBy is the ORDER BY clause for the column, with id as tie breaker.
+func (col sortColumn) orderBy(dir string) string {
+	nulls := ""
+	if col.Nullable {
+		nulls = " NULLS LAST"
+	}
+	return fmt.Sprintf(` ORDER BY page.%s %s%s, page.id %s`, col.Column, dir, nulls, dir)
+}
+
+// cursorCondition selects the rows after the cursor (value, id), comparing
+// with cmp. Rows with a NULL sort value come after every other row, so a
+// cursor on a NULL only moves on by id and any other cursor still lets them
+// through.
+func (col sortColumn) cursorCondition(cmp, value string, id int, params []any) (string, []any) {
+	if col.Nullable && value == "" {
+		params = append(params, id)
+		return fmt.Sprintf(`(page.%s IS NULL AND page.id %s $%d)`, col.Column, cmp, len(params)), params
+	}
+
+	params = append(params, value, id)
+	cond := fmt.Sprintf(`(page.%s, page.id) %s ($%d::text::%s, $%d)`, col.Column, cmp, len(params)-1, col.Cast, len(params))
+	if col.Nullable {
+		cond = fmt.Sprintf(`(page.%s IS NULL OR %s)`, col.Column, cond)
+	}
+	return cond, params
+}
+
+// rangeFilter bounds a column of the base query with an inclusive lower and
+// upper bound read from the MinParam and MaxParam query parameters. Either
+// bound may be omitted. Cast is the SQL type the bounds are compared as.
+type rangeFilter struct {
+	Column   string
+	Cast     string
+	MinParam string
+	MaxParam string
+}
+
+// filterKind is how a filter value is compared with its column.
+type filterKind int
+
+const (
+	// filterText matches free text such as a city, case insensitively.
+	filterText filterKind = iota
+	// filterCode matches a lower case code such as a status.
+	filterCode
+	// filterInt and filterBool match numeric ids and flags.
+	filterInt
+	filterBool
+)
+
+// filterColumn maps a filter parameter to an output column of the base query.
+// Only text columns are wrapped in LOWER, so the other kinds compare the bare
+// column and can use a plain index on it.
+type filterColumn struct {
+	Column string
+	Kind   filterKind
+}
+
+// valid reports whether value can be compared with the column at all. A
+// value that is not a number or a flag matches no rows, the same as before
+// filters were typed, instead of failing the cast in the database.
+func (f filterColumn) valid(value string) bool {
+	switch f.Kind {
+	case filterInt:
+		_, err := strconv.Atoi(value)
+		return err == nil
+	case filterBool:
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	}
+	return true
+}
+
+// condition is the equality between the column and the nth query parameter.
+func (f filterColumn) condition(n int) string {
+	switch f.Kind {
+	case filterCode:
+		return fmt.Sprintf(`page.%s::text = LOWER($%d)`, f.Column, n)
+	case filterInt:
+		return fmt.Sprintf(`page.%s = $%d::text::int`, f.Column, n)
+	case filterBool:
+		return fmt.Sprintf(`page.%s = $%d::text::boolean`, f.Column, n)
+	default:
+		return fmt.Sprintf(`LOWER(page.%s) = LOWER($%d)`, f.Column, n)
+	}
+}
+
+// listSpec describes which sorts and filters a list endpoint accepts. The base
+// query of every list must expose an "id" column, used as a tie breaker.
+type listSpec struct {
+	Sorts         map[string]sortColumn
+	DefaultSort   string
+	DefaultDesc   bool
+	SearchColumns []string
+	Filters       map[string]filterColumn
+	Ranges        []rangeFilter
+}
+
+// sortKey resolves the requested sort to a key known by the spec.
+func (spec listSpec) sortKey(q utils.PageQuery) (string, bool) {
+	if _, ok := spec.Sorts[q.Sort]; ok {
+		return q.Sort, q.Desc
+	}
+	return spec.DefaultSort, spec.DefaultDesc || q.Desc
+}
+
+// queryPage wraps base in a subquery, applies search, filters, sorting and
+// offset or cursor paging, and returns the page rows together with the total
+// number of matching rows. One extra row is fetched so trimPage can tell
+// whether there is a next page.
+func queryPage(conn *pgxpool.Conn, base string, params []any, q utils.PageQuery, spec listSpec) (pgx.Rows, int, error) {
+	filtered, params, conditions := filterQuery(base, params, q, spec)
+
+	var total int
+	err := conn.QueryRow(context.Background(), `SELECT COUNT(*) FROM (`+filtered+`) AS counted`, params...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, desc := spec.sortKey(q)
+	col := spec.Sorts[key]
+	dir, cmp := "ASC", ">"
+	if desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	query := filtered
+	if q.Cursor != "" {
+		value, id, err := utils.DecodeCursor(q.Cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		var cursorCond string
+		cursorCond, params = col.cursorCondition(cmp, value, id, params)
+		if len(conditions) > 0 {
+			query += ` AND ` + cursorCond
+		} else {
+			query += ` WHERE ` + cursorCond
+		}
+	}
+
+	query += col.orderBy(dir)
+
+	if q.Paged() {
+		params = append(params, q.Limit+1)
+		query += fmt.Sprintf(` LIMIT $%d`, len(params))
+		if q.Cursor == "" {
+			params = append(params, q.Offset())
+			query += fmt.Sprintf(` OFFSET $%d`, len(params))
+		}
+	}
+
+	rows, err := conn.Query(context.Background(), query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// filterQuery wraps base in a subquery aliased page and applies the search and
+// filters of q. It returns the query, the extended params and the conditions
+// that were added.
+func filterQuery(base string, params []any, q utils.PageQuery, spec listSpec) (string, []any, []string) {
+	conditions := []string{}
+
+	if q.Search != "" && len(spec.SearchColumns) > 0 {
+		params = append(params, q.Search)
+		matches := []string{}
+		for _, col := range spec.SearchColumns {
+			matches = append(matches, fmt.Sprintf(`page.%s ILIKE '%%' || $%d || '%%'`, col, len(params)))
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	filterKeys := make([]string, 0, len(spec.Filters))
+	for key := range spec.Filters {
+		filterKeys = append(filterKeys, key)
+	}
+	sort.Strings(filterKeys)
+	for _, key := range filterKeys {
+		value := q.Filters.Get(key)
+		switch filter := spec.Filters[key]; {
+		case value == "":
+		case !filter.valid(value):
+			conditions = append(conditions, `FALSE`)
+		default:
+			params = append(params, value)
+			conditions = append(conditions, filter.condition(len(params)))
+		}
+	}
+
+	for _, r := range spec.Ranges {
+		if value := q.Filters.Get(r.MinParam); value != "" {
+			params = append(params, value)
+			conditions = append(conditions, fmt.Sprintf(`page.%s >= $%d::text::%s`, r.Column, len(params), r.Cast))
+		}
+		if value := q.Filters.Get(r.MaxParam); value != "" {
+			params = append(params, value)
+			conditions = append(conditions, fmt.Sprintf(`page.%s <= $%d::text::%s`, r.Column, len(params), r.Cast))
+		}
+	}
+
+	filtered := `SELECT * FROM (` + base + `) AS page`
+	if len(conditions) > 0 {
+		filtered += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	return filtered, params, conditions
+}
+
+// exportBatchSize is how many rows streamList fetches from its cursor at once.
+const exportBatchSize = 500
+
+// streamList runs base with the search, filters and sort of q, ignoring any
+// paging, through a server side cursor and calls each for every row. Only one
+// batch of rows is held in memory at a time, so it suits exports of any size.
+func streamList(base string, params []any, q utils.PageQuery, spec listSpec, each func(pgx.Rows) error) error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query, params, _ := filterQuery(base, params, q, spec)
+	key, desc := spec.sortKey(q)
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	query += spec.Sorts[key].orderBy(dir)
+
+	if _, err := tx.Exec(ctx, `DECLARE export_rows NO SCROLL CURSOR FOR `+query, params...); err != nil {
+		return fmt.Errorf("failed to open export cursor: %v", err)
+	}
+
+	for {
+		rows, err := tx.Query(ctx, fmt.Sprintf(`FETCH FORWARD %d FROM export_rows`, exportBatchSize))
+		if err != nil {
+			return err
+		}
+		fetched := 0
+		for rows.Next() {
+			fetched++
+			if err := each(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if fetched < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// trimPage drops the look-ahead row fetched by queryPage and builds the cursor
+// pointing after the last returned item.
+func trimPage[T any](items []T, q utils.PageQuery, key func(T) (string, int)) ([]T, string) {
+	if !q.Paged() || len(items) <= q.Limit {
+		return items, ""
+	}
+	items = items[:q.Limit]
+	value, id := key(items[len(items)-1])
+	return items, utils.EncodeCursor(value, id)
+}
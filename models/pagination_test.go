@@ -0,0 +1,116 @@
+package models
+
+import (
+	"be-tickitz/utils"
+	"reflect"
+	"testing"
+)
+
+func TestSortColumnCursorCondition(t *testing.T) {
+	title := sortColumn{Column: "title", Cast: "text"}
+	released := sortColumn{Column: "release_date", Cast: "date", Nullable: true}
+
+	tests := []struct {
+		name   string
+		col    sortColumn
+		cmp    string
+		value  string
+		want   string
+		params []any
+	}{
+		{"plain", title, ">", "Dune", `(page.title, page.id) > ($2::text::text, $3)`, []any{"x", "Dune", 7}},
+		{"nullable value", released, "<", "2025-07-30",
+			`(page.release_date IS NULL OR (page.release_date, page.id) < ($2::text::date, $3))`, []any{"x", "2025-07-30", 7}},
+		{"nullable on NULL", released, ">", "", `(page.release_date IS NULL AND page.id > $2)`, []any{"x", 7}},
+	}
+	for _, tt := range tests {
+		cond, params := tt.col.cursorCondition(tt.cmp, tt.value, 7, []any{"x"})
+		if cond != tt.want || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.name, cond, params, tt.want, tt.params)
+		}
+	}
+
+	if got := released.orderBy("DESC"); got != ` ORDER BY page.release_date DESC NULLS LAST, page.id DESC` {
+		t.Errorf("nullable orderBy = %q", got)
+	}
+	if got := title.orderBy("ASC"); got != ` ORDER BY page.title ASC, page.id ASC` {
+		t.Errorf("orderBy = %q", got)
+	}
+}
+
+func TestListSpecSortKey(t *testing.T) {
+	spec := listSpec{
+		Sorts:       map[string]sortColumn{"id": {Column: "id"}, "title": {Column: "title"}},
+		DefaultSort: "id",
+		DefaultDesc: true,
+	}
+	if key, desc := spec.sortKey(utils.PageQuery{Sort: "title"}); key != "title" || desc {
+		t.Errorf("sortKey(title) = %s %v, want title ascending", key, desc)
+	}
+	if key, desc := spec.sortKey(utils.PageQuery{Sort: "password"}); key != "id" || !desc {
+		t.Errorf("unknown sort = %s %v, want the default", key, desc)
+	}
+}
+
+func TestFilterQuery(t *testing.T) {
+	spec := listSpec{
+		SearchColumns: []string{"title", "description"},
+		Filters: map[string]filterColumn{
+			"status": {Column: "status", Kind: filterCode},
+			"genre":  {Column: "genre_id", Kind: filterInt},
+			"city":   {Column: "location"},
+			"active": {Column: "is_active", Kind: filterBool},
+		},
+		Ranges: []rangeFilter{{Column: "price", Cast: "int", MinParam: "minPrice", MaxParam: "maxPrice"}},
+	}
+	q := utils.PageQuery{Search: "dune", Filters: map[string][]string{
+		"status": {"Paid"}, "genre": {"3"}, "city": {"Jakarta"}, "active": {"true"},
+		"maxPrice": {"50000"}, "ignored": {"x"},
+	}}
+
+	query, params, conditions := filterQuery(`SELECT * FROM t WHERE a = $1`, []any{1}, q, spec)
+	want := `SELECT * FROM (SELECT * FROM t WHERE a = $1) AS page WHERE ` +
+		`(page.title ILIKE '%' || $2 || '%' OR page.description ILIKE '%' || $2 || '%') AND ` +
+		`page.is_active = $3::text::boolean AND LOWER(page.location) = LOWER($4) AND ` +
+		`page.genre_id = $5::text::int AND page.status::text = LOWER($6) AND ` +
+		`page.price <= $7::text::int`
+	if query != want {
+		t.Errorf("query =\n%s\nwant\n%s", query, want)
+	}
+	if !reflect.DeepEqual(params, []any{1, "dune", "true", "Jakarta", "3", "Paid", "50000"}) || len(conditions) != 6 {
+		t.Errorf("params = %v, conditions = %d", params, len(conditions))
+	}
+}
+
+func TestFilterQueryInvalidValue(t *testing.T) {
+	spec := listSpec{Filters: map[string]filterColumn{
+		"movieId":  {Column: "id_movie", Kind: filterInt},
+		"isActive": {Column: "is_active", Kind: filterBool},
+	}}
+	q := utils.PageQuery{Filters: map[string][]string{"movieId": {"abc"}, "isActive": {"maybe"}}}
+
+	query, params, _ := filterQuery(`SELECT * FROM t`, nil, q, spec)
+	if want := `SELECT * FROM (SELECT * FROM t) AS page WHERE FALSE AND FALSE`; query != want || len(params) != 0 {
+		t.Errorf("query = %s %v, want %s", query, params, want)
+	}
+}
+
+func TestTrimPage(t *testing.T) {
+	items := []int{10, 20, 30}
+	key := func(n int) (string, int) { return "v", n }
+
+	page, cursor := trimPage(items, utils.PageQuery{Limit: 2}, key)
+	if !reflect.DeepEqual(page, []int{10, 20}) {
+		t.Fatalf("page = %v, want the look-ahead row dropped", page)
+	}
+	if value, id, err := utils.DecodeCursor(cursor); err != nil || value != "v" || id != 20 {
+		t.Fatalf("cursor = %q, %d, %v; want it after the last row", value, id, err)
+	}
+
+	if page, cursor := trimPage(items, utils.PageQuery{Limit: 3}, key); len(page) != 3 || cursor != "" {
+		t.Errorf("last page = %v %q, want every row and no cursor", page, cursor)
+	}
+	if page, cursor := trimPage(items, utils.PageQuery{}, key); len(page) != 3 || cursor != "" {
+		t.Errorf("unpaged = %v %q, want every row and no cursor", page, cursor)
+	}
+}
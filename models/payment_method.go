@@ -1,38 +1,40 @@
 package models
 
 import (
+	"be-tickitz/dto"
 	"be-tickitz/utils"
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type PaymentMethod struct {
-	ID          int    `json:"id"`
-	PaymentName string `json:"paymentName"`
+	ID          int        `json:"id"`
+	PaymentName string     `json:"paymentName"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 }
 
-func CreatePaymentMethod(paymentName string) (PaymentMethod, error) {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return PaymentMethod{}, err
-	}
-	defer conn.Release()
-
+func CreatePaymentMethod(admin dto.AuditActor, paymentName string) (PaymentMethod, error) {
 	var method PaymentMethod
-	err = conn.QueryRow(context.Background(), `
-		INSERT INTO payment_method (payment_name)
-		VALUES ($1)
-		RETURNING id, payment_name
-	`, paymentName).Scan(
-		&method.ID,
-		&method.PaymentName,
-	)
+	_, err := withAudit(admin, "create", "payment_method", 0, func(tx pgx.Tx) (int, error) {
+		err := tx.QueryRow(context.Background(), `
+			INSERT INTO payment_method (payment_name)
+			VALUES ($1)
+			RETURNING id, payment_name, deleted_at
+		`, paymentName).Scan(
+			&method.ID,
+			&method.PaymentName,
+			&method.DeletedAt,
+		)
+		return method.ID, err
+	})
 
 	return method, err
 }
 
-func GetAllPaymentMethod() ([]PaymentMethod, error) {
+// GetAllPaymentMethod lists payment methods; public listings use ScopeLive.
+func GetAllPaymentMethod(scope CatalogScope) ([]PaymentMethod, error) {
 	conn, err := utils.ConnectDB()
 	if err != nil {
 		return nil, err
@@ -40,7 +42,9 @@ func GetAllPaymentMethod() ([]PaymentMethod, error) {
 	defer conn.Release()
 
 	rows, err := conn.Query(context.Background(), `
-    SELECT id, payment_name FROM payment_method ORDER BY payment_name ASC
+    SELECT id, payment_name, deleted_at FROM payment_method
+    WHERE `+scope.sql("deleted_at")+`
+    ORDER BY payment_name ASC
   `)
 	if err != nil {
 		return nil, err
@@ -49,13 +53,10 @@ func GetAllPaymentMethod() ([]PaymentMethod, error) {
 	return pgx.CollectRows(rows, pgx.RowToStructByName[PaymentMethod])
 }
 
-func DeletePaymentMethod(id string) error {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return err
-	}
-	defer conn.Release()
+func DeletePaymentMethod(admin dto.AuditActor, id int) error {
+	return softDelete(admin, "payment_method", id, ErrPaymentMethodNotFound)
+}
 
-	_, err = conn.Exec(context.Background(), `DELETE FROM payment_method WHERE id = $1`, id)
-	return err
+func RestorePaymentMethod(admin dto.AuditActor, id int) error {
+	return restoreDeleted(admin, "payment_method", id, ErrPaymentMethodNotFound)
 }
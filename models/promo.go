@@ -0,0 +1,509 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrPromoNotFound = errors.New("promo not found")
+	// ErrPromoInvalid is wrapped with the reason a code cannot be applied.
+	ErrPromoInvalid = errors.New("promo code cannot be applied")
+)
+
+// querier is satisfied by both a pooled connection and a transaction, for
+// helpers that run either standalone or inside CreateTransaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// promoOrder is what a promo code is checked against.
+type promoOrder struct {
+	UserID        int
+	MovieID       int
+	Cinema        string
+	PaymentMethod int
+	Subtotal      int
+}
+
+var promoListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":        {Column: "id", Cast: "int"},
+		"code":      {Column: "code", Cast: "text"},
+		"createdAt": {Column: "created_at", Cast: "timestamp"},
+		"endsAt":    {Column: "ends_at", Cast: "timestamptz", Nullable: true},
+	},
+	DefaultSort:   "createdAt",
+	DefaultDesc:   true,
+	SearchColumns: []string{"code", "description"},
+	Filters: map[string]filterColumn{
+		"isActive":     {Column: "is_active", Kind: filterBool},
+		"discountType": {Column: "discount_type", Kind: filterCode},
+	},
+}
+
+const promoSelect = `
+    SELECT p.id, p.code, p.description, p.discount_type, p.discount_value, p.max_discount,
+      p.min_spend, p.usage_limit, p.per_user_limit, p.starts_at, p.ends_at, p.is_active,
+      COALESCE((SELECT ARRAY_AGG(id_movie ORDER BY id_movie) FROM promo_movies WHERE id_promo = p.id), '{}') AS movie_ids,
+      COALESCE((SELECT ARRAY_AGG(cinema ORDER BY cinema) FROM promo_cinemas WHERE id_promo = p.id), '{}') AS cinemas,
+      COALESCE((SELECT ARRAY_AGG(id_payment_method ORDER BY id_payment_method) FROM promo_payment_methods WHERE id_promo = p.id), '{}') AS payment_method_ids,
+      (SELECT COUNT(*) FROM transactions t WHERE t.id_promo = p.id AND t.status NOT IN ('cancelled', 'refunded')) AS used_count,
+      p.created_at
+    FROM promos p
+  `
+
+func scanPromo(row pgx.Row) (dto.Promo, error) {
+	var p dto.Promo
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.Description,
+		&p.DiscountType,
+		&p.DiscountValue,
+		&p.MaxDiscount,
+		&p.MinSpend,
+		&p.UsageLimit,
+		&p.PerUserLimit,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.IsActive,
+		&p.MovieIDs,
+		&p.Cinemas,
+		&p.PaymentMethodIDs,
+		&p.UsedCount,
+		&p.CreatedAt,
+	)
+	return p, err
+}
+
+func validatePromo(p dto.Promo) error {
+	if p.DiscountType == "percent" && p.DiscountValue > 100 {
+		return fmt.Errorf("percentage discount must be between 1 and 100")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && p.EndsAt.Before(*p.StartsAt) {
+		return fmt.Errorf("endsAt must be after startsAt")
+	}
+	return nil
+}
+
+func CreatePromo(admin dto.AuditActor, input dto.CreatePromoRequest) (dto.Promo, error) {
+	promo := dto.Promo{
+		Code:          strings.ToUpper(strings.TrimSpace(input.Code)),
+		Description:   input.Description,
+		DiscountType:  input.DiscountType,
+		DiscountValue: input.DiscountValue,
+		MaxDiscount:   input.MaxDiscount,
+		MinSpend:      input.MinSpend,
+		UsageLimit:    input.UsageLimit,
+		PerUserLimit:  input.PerUserLimit,
+		StartsAt:      input.StartsAt,
+		EndsAt:        input.EndsAt,
+		IsActive:      input.IsActive == nil || *input.IsActive,
+	}
+	if err := validatePromo(promo); err != nil {
+		return dto.Promo{}, err
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Promo{}, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return dto.Promo{}, err
+	}
+	defer tx.Rollback(context.Background())
+
+	err = tx.QueryRow(context.Background(), `
+    INSERT INTO promos (
+      code, description, discount_type, discount_value, max_discount, min_spend,
+      usage_limit, per_user_limit, starts_at, ends_at, is_active
+    )
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    RETURNING id
+  `, promo.Code, promo.Description, promo.DiscountType, promo.DiscountValue, promo.MaxDiscount, promo.MinSpend,
+		promo.UsageLimit, promo.PerUserLimit, promo.StartsAt, promo.EndsAt, promo.IsActive).Scan(&promo.ID)
+	if err != nil {
+		return dto.Promo{}, fmt.Errorf("failed to create promo: %v", err)
+	}
+
+	if err := setPromoRestrictions(tx, promo.ID, &input.MovieIDs, &input.Cinemas, &input.PaymentMethodIDs); err != nil {
+		return dto.Promo{}, err
+	}
+
+	if err := recordAudit(tx, admin, "create", "promo", promo.ID, nil); err != nil {
+		return dto.Promo{}, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return dto.Promo{}, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return GetPromoByID(promo.ID)
+}
+
+// setPromoRestrictions replaces each restriction list that is not nil.
+func setPromoRestrictions(tx pgx.Tx, promoID int, movieIDs *[]int, cinemas *[]string, paymentMethodIDs *[]int) error {
+	if movieIDs != nil {
+		if _, err := tx.Exec(context.Background(), `DELETE FROM promo_movies WHERE id_promo = $1`, promoID); err != nil {
+			return err
+		}
+		for _, id := range *movieIDs {
+			_, err := tx.Exec(context.Background(), `
+        INSERT INTO promo_movies (id_promo, id_movie) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+      `, promoID, id)
+			if err != nil {
+				return fmt.Errorf("failed to restrict promo to movie %d: %v", id, err)
+			}
+		}
+	}
+
+	if cinemas != nil {
+		if _, err := tx.Exec(context.Background(), `DELETE FROM promo_cinemas WHERE id_promo = $1`, promoID); err != nil {
+			return err
+		}
+		for _, cinema := range *cinemas {
+			cinema = strings.TrimSpace(cinema)
+			if cinema == "" {
+				continue
+			}
+			_, err := tx.Exec(context.Background(), `
+        INSERT INTO promo_cinemas (id_promo, cinema) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+      `, promoID, cinema)
+			if err != nil {
+				return fmt.Errorf("failed to restrict promo to cinema %s: %v", cinema, err)
+			}
+		}
+	}
+
+	if paymentMethodIDs != nil {
+		if _, err := tx.Exec(context.Background(), `DELETE FROM promo_payment_methods WHERE id_promo = $1`, promoID); err != nil {
+			return err
+		}
+		for _, id := range *paymentMethodIDs {
+			_, err := tx.Exec(context.Background(), `
+        INSERT INTO promo_payment_methods (id_promo, id_payment_method) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+      `, promoID, id)
+			if err != nil {
+				return fmt.Errorf("failed to restrict promo to payment method %d: %v", id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func GetPromoByID(id int) (dto.Promo, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Promo{}, err
+	}
+	defer conn.Release()
+
+	promo, err := scanPromo(conn.QueryRow(context.Background(), promoSelect+` WHERE p.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.Promo{}, ErrPromoNotFound
+	}
+	return promo, err
+}
+
+func GetAllPromos(q utils.PageQuery) ([]dto.Promo, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, promoSelect, nil, q, promoListSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	var promos []dto.Promo
+	for rows.Next() {
+		promo, err := scanPromo(rows)
+		if err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		promos = append(promos, promo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := promoListSpec.sortKey(q)
+	promos, nextCursor := trimPage(promos, q, func(p dto.Promo) (string, int) {
+		switch key {
+		case "code":
+			return p.Code, p.ID
+		case "createdAt":
+			return p.CreatedAt.Format("2006-01-02 15:04:05.999999"), p.ID
+		case "endsAt":
+			if p.EndsAt == nil {
+				return "", p.ID
+			}
+			return p.EndsAt.Format("2006-01-02 15:04:05.999999Z07:00"), p.ID
+		}
+		return strconv.Itoa(p.ID), p.ID
+	})
+
+	return promos, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
+
+// optionalLimit maps 0 from an update request to "no limit".
+func optionalLimit(value *int) *int {
+	if value == nil || *value == 0 {
+		return nil
+	}
+	return value
+}
+
+func UpdatePromo(admin dto.AuditActor, id int, input dto.UpdatePromoRequest) (dto.Promo, error) {
+	promo, err := GetPromoByID(id)
+	if err != nil {
+		return dto.Promo{}, err
+	}
+
+	if input.Code != nil {
+		promo.Code = strings.ToUpper(strings.TrimSpace(*input.Code))
+	}
+	if input.Description != nil {
+		promo.Description = *input.Description
+	}
+	if input.DiscountType != nil {
+		promo.DiscountType = *input.DiscountType
+	}
+	if input.DiscountValue != nil {
+		promo.DiscountValue = *input.DiscountValue
+	}
+	if input.MaxDiscount != nil {
+		promo.MaxDiscount = optionalLimit(input.MaxDiscount)
+	}
+	if input.MinSpend != nil {
+		promo.MinSpend = *input.MinSpend
+	}
+	if input.UsageLimit != nil {
+		promo.UsageLimit = optionalLimit(input.UsageLimit)
+	}
+	if input.PerUserLimit != nil {
+		promo.PerUserLimit = optionalLimit(input.PerUserLimit)
+	}
+	if input.StartsAt != nil {
+		promo.StartsAt = input.StartsAt
+	}
+	if input.EndsAt != nil {
+		promo.EndsAt = input.EndsAt
+	}
+	if input.IsActive != nil {
+		promo.IsActive = *input.IsActive
+	}
+	if err := validatePromo(promo); err != nil {
+		return dto.Promo{}, err
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Promo{}, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return dto.Promo{}, err
+	}
+	defer tx.Rollback(context.Background())
+
+	before, err := auditSnapshot(tx, "promo", id)
+	if err != nil {
+		return dto.Promo{}, err
+	}
+
+	_, err = tx.Exec(context.Background(), `
+    UPDATE promos SET
+      code = $1,
+      description = $2,
+      discount_type = $3,
+      discount_value = $4,
+      max_discount = $5,
+      min_spend = $6,
+      usage_limit = $7,
+      per_user_limit = $8,
+      starts_at = $9,
+      ends_at = $10,
+      is_active = $11,
+      updated_at = NOW()
+    WHERE id = $12
+  `, promo.Code, promo.Description, promo.DiscountType, promo.DiscountValue, promo.MaxDiscount, promo.MinSpend,
+		promo.UsageLimit, promo.PerUserLimit, promo.StartsAt, promo.EndsAt, promo.IsActive, id)
+	if err != nil {
+		return dto.Promo{}, fmt.Errorf("failed to update promo: %v", err)
+	}
+
+	if err := setPromoRestrictions(tx, id, input.MovieIDs, input.Cinemas, input.PaymentMethodIDs); err != nil {
+		return dto.Promo{}, err
+	}
+
+	if err := recordAudit(tx, admin, "update", "promo", id, before); err != nil {
+		return dto.Promo{}, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return dto.Promo{}, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return GetPromoByID(id)
+}
+
+func DeletePromo(admin dto.AuditActor, id int) error {
+	_, err := withAudit(admin, "delete", "promo", id, func(tx pgx.Tx) (int, error) {
+		tag, err := tx.Exec(context.Background(), `DELETE FROM promos WHERE id = $1`, id)
+		if err != nil {
+			return 0, err
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, ErrPromoNotFound
+		}
+		return id, nil
+	})
+	return err
+}
+
+// QuotePromo previews the discount a code gives on an order without using it.
+// The subtotal is priced from the showtime, as at checkout.
+func QuotePromo(userID int, input dto.ValidatePromoRequest) (dto.PromoQuote, error) {
+	showDate, showTime, err := parseShowSlot(input.ShowDate, input.ShowTime)
+	if err != nil {
+		return dto.PromoQuote{}, fmt.Errorf("%w: %v", ErrPromoInvalid, err)
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.PromoQuote{}, err
+	}
+	defer conn.Release()
+
+	showtimeID, price, err := findShowtime(conn, input.MovieID, showDate, showTime, input.Location, input.Cinema)
+	if err != nil {
+		return dto.PromoQuote{}, err
+	}
+	if showtimeID == 0 {
+		return dto.PromoQuote{}, fmt.Errorf("%w: only valid for scheduled showtimes", ErrPromoInvalid)
+	}
+
+	order := promoOrder{
+		UserID:        userID,
+		MovieID:       input.MovieID,
+		Cinema:        input.Cinema,
+		PaymentMethod: input.PaymentMethod,
+		Subtotal:      len(input.Seats) * price,
+	}
+	_, quote, err := applyPromo(conn, input.Code, order, false)
+	return quote, err
+}
+
+// applyPromo checks a code against an order and computes the discount. With
+// lock set the promo row is locked until the surrounding transaction ends, so
+// concurrent checkouts cannot both take the last use.
+func applyPromo(db querier, code string, order promoOrder, lock bool) (int, dto.PromoQuote, error) {
+	query := `
+    SELECT id, code, discount_type, discount_value, max_discount, min_spend,
+      usage_limit, per_user_limit, starts_at, ends_at, is_active
+    FROM promos
+    WHERE UPPER(code) = UPPER($1)
+  `
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	var p dto.Promo
+	err := db.QueryRow(context.Background(), query, strings.TrimSpace(code)).Scan(
+		&p.ID, &p.Code, &p.DiscountType, &p.DiscountValue, &p.MaxDiscount, &p.MinSpend,
+		&p.UsageLimit, &p.PerUserLimit, &p.StartsAt, &p.EndsAt, &p.IsActive,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: unknown code", ErrPromoInvalid)
+	}
+	if err != nil {
+		return 0, dto.PromoQuote{}, err
+	}
+
+	now := time.Now()
+	switch {
+	case !p.IsActive:
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: code is not active", ErrPromoInvalid)
+	case p.StartsAt != nil && now.Before(*p.StartsAt):
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: code is not valid yet", ErrPromoInvalid)
+	case p.EndsAt != nil && now.After(*p.EndsAt):
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: code has expired", ErrPromoInvalid)
+	case order.Subtotal < p.MinSpend:
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: minimum spend is %d", ErrPromoInvalid, p.MinSpend)
+	}
+
+	var movieOK, cinemaOK, paymentOK bool
+	var used, usedByUser int
+	err = db.QueryRow(context.Background(), `
+    SELECT
+      NOT EXISTS (SELECT 1 FROM promo_movies WHERE id_promo = $1)
+        OR EXISTS (SELECT 1 FROM promo_movies WHERE id_promo = $1 AND id_movie = $2),
+      NOT EXISTS (SELECT 1 FROM promo_cinemas WHERE id_promo = $1)
+        OR EXISTS (SELECT 1 FROM promo_cinemas WHERE id_promo = $1 AND LOWER(cinema) = LOWER($3)),
+      NOT EXISTS (SELECT 1 FROM promo_payment_methods WHERE id_promo = $1)
+        OR EXISTS (SELECT 1 FROM promo_payment_methods WHERE id_promo = $1 AND id_payment_method = $4),
+      (SELECT COUNT(*) FROM transactions WHERE id_promo = $1 AND status NOT IN ('cancelled', 'refunded')),
+      (SELECT COUNT(*) FROM transactions WHERE id_promo = $1 AND id_user = $5 AND status NOT IN ('cancelled', 'refunded'))
+  `, p.ID, order.MovieID, order.Cinema, order.PaymentMethod, order.UserID).Scan(
+		&movieOK, &cinemaOK, &paymentOK, &used, &usedByUser,
+	)
+	if err != nil {
+		return 0, dto.PromoQuote{}, err
+	}
+
+	switch {
+	case !movieOK:
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: not valid for this movie", ErrPromoInvalid)
+	case !cinemaOK:
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: not valid at this cinema", ErrPromoInvalid)
+	case !paymentOK:
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: not valid for this payment method", ErrPromoInvalid)
+	case p.UsageLimit != nil && used >= *p.UsageLimit:
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: code has been fully redeemed", ErrPromoInvalid)
+	case p.PerUserLimit != nil && usedByUser >= *p.PerUserLimit:
+		return 0, dto.PromoQuote{}, fmt.Errorf("%w: you have already used this code", ErrPromoInvalid)
+	}
+
+	discount := p.DiscountValue
+	if p.DiscountType == "percent" {
+		discount = order.Subtotal * p.DiscountValue / 100
+		if p.MaxDiscount != nil && discount > *p.MaxDiscount {
+			discount = *p.MaxDiscount
+		}
+	}
+	if discount > order.Subtotal {
+		discount = order.Subtotal
+	}
+
+	return p.ID, dto.PromoQuote{
+		Code:     p.Code,
+		Subtotal: order.Subtotal,
+		Discount: discount,
+		Total:    order.Subtotal - discount,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Weights of each kind of credit when matching a movie to a user's history.
+// A shared director says more about taste than a shared genre.
+const (
+	recommendGenreWeight    = 1.0
+	recommendDirectorWeight = 2.0
+	recommendActorWeight    = 1.5
+	// recommendPopularityWeight keeps popularity as a tie breaker between
+	// movies that match the history equally well.
+	recommendPopularityWeight = 0.25
+	// recommendTopBilled limits actor matching to the leading cast.
+	recommendTopBilled = 10
+)
+
+type tasteProfile struct {
+	watched   int
+	genres    map[int]float64
+	directors map[int]float64
+	actors    map[int]float64
+}
+
+type recommendCandidate struct {
+	movie     dto.RecommendedMovie
+	directors []int
+	actors    []int
+	tickets   int
+	rating    float64
+}
+
+// GetRecommendations scores the now-showing movies the user has not booked
+// yet against the genres, directors and leading cast of the movies they did
+// book. Users without history get the most popular movies.
+func GetRecommendations(userID int, limit int) ([]dto.RecommendedMovie, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	profile := tasteProfile{
+		genres:    map[int]float64{},
+		directors: map[int]float64{},
+		actors:    map[int]float64{},
+	}
+
+	err = conn.QueryRow(context.Background(), `
+    SELECT COUNT(DISTINCT id_movie) FROM transactions WHERE id_user = $1 AND status = 'paid'
+  `, userID).Scan(&profile.watched)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load booking history: %v", err)
+	}
+
+	if profile.watched > 0 {
+		rows, err := conn.Query(context.Background(), `
+      WITH watched AS (
+        SELECT DISTINCT id_movie FROM transactions WHERE id_user = $1 AND status = 'paid'
+      )
+      SELECT 'genre', mg.id_genre, COUNT(*) FROM movie_genres mg JOIN watched w USING (id_movie) GROUP BY mg.id_genre
+      UNION ALL
+      SELECT 'director', md.id_director, COUNT(*) FROM movie_directors md JOIN watched w USING (id_movie) GROUP BY md.id_director
+      UNION ALL
+      SELECT 'actor', mc.id_actor, COUNT(*) FROM movie_casts mc JOIN watched w USING (id_movie)
+      WHERE mc.billing_order < $2
+      GROUP BY mc.id_actor
+    `, userID, recommendTopBilled)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load taste profile: %v", err)
+		}
+		for rows.Next() {
+			var kind string
+			var id, count int
+			if err := rows.Scan(&kind, &id, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			// Share of the watched movies that had this credit, 0..1.
+			share := float64(count) / float64(profile.watched)
+			switch kind {
+			case "genre":
+				profile.genres[id] = share
+			case "director":
+				profile.directors[id] = share
+			case "actor":
+				profile.actors[id] = share
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	where, params := nowShowingSQL(dto.ShowtimeFilter{}, []any{userID, recommendTopBilled})
+	rows, err := conn.Query(context.Background(), `
+    SELECT m.id, m.title, m.release_date, m.duration_minutes, m.image, m.horizontal_image,
+      COALESCE((SELECT ARRAY_AGG(id_genre) FROM movie_genres WHERE id_movie = m.id), '{}'),
+      COALESCE((SELECT ARRAY_AGG(id_director) FROM movie_directors WHERE id_movie = m.id), '{}'),
+      COALESCE((SELECT ARRAY_AGG(id_actor) FROM movie_casts WHERE id_movie = m.id AND billing_order < $2), '{}'),
+      (SELECT COUNT(*) FROM transaction_details td
+        JOIN transactions t ON t.id = td.transaction_id
+        WHERE t.id_movie = m.id AND t.status = 'paid' AND t.created_at >= NOW() - INTERVAL '30 days') AS tickets,
+      COALESCE((SELECT AVG(rating) FROM reviews WHERE id_movie = m.id AND status <> 'hidden'), 0)::float8
+    FROM movies m
+    WHERE m.deleted_at IS NULL AND `+where+`
+      AND m.id NOT IN (SELECT id_movie FROM transactions WHERE id_user = $1 AND id_movie IS NOT NULL)
+  `, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load now showing movies: %v", err)
+	}
+	defer rows.Close()
+
+	var candidates []recommendCandidate
+	maxTickets := 0
+	for rows.Next() {
+		var c recommendCandidate
+		m := &c.movie
+		if err := rows.Scan(&m.ID, &m.Title, &m.ReleaseDate, &m.Duration, &m.Image, &m.HorizontalImage,
+			&m.GenreIDs, &c.directors, &c.actors, &c.tickets, &c.rating); err != nil {
+			return nil, err
+		}
+		if c.tickets > maxTickets {
+			maxTickets = c.tickets
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		popularity := popularityScore(c.tickets, maxTickets, c.rating)
+		affinity := profile.affinity(c)
+		if affinity > 0 {
+			c.movie.Score = affinity + recommendPopularityWeight*popularity
+			c.movie.Reason = "history"
+		} else {
+			c.movie.Score = recommendPopularityWeight * popularity
+			c.movie.Reason = "popular"
+		}
+		c.movie.Score = math.Round(c.movie.Score*1000) / 1000
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].movie.Score != candidates[j].movie.Score {
+			return candidates[i].movie.Score > candidates[j].movie.Score
+		}
+		return candidates[i].movie.ID < candidates[j].movie.ID
+	})
+
+	recommended := []dto.RecommendedMovie{}
+	for _, c := range candidates {
+		if len(recommended) == limit {
+			break
+		}
+		recommended = append(recommended, c.movie)
+	}
+	return recommended, nil
+}
+
+func (p tasteProfile) affinity(c *recommendCandidate) float64 {
+	score := 0.0
+	for _, id := range c.movie.GenreIDs {
+		score += recommendGenreWeight * p.genres[id]
+	}
+	for _, id := range c.directors {
+		score += recommendDirectorWeight * p.directors[id]
+	}
+	for _, id := range c.actors {
+		score += recommendActorWeight * p.actors[id]
+	}
+	return score
+}
+
+// popularityScore blends recent ticket sales (log-scaled against the best
+// seller) with the average rating, giving a value between 0 and 1.
+func popularityScore(tickets, maxTickets int, rating float64) float64 {
+	sales := 0.0
+	if maxTickets > 0 {
+		sales = math.Log1p(float64(tickets)) / math.Log1p(float64(maxTickets))
+	}
+	return 0.7*sales + 0.3*(rating/5)
+}
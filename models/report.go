@@ -0,0 +1,253 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// salesDimensions maps a report groupBy value to the key it groups on and the
+// label shown for it. Columns refer to the paid CTE built in salesReportSQL;
+// labels are either aggregates or the grouped expression itself.
+var salesDimensions = map[string]struct{ key, label string }{
+	"movie":          {key: "id_movie::text", label: "MIN(movie_title)"},
+	"cinema":         {key: "LOWER(cinema) || '|' || LOWER(location)", label: "MIN(cinema) || ', ' || MIN(location)"},
+	"day":            {key: "TO_CHAR(created_at, 'YYYY-MM-DD')", label: "TO_CHAR(created_at, 'YYYY-MM-DD')"},
+	"payment-method": {key: "COALESCE(payment_method::text, '')", label: "COALESCE(MIN(payment_name), 'Unknown')"},
+	"time-slot":      {key: "TO_CHAR(show_time, 'HH24') || ':00'", label: "TO_CHAR(show_time, 'HH24') || ':00'"},
+}
+
+// SalesGroupings lists the accepted groupBy values.
+func SalesGroupings() []string {
+	return []string{"movie", "cinema", "day", "payment-method", "time-slot"}
+}
+
+// paidTransactionsSQL selects paid transactions booked between $1 and $2
+// (inclusive dates) with their ticket count.
+const paidTransactionsSQL = `
+    SELECT t.id, t.id_user, t.id_movie, m.title AS movie_title, t.location, t.cinema,
+      t.show_date, t.show_time, t.payment_method, pm.payment_name, t.total_price,
+      t.discount_amount + t.points_discount AS discounts, t.created_at,
+      (SELECT COUNT(*) FROM transaction_details td WHERE td.transaction_id = t.id) AS tickets
+    FROM transactions t
+    JOIN movies m ON m.id = t.id_movie
+    LEFT JOIN payment_method pm ON pm.id = t.payment_method
+    WHERE t.status = 'paid'
+      AND t.created_at >= $1::date
+      AND t.created_at < $2::date + 1
+  `
+
+func salesReportSQL(groupBy string) (string, error) {
+	dim, ok := salesDimensions[groupBy]
+	if !ok {
+		return "", fmt.Errorf("unknown groupBy %q", groupBy)
+	}
+
+	return `
+    WITH paid AS (` + paidTransactionsSQL + `)
+    SELECT ` + dim.key + ` AS key, ` + dim.label + ` AS label,
+      COUNT(*) AS transactions,
+      COALESCE(SUM(tickets), 0)::bigint AS tickets,
+      COALESCE(SUM(total_price), 0)::bigint AS revenue,
+      COALESCE(SUM(discounts), 0)::bigint AS discounts
+    FROM paid
+    GROUP BY 1
+    ORDER BY ` + salesReportOrder(groupBy), nil
+}
+
+func salesReportOrder(groupBy string) string {
+	if groupBy == "day" || groupBy == "time-slot" {
+		return "key ASC"
+	}
+	return "revenue DESC, key ASC"
+}
+
+func GetSalesReport(groupBy string, from, to time.Time) (dto.SalesReport, error) {
+	report := dto.SalesReport{
+		GroupBy: groupBy,
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		Rows:    []dto.SalesReportRow{},
+		Totals:  dto.SalesReportRow{Key: "total", Label: "Total"},
+	}
+
+	query, err := salesReportSQL(groupBy)
+	if err != nil {
+		return report, err
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return report, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), query, from, to)
+	if err != nil {
+		return report, fmt.Errorf("failed to build sales report: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r dto.SalesReportRow
+		if err := rows.Scan(&r.Key, &r.Label, &r.Transactions, &r.Tickets, &r.Revenue, &r.Discounts); err != nil {
+			return report, err
+		}
+		report.Rows = append(report.Rows, r)
+		report.Totals.Transactions += r.Transactions
+		report.Totals.Tickets += r.Tickets
+		report.Totals.Revenue += r.Revenue
+		report.Totals.Discounts += r.Discounts
+	}
+
+	return report, rows.Err()
+}
+
+var occupancyListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":       {Column: "id", Cast: "int"},
+		"showDate": {Column: "show_date", Cast: "date"},
+		"rate":     {Column: "rate", Cast: "float8"},
+	},
+	DefaultSort:   "showDate",
+	SearchColumns: []string{"movie_title"},
+	Filters: map[string]filterColumn{
+		"movieId":  {Column: "id_movie", Kind: filterInt},
+		"location": {Column: "location"},
+		"cinema":   {Column: "cinema"},
+	},
+}
+
+// occupancySQL selects showtimes on dates between $1 and $2 with the seats
+// sold on live (not cancelled or refunded) bookings for the same slot.
+const occupancySQL = `
+    SELECT s.id, s.id_movie, m.title AS movie_title, s.location, s.cinema,
+      s.show_date, s.show_time, s.capacity, sold.seats AS seats_sold,
+      ROUND(sold.seats::numeric / s.capacity, 4)::float8 AS rate
+    FROM showtimes s
+    JOIN movies m ON m.id = s.id_movie
+    CROSS JOIN LATERAL (
+      SELECT COUNT(td.id) AS seats
+      FROM transactions t
+      JOIN transaction_details td ON td.transaction_id = t.id
+      WHERE t.id_movie = s.id_movie
+        AND t.show_date = s.show_date
+        AND t.show_time = s.show_time
+        AND LOWER(t.location) = LOWER(s.location)
+        AND LOWER(t.cinema) = LOWER(s.cinema)
+        AND t.status NOT IN ('cancelled', 'refunded')
+    ) sold
+    WHERE s.show_date BETWEEN $1 AND $2
+  `
+
+func scanOccupancyRow(scan func(dest ...any) error) (dto.OccupancyRow, error) {
+	var r dto.OccupancyRow
+	var showDate, showTime time.Time
+	err := scan(&r.ShowtimeID, &r.MovieID, &r.MovieTitle, &r.Location, &r.Cinema,
+		&showDate, &showTime, &r.Capacity, &r.SeatsSold, &r.Rate)
+	r.ShowDate = showDate.Format("2006-01-02")
+	r.ShowTime = showTime.Format("15:04")
+	return r, err
+}
+
+func GetOccupancyReport(from, to time.Time, q utils.PageQuery) ([]dto.OccupancyRow, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, occupancySQL, []any{from, to}, q, occupancyListSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	result := []dto.OccupancyRow{}
+	for rows.Next() {
+		r, err := scanOccupancyRow(rows.Scan)
+		if err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		result = append(result, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := occupancyListSpec.sortKey(q)
+	result, nextCursor := trimPage(result, q, func(r dto.OccupancyRow) (string, int) {
+		switch key {
+		case "showDate":
+			return r.ShowDate, r.ShowtimeID
+		case "rate":
+			return strconv.FormatFloat(r.Rate, 'f', -1, 64), r.ShowtimeID
+		}
+		return strconv.Itoa(r.ShowtimeID), r.ShowtimeID
+	})
+
+	return result, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
+
+// OccupancyExportColumns are the header cells of an occupancy export.
+var OccupancyExportColumns = []any{
+	"Showtime ID", "Movie ID", "Movie", "Location", "Cinema", "Show Date",
+	"Show Time", "Capacity", "Seats Sold", "Occupancy Rate",
+}
+
+// ExportOccupancyReport streams the occupancy of every showtime between from
+// and to that matches the search and filters of q.
+func ExportOccupancyReport(from, to time.Time, q utils.PageQuery, write func(values ...any) error) error {
+	return streamList(occupancySQL, []any{from, to}, q, occupancyListSpec, func(rows pgx.Rows) error {
+		r, err := scanOccupancyRow(rows.Scan)
+		if err != nil {
+			return err
+		}
+		return write(r.ShowtimeID, r.MovieID, r.MovieTitle, r.Location, r.Cinema,
+			r.ShowDate, r.ShowTime, r.Capacity, r.SeatsSold, r.Rate)
+	})
+}
+
+// topCustomersSQL ranks users by what they paid between $1 and $2, limited
+// to $3 rows.
+const topCustomersSQL = `
+    WITH paid AS (` + paidTransactionsSQL + `)
+    SELECT u.id, u.email, COALESCE(u.full_name, ''),
+      COUNT(*) AS transactions,
+      COALESCE(SUM(p.tickets), 0)::bigint AS tickets,
+      COALESCE(SUM(p.total_price), 0)::bigint AS spend
+    FROM paid p
+    JOIN users u ON u.id = p.id_user
+    GROUP BY u.id, u.email, u.full_name
+    ORDER BY spend DESC, u.id ASC
+    LIMIT $3
+  `
+
+func GetTopCustomers(from, to time.Time, limit int) ([]dto.TopCustomer, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), topCustomersSQL, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build top customers report: %v", err)
+	}
+	defer rows.Close()
+
+	customers := []dto.TopCustomer{}
+	for rows.Next() {
+		var c dto.TopCustomer
+		if err := rows.Scan(&c.UserID, &c.Email, &c.FullName, &c.Transactions, &c.Tickets, &c.Spend); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, rows.Err()
+}
@@ -0,0 +1,303 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrReviewNotFound    = errors.New("review not found")
+	ErrReviewExists      = errors.New("you have already reviewed this movie")
+	ErrNotVerifiedViewer = errors.New("only viewers with a completed booking for this movie can review it")
+)
+
+var reviewListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":        {Column: "id", Cast: "int"},
+		"createdAt": {Column: "created_at", Cast: "timestamp"},
+		"rating":    {Column: "rating", Cast: "int"},
+	},
+	DefaultSort:   "createdAt",
+	DefaultDesc:   true,
+	SearchColumns: []string{"review"},
+	Filters: map[string]filterColumn{
+		"status":  {Column: "status", Kind: filterCode},
+		"rating":  {Column: "rating", Kind: filterInt},
+		"movieId": {Column: "id_movie", Kind: filterInt},
+	},
+}
+
+const reviewSelect = `
+    SELECT r.id, r.id_movie, r.id_user, COALESCE(u.full_name, '') AS user_name,
+      r.rating, r.review, r.status, COALESCE(r.moderation_note, '') AS moderation_note,
+      r.created_at, r.updated_at
+    FROM reviews r
+    JOIN users u ON u.id = r.id_user
+  `
+
+// hasCompletedBooking reports whether the user has a paid booking for the
+// movie whose show has already started.
+func hasCompletedBooking(conn *pgxpool.Conn, userID, movieID int) (bool, error) {
+	var ok bool
+	err := conn.QueryRow(context.Background(), `
+    SELECT EXISTS (
+      SELECT 1 FROM transactions
+      WHERE id_user = $1 AND id_movie = $2 AND status = 'paid'
+        AND (show_date + show_time) <= LOCALTIMESTAMP
+    )
+  `, userID, movieID).Scan(&ok)
+	return ok, err
+}
+
+func CreateReview(userID, movieID int, input dto.CreateReviewRequest) (dto.Review, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Review{}, err
+	}
+	defer conn.Release()
+
+	if err := requireLive(conn, "movie", movieID, ErrMovieNotFound); err != nil {
+		return dto.Review{}, err
+	}
+
+	verified, err := hasCompletedBooking(conn, userID, movieID)
+	if err != nil {
+		return dto.Review{}, fmt.Errorf("failed to check bookings: %v", err)
+	}
+	if !verified {
+		return dto.Review{}, ErrNotVerifiedViewer
+	}
+
+	var id int
+	err = conn.QueryRow(context.Background(), `
+    INSERT INTO reviews (id_user, id_movie, rating, review)
+    VALUES ($1, $2, $3, $4)
+    ON CONFLICT (id_user, id_movie) DO NOTHING
+    RETURNING id
+  `, userID, movieID, input.Rating, input.Review).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.Review{}, ErrReviewExists
+	}
+	if err != nil {
+		return dto.Review{}, fmt.Errorf("failed to create review: %v", err)
+	}
+
+	invalidateCache(MovieCacheTag(movieID), CacheTagReviews)
+	return getReview(conn, id)
+}
+
+func getReview(conn *pgxpool.Conn, id int) (dto.Review, error) {
+	rows, err := conn.Query(context.Background(), reviewSelect+` WHERE r.id = $1`, id)
+	if err != nil {
+		return dto.Review{}, err
+	}
+	reviews, err := scanReviews(rows)
+	if err != nil {
+		return dto.Review{}, err
+	}
+	if len(reviews) == 0 {
+		return dto.Review{}, ErrReviewNotFound
+	}
+	return reviews[0], nil
+}
+
+func scanReviews(rows pgx.Rows) ([]dto.Review, error) {
+	defer rows.Close()
+
+	var reviews []dto.Review
+	for rows.Next() {
+		var r dto.Review
+		if err := rows.Scan(
+			&r.ID,
+			&r.MovieID,
+			&r.UserID,
+			&r.UserName,
+			&r.Rating,
+			&r.Review,
+			&r.Status,
+			&r.ModerationNote,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+// GetMovieReviews lists the public (not hidden) reviews of a movie, which
+// must not be deleted.
+func GetMovieReviews(movieID int, q utils.PageQuery) ([]dto.Review, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	err = requireLive(conn, "movie", movieID, ErrMovieNotFound)
+	conn.Release()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	return listReviews(reviewSelect+`
+    WHERE r.id_movie = $1 AND r.status <> 'hidden'
+  `, []any{movieID}, q)
+}
+
+// GetAllReviews lists every review for moderation, including hidden ones.
+func GetAllReviews(q utils.PageQuery) ([]dto.Review, utils.PageResult, error) {
+	return listReviews(reviewSelect, nil, q)
+}
+
+func listReviews(base string, params []any, q utils.PageQuery) ([]dto.Review, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, base, params, q, reviewListSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	reviews, err := scanReviews(rows)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := reviewListSpec.sortKey(q)
+	reviews, nextCursor := trimPage(reviews, q, func(r dto.Review) (string, int) {
+		switch key {
+		case "createdAt":
+			return r.CreatedAt.Format("2006-01-02 15:04:05.999999"), r.ID
+		case "rating":
+			return strconv.Itoa(r.Rating), r.ID
+		}
+		return strconv.Itoa(r.ID), r.ID
+	})
+
+	return reviews, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
+
+// UpdateReview edits a review owned by userID.
+func UpdateReview(userID, reviewID int, input dto.UpdateReviewRequest) (dto.Review, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Review{}, err
+	}
+	defer conn.Release()
+
+	var movieID int
+	err = conn.QueryRow(context.Background(), `
+    UPDATE reviews SET
+      rating = COALESCE($1, rating),
+      review = COALESCE($2, review),
+      updated_at = NOW()
+    WHERE id = $3 AND id_user = $4
+    RETURNING id_movie
+  `, input.Rating, input.Review, reviewID, userID).Scan(&movieID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.Review{}, ErrReviewNotFound
+	}
+	if err != nil {
+		return dto.Review{}, fmt.Errorf("failed to update review: %v", err)
+	}
+
+	invalidateCache(MovieCacheTag(movieID), CacheTagReviews)
+	return getReview(conn, reviewID)
+}
+
+// DeleteReview removes a review owned by userID.
+func DeleteReview(userID, reviewID int) error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	var movieID int
+	err = conn.QueryRow(context.Background(), `
+    DELETE FROM reviews WHERE id = $1 AND id_user = $2
+    RETURNING id_movie
+  `, reviewID, userID).Scan(&movieID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrReviewNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete review: %v", err)
+	}
+
+	invalidateCache(MovieCacheTag(movieID), CacheTagReviews)
+	return nil
+}
+
+func ModerateReview(admin dto.AuditActor, reviewID int, input dto.ModerateReviewRequest) (dto.Review, error) {
+	var movieID int
+	_, err := withAudit(admin, "moderate", "review", reviewID, func(tx pgx.Tx) (int, error) {
+		err := tx.QueryRow(context.Background(), `
+      UPDATE reviews SET
+        status = $1,
+        moderation_note = NULLIF($2, ''),
+        moderated_at = NOW()
+      WHERE id = $3
+      RETURNING id_movie
+    `, input.Status, input.Note, reviewID).Scan(&movieID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrReviewNotFound
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to moderate review: %v", err)
+		}
+		return reviewID, nil
+	})
+	if err != nil {
+		return dto.Review{}, err
+	}
+
+	invalidateCache(MovieCacheTag(movieID), CacheTagReviews)
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Review{}, err
+	}
+	defer conn.Release()
+	return getReview(conn, reviewID)
+}
+
+// ratingSummaries returns the rating of each movie in movieIDs that has at
+// least one public review.
+func ratingSummaries(conn *pgxpool.Conn, movieIDs []int) (map[int]dto.RatingSummary, error) {
+	summaries := map[int]dto.RatingSummary{}
+	if len(movieIDs) == 0 {
+		return summaries, nil
+	}
+
+	rows, err := conn.Query(context.Background(), `
+    SELECT id_movie, ROUND(AVG(rating)::numeric, 1)::float8, COUNT(*)
+    FROM reviews
+    WHERE id_movie = ANY($1) AND status <> 'hidden'
+    GROUP BY id_movie
+  `, movieIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var s dto.RatingSummary
+		if err := rows.Scan(&id, &s.Average, &s.Count); err != nil {
+			return nil, err
+		}
+		summaries[id] = s
+	}
+	return summaries, rows.Err()
+}
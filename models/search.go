@@ -0,0 +1,132 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ts_headline marks matches with control characters rather than <mark>, as
+// it copies the rest of the text as is. highlightHTML escapes the text and
+// only then turns the markers into tags.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+
+	titleHeadlineOptions = `'StartSel="` + headlineStart + `", StopSel="` + headlineStop + `", HighlightAll=true'`
+	headlineOptions      = `'StartSel="` + headlineStart + `", StopSel="` + headlineStop + `", MaxFragments=2, MaxWords=25, MinWords=10'`
+)
+
+var headlineTags = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// highlightHTML turns a ts_headline result into HTML where only the <mark>
+// tags around matches are markup.
+func highlightHTML(headline string) string {
+	return headlineTags.Replace(html.EscapeString(headline))
+}
+
+// movieTSQuery is the full-text query for the search term bound to $n. Names
+// are indexed with the simple config and descriptions with english, so both
+// parsers are tried.
+func movieTSQuery(idx int) string {
+	return strings.ReplaceAll(`(websearch_to_tsquery('simple', $n) || websearch_to_tsquery('english', $n))`, "$n", fmt.Sprintf("$%d", idx))
+}
+
+// movieMatchSQL matches movies (aliased m) against the search term bound to
+// $n, either through the tsvector or through trigram similarity on the title
+// so that small typos still find the movie.
+func movieMatchSQL(idx int) string {
+	return strings.ReplaceAll(`(
+      m.search_vector @@ `+movieTSQuery(idx)+`
+      OR m.title % $n
+      OR $n <% m.title
+    )`, "$n", fmt.Sprintf("$%d", idx))
+}
+
+// movieRankSQL scores a match for ORDER BY; title similarity breaks ties and
+// ranks fuzzy-only matches.
+func movieRankSQL(idx int) string {
+	return strings.ReplaceAll(`(ts_rank_cd(m.search_vector, `+movieTSQuery(idx)+`) + word_similarity($n, m.title))`, "$n", fmt.Sprintf("$%d", idx))
+}
+
+func SearchCatalog(search string, limit int) (dto.SearchResults, error) {
+	results := dto.SearchResults{
+		Movies:    []dto.MovieSearchResult{},
+		Actors:    []dto.PersonSearchResult{},
+		Directors: []dto.PersonSearchResult{},
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return results, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), `
+    SELECT m.id, m.title,
+           ts_headline('simple', m.title, `+movieTSQuery(1)+`, `+titleHeadlineOptions+`),
+           ts_headline('english', COALESCE(m.description, ''), `+movieTSQuery(1)+`, `+headlineOptions+`),
+           m.release_date, COALESCE(m.image, ''),
+           `+movieRankSQL(1)+` AS rank
+    FROM movies m
+    WHERE m.deleted_at IS NULL AND `+movieMatchSQL(1)+`
+    ORDER BY rank DESC, m.release_date DESC
+    LIMIT $2
+  `, search, limit)
+	if err != nil {
+		return results, err
+	}
+	for rows.Next() {
+		var m dto.MovieSearchResult
+		if err := rows.Scan(&m.ID, &m.Title, &m.TitleHighlight, &m.Snippet, &m.ReleaseDate, &m.Image, &m.Rank); err != nil {
+			rows.Close()
+			return results, err
+		}
+		m.TitleHighlight = highlightHTML(m.TitleHighlight)
+		m.Snippet = highlightHTML(m.Snippet)
+		results.Movies = append(results.Movies, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return results, err
+	}
+
+	results.Actors, err = searchPeople(conn, "actors", "actor_name", search, limit)
+	if err != nil {
+		return results, err
+	}
+
+	results.Directors, err = searchPeople(conn, "directors", "director_name", search, limit)
+	return results, err
+}
+
+// searchPeople looks up actors or directors by name with trigram similarity.
+func searchPeople(conn *pgxpool.Conn, table, column, search string, limit int) ([]dto.PersonSearchResult, error) {
+	rows, err := conn.Query(context.Background(), fmt.Sprintf(`
+    SELECT id, %[2]s, word_similarity($1, %[2]s) AS score
+    FROM %[1]s
+    WHERE deleted_at IS NULL AND (%[2]s %% $1 OR $1 <%% %[2]s OR %[2]s ILIKE '%%' || $1 || '%%')
+    ORDER BY score DESC, %[2]s ASC
+    LIMIT $2
+  `, table, column), search, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	people := []dto.PersonSearchResult{}
+	for rows.Next() {
+		var p dto.PersonSearchResult
+		if err := rows.Scan(&p.ID, &p.Name, &p.Score); err != nil {
+			return nil, err
+		}
+		people = append(people, p)
+	}
+
+	return people, rows.Err()
+}
@@ -0,0 +1,11 @@
+package models
+
+import "testing"
+
+func TestHighlightHTML(t *testing.T) {
+	headline := `Tom & <script>alert(1)</script> in ` + headlineStart + `Dune` + headlineStop + `: "Part Two"`
+	want := `Tom &amp; &lt;script&gt;alert(1)&lt;/script&gt; in <mark>Dune</mark>: &#34;Part Two&#34;`
+	if got := highlightHTML(headline); got != want {
+		t.Errorf("highlightHTML() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,357 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrShowtimeNotFound = errors.New("showtime not found")
+	// ErrSeatsHeld is wrapped with the seats another customer is holding.
+	ErrSeatsHeld = errors.New("seats are held by another customer")
+)
+
+// Seat stream event types.
+const (
+	SeatTaken    = "seat-taken"
+	SeatHeld     = "seat-held"
+	SeatReleased = "seat-released"
+)
+
+// showtimeSlotSQL matches transactions (aliased t) booked for the showtime s.
+// Transactions store the slot rather than the showtime ID.
+const showtimeSlotSQL = `
+        t.id_movie = s.id_movie
+        AND t.show_date = s.show_date
+        AND t.show_time = s.show_time
+        AND LOWER(t.location) = LOWER(s.location)
+        AND LOWER(t.cinema) = LOWER(s.cinema)`
+
+// SeatHoldDuration is how long a seat stays held for a customer before it is
+// released again, configured with SEAT_HOLD_MINUTES.
+func SeatHoldDuration() time.Duration {
+	return time.Duration(max(utils.GetEnvInt("SEAT_HOLD_MINUTES", 10), 1)) * time.Minute
+}
+
+func seatChannel(showtimeID int) string {
+	return fmt.Sprintf("seats:%d", showtimeID)
+}
+
+// publishSeatEvent tells everyone watching the showtime's seat stream that
+// seats changed. It is called after the change is committed.
+func publishSeatEvent(showtimeID int, eventType string, seats []string, expiresAt *time.Time) {
+	if showtimeID == 0 || len(seats) == 0 {
+		return
+	}
+	payload, err := json.Marshal(dto.SeatEvent{
+		Type:       eventType,
+		ShowtimeID: showtimeID,
+		Seats:      seats,
+		ExpiresAt:  expiresAt,
+		At:         time.Now(),
+	})
+	if err != nil {
+		log.Println("Failed to encode seat event:", err.Error())
+		return
+	}
+	utils.Publish(context.Background(), seatChannel(showtimeID), payload)
+}
+
+// SubscribeSeats returns the seat events of a showtime, encoded as
+// dto.SeatEvent, and a function ending the subscription. The channel is
+// closed when the subscriber falls too far behind.
+func SubscribeSeats(showtimeID int) (<-chan []byte, func()) {
+	return utils.Subscribe(seatChannel(showtimeID))
+}
+
+// parseShowSlot parses the date and time of a booking slot; the time may
+// leave out the seconds.
+func parseShowSlot(date, clock string) (time.Time, time.Time, error) {
+	showDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date format: %v", err)
+	}
+	showTime, err := time.Parse("15:04:05", clock)
+	if err != nil {
+		showTime, err = time.Parse("15:04", clock)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid time format: %v", err)
+		}
+	}
+	return showDate, showTime, nil
+}
+
+// findShowtime returns the ID and seat price of the showtime for a booking
+// slot, or 0 when the slot is not scheduled.
+func findShowtime(db querier, movieID int, showDate, showTime time.Time, location, cinema string) (int, int, error) {
+	var id, price int
+	err := db.QueryRow(context.Background(), `
+    SELECT id, price FROM showtimes
+    WHERE id_movie = $1 AND show_date = $2 AND show_time = $3
+      AND LOWER(location) = LOWER($4) AND LOWER(cinema) = LOWER($5)
+  `, movieID, showDate, showTime, location, cinema).Scan(&id, &price)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find showtime: %v", err)
+	}
+	return id, price, nil
+}
+
+// transactionShowtimeSeats returns the showtime of a transaction, 0 when its
+// slot is not scheduled, and its seats.
+func transactionShowtimeSeats(db querier, transactionID int) (int, []string, error) {
+	var showtimeID int
+	var seats []string
+	err := db.QueryRow(context.Background(), `
+    SELECT COALESCE((SELECT s.id FROM showtimes s WHERE`+showtimeSlotSQL+`), 0),
+      COALESCE((SELECT ARRAY_AGG(td.seat ORDER BY td.seat) FROM transaction_details td
+        WHERE td.transaction_id = t.id), '{}')
+    FROM transactions t
+    WHERE t.id = $1
+  `, transactionID).Scan(&showtimeID, &seats)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to load seats of transaction %d: %v", transactionID, err)
+	}
+	return showtimeID, seats, nil
+}
+
+// claimHeldSeats makes sure none of the seats is held by someone else, then
+// drops the customer's own holds on them as they are being booked.
+func claimHeldSeats(db querier, showtimeID, userID int, seats []string) error {
+	rows, err := db.Query(context.Background(), `
+    SELECT seat FROM seat_holds
+    WHERE id_showtime = $1 AND seat = ANY($2) AND id_user <> $3 AND expires_at > NOW()
+    ORDER BY seat
+  `, showtimeID, seats, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check seat holds: %v", err)
+	}
+	held, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to check seat holds: %v", err)
+	}
+	if len(held) > 0 {
+		return fmt.Errorf("%w: %s", ErrSeatsHeld, strings.Join(held, ", "))
+	}
+
+	_, err = db.Exec(context.Background(), `
+    DELETE FROM seat_holds WHERE id_showtime = $1 AND seat = ANY($2)
+  `, showtimeID, seats)
+	if err != nil {
+		return fmt.Errorf("failed to clear seat holds: %v", err)
+	}
+	return nil
+}
+
+// GetSeatSnapshot returns the seats of a showtime that are booked and the ones
+// currently held.
+func GetSeatSnapshot(showtimeID int) (dto.SeatSnapshot, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.SeatSnapshot{}, err
+	}
+	defer conn.Release()
+
+	snapshot := dto.SeatSnapshot{ShowtimeID: showtimeID}
+	err = conn.QueryRow(context.Background(), `
+    SELECT s.capacity,
+      COALESCE((
+        SELECT ARRAY_AGG(td.seat ORDER BY td.seat)
+        FROM transactions t
+        JOIN transaction_details td ON td.transaction_id = t.id
+        WHERE`+showtimeSlotSQL+`
+          AND t.status NOT IN ('cancelled', 'refunded')
+      ), '{}'),
+      COALESCE((
+        SELECT ARRAY_AGG(h.seat ORDER BY h.seat)
+        FROM seat_holds h
+        WHERE h.id_showtime = s.id AND h.expires_at > NOW()
+      ), '{}')
+    FROM showtimes s
+    WHERE s.id = $1
+  `, showtimeID).Scan(&snapshot.Capacity, &snapshot.Taken, &snapshot.Held)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return snapshot, ErrShowtimeNotFound
+	}
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to load seats: %v", err)
+	}
+	return snapshot, nil
+}
+
+// HoldSeats reserves seats for the customer for SeatHoldDuration, extending
+// holds they already have. Seats that are booked or held by someone else are
+// returned and nothing is held.
+func HoldSeats(showtimeID, userID int, seats []string) (dto.SeatHold, []string, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.SeatHold{}, nil, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return dto.SeatHold{}, nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	seats = slices.Compact(slices.Sorted(slices.Values(seats)))
+
+	var taken []string
+	err = tx.QueryRow(context.Background(), `
+    SELECT COALESCE((
+      SELECT ARRAY_AGG(td.seat ORDER BY td.seat)
+      FROM transactions t
+      JOIN transaction_details td ON td.transaction_id = t.id
+      WHERE`+showtimeSlotSQL+`
+        AND t.status NOT IN ('cancelled', 'refunded')
+        AND td.seat = ANY($2)
+    ), '{}')
+    FROM showtimes s
+    WHERE s.id = $1 AND (s.show_date + s.show_time) > LOCALTIMESTAMP
+  `, showtimeID, seats).Scan(&taken)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.SeatHold{}, nil, ErrShowtimeNotFound
+	}
+	if err != nil {
+		return dto.SeatHold{}, nil, fmt.Errorf("failed to check seats: %v", err)
+	}
+	if len(taken) > 0 {
+		return dto.SeatHold{}, taken, nil
+	}
+
+	// A seat can be taken over from someone else only once their hold expired.
+	rows, err := tx.Query(context.Background(), `
+    INSERT INTO seat_holds (id_showtime, seat, id_user, expires_at)
+    SELECT $1, seat, $3, NOW() + make_interval(secs => $4) FROM UNNEST($2::varchar[]) AS seat
+    ON CONFLICT (id_showtime, seat) DO UPDATE SET
+      id_user = EXCLUDED.id_user,
+      expires_at = EXCLUDED.expires_at,
+      created_at = CASE WHEN seat_holds.id_user = EXCLUDED.id_user THEN seat_holds.created_at ELSE NOW() END
+    WHERE seat_holds.id_user = EXCLUDED.id_user OR seat_holds.expires_at <= NOW()
+    RETURNING seat, expires_at
+  `, showtimeID, seats, userID, SeatHoldDuration().Seconds())
+	if err != nil {
+		return dto.SeatHold{}, nil, fmt.Errorf("failed to hold seats: %v", err)
+	}
+	var held []string
+	var expiresAt time.Time
+	for rows.Next() {
+		var seat string
+		if err := rows.Scan(&seat, &expiresAt); err != nil {
+			rows.Close()
+			return dto.SeatHold{}, nil, err
+		}
+		held = append(held, seat)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return dto.SeatHold{}, nil, fmt.Errorf("failed to hold seats: %v", err)
+	}
+
+	var conflicts []string
+	for _, seat := range seats {
+		if !slices.Contains(held, seat) {
+			conflicts = append(conflicts, seat)
+		}
+	}
+	if len(conflicts) > 0 {
+		return dto.SeatHold{}, conflicts, nil
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return dto.SeatHold{}, nil, fmt.Errorf("commit failed: %v", err)
+	}
+
+	publishSeatEvent(showtimeID, SeatHeld, seats, &expiresAt)
+	return dto.SeatHold{ShowtimeID: showtimeID, Seats: seats, ExpiresAt: expiresAt}, nil, nil
+}
+
+// ReleaseSeats drops the customer's holds on a showtime, only on the given
+// seats when there are any. It returns the seats released.
+func ReleaseSeats(showtimeID, userID int, seats []string) ([]string, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), `
+    DELETE FROM seat_holds
+    WHERE id_showtime = $1 AND id_user = $2
+      AND (COALESCE(cardinality($3::varchar[]), 0) = 0 OR seat = ANY($3))
+    RETURNING seat
+  `, showtimeID, userID, seats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %v", err)
+	}
+	released, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to release seats: %v", err)
+	}
+
+	slices.Sort(released)
+	publishSeatEvent(showtimeID, SeatReleased, released, nil)
+	return released, nil
+}
+
+// StartSeatHoldSweeper releases expired seat holds in the background every
+// SEAT_HOLD_SWEEP_SECONDS, so seat streams hear about them.
+func StartSeatHoldSweeper() {
+	interval := time.Duration(max(utils.GetEnvInt("SEAT_HOLD_SWEEP_SECONDS", 15), 1)) * time.Second
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := releaseExpiredHolds(); err != nil {
+				log.Println("Seat hold sweeper:", err.Error())
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// releaseExpiredHolds deletes expired holds and announces the seats as
+// released. Each hold is deleted by exactly one instance, so it is announced
+// once.
+func releaseExpiredHolds() error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), `
+    WITH expired AS (
+      DELETE FROM seat_holds WHERE expires_at <= NOW()
+      RETURNING id_showtime, seat
+    )
+    SELECT id_showtime, ARRAY_AGG(seat ORDER BY seat) FROM expired GROUP BY id_showtime
+  `)
+	if err != nil {
+		return fmt.Errorf("failed to release expired holds: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var showtimeID int
+		var seats []string
+		if err := rows.Scan(&showtimeID, &seats); err != nil {
+			return err
+		}
+		publishSeatEvent(showtimeID, SeatReleased, seats, nil)
+	}
+	return rows.Err()
+}
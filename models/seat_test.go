@@ -0,0 +1,44 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSeatEventsReachSubscribers(t *testing.T) {
+	// Redis is unreachable, so events are handed out by this process alone.
+	t.Setenv("RDADDRESS", "127.0.0.1:1")
+
+	events, stop := SubscribeSeats(42)
+	defer stop()
+
+	expiresAt := time.Now().Add(SeatHoldDuration()).Truncate(time.Second)
+	publishSeatEvent(42, SeatHeld, []string{"A1", "A2"}, &expiresAt)
+	publishSeatEvent(43, SeatTaken, []string{"B1"}, nil)
+	publishSeatEvent(42, SeatReleased, nil, nil)
+
+	select {
+	case payload := <-events:
+		var event dto.SeatEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Type != SeatHeld || event.ShowtimeID != 42 || !slices.Equal(event.Seats, []string{"A1", "A2"}) {
+			t.Fatalf("event = %+v, want A1 and A2 held on showtime 42", event)
+		}
+		if event.ExpiresAt == nil || !event.ExpiresAt.Equal(expiresAt) {
+			t.Fatalf("expiresAt = %v, want %v", event.ExpiresAt, expiresAt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the seat event")
+	}
+
+	select {
+	case payload := <-events:
+		t.Fatalf("got %s; events of other showtimes and empty events should not be sent", payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidShowDate is returned for a show date that is not YYYY-MM-DD.
+var ErrInvalidShowDate = errors.New("invalid date format")
+
+// NowShowingWindowDays is how far ahead a showtime may be and still count
+// the movie as "now showing". Configured with NOW_SHOWING_WINDOW_DAYS.
+func NowShowingWindowDays() int {
+	return utils.GetEnvInt("NOW_SHOWING_WINDOW_DAYS", 7)
+}
+
+// showtimeFilterSQL appends the city/cinema conditions for the showtimes
+// alias s and returns the extended params.
+func showtimeFilterSQL(filter dto.ShowtimeFilter, params []any) (string, []any) {
+	clause := ""
+	if filter.Location != "" {
+		params = append(params, filter.Location)
+		clause += fmt.Sprintf(` AND LOWER(s.location) = LOWER($%d)`, len(params))
+	}
+	if filter.Cinema != "" {
+		params = append(params, filter.Cinema)
+		clause += fmt.Sprintf(` AND LOWER(s.cinema) = LOWER($%d)`, len(params))
+	}
+	return clause, params
+}
+
+// nowShowingSQL matches movies (aliased m) with at least one future showtime
+// inside the run window.
+func nowShowingSQL(filter dto.ShowtimeFilter, params []any) (string, []any) {
+	params = append(params, NowShowingWindowDays())
+	windowIdx := len(params)
+	filterSQL, params := showtimeFilterSQL(filter, params)
+	return fmt.Sprintf(`EXISTS (
+      SELECT 1 FROM showtimes s
+      WHERE s.id_movie = m.id
+        AND (s.show_date + s.show_time) >= LOCALTIMESTAMP
+        AND s.show_date <= CURRENT_DATE + $%d::int%s
+    )`, windowIdx, filterSQL), params
+}
+
+// upcomingSQL matches movies that are not bookable inside the run window but
+// either have showtimes scheduled after it or have not been released yet.
+// When a city or cinema is given only scheduled movies qualify.
+func upcomingSQL(filter dto.ShowtimeFilter, params []any) (string, []any) {
+	nowShowing, params := nowShowingSQL(filter, params)
+	windowIdx := len(params) + 1
+	params = append(params, NowShowingWindowDays())
+	filterSQL, params := showtimeFilterSQL(filter, params)
+
+	scheduled := fmt.Sprintf(`EXISTS (
+      SELECT 1 FROM showtimes s
+      WHERE s.id_movie = m.id
+        AND s.show_date > CURRENT_DATE + $%d::int%s
+    )`, windowIdx, filterSQL)
+
+	if filter.Location == "" && filter.Cinema == "" {
+		return fmt.Sprintf(`NOT %s AND (m.release_date > CURRENT_DATE OR %s)`, nowShowing, scheduled), params
+	}
+	return fmt.Sprintf(`NOT %s AND %s`, nowShowing, scheduled), params
+}
+
+func CreateShowtime(admin dto.AuditActor, input dto.CreateShowtimeRequest) (dto.Showtime, error) {
+	showDate, err := time.Parse("2006-01-02", input.ShowDate)
+	if err != nil {
+		return dto.Showtime{}, fmt.Errorf("invalid date format: %v", err)
+	}
+
+	showTime, err := time.Parse("15:04:05", input.ShowTime)
+	if err != nil {
+		showTime, err = time.Parse("15:04", input.ShowTime)
+		if err != nil {
+			return dto.Showtime{}, fmt.Errorf("invalid time format: %v", err)
+		}
+	}
+
+	var st dto.Showtime
+	var date, clock time.Time
+	_, err = withAudit(admin, "create", "showtime", 0, func(tx pgx.Tx) (int, error) {
+		if err := requireLive(tx, "movie", input.MovieID, ErrMovieNotFound); err != nil {
+			return 0, err
+		}
+		err := tx.QueryRow(context.Background(), `
+      INSERT INTO showtimes (id_movie, location, cinema, show_date, show_time, price, capacity)
+      VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7::int, 0), 100))
+      RETURNING id, id_movie, location, cinema, show_date, show_time, price, capacity
+    `, input.MovieID, input.Location, input.Cinema, showDate, showTime, input.Price, input.Capacity).Scan(
+			&st.ID,
+			&st.MovieID,
+			&st.Location,
+			&st.Cinema,
+			&date,
+			&clock,
+			&st.Price,
+			&st.Capacity,
+		)
+		return st.ID, err
+	})
+	if err != nil {
+		return dto.Showtime{}, err
+	}
+
+	st.ShowDate = date.Format("2006-01-02")
+	st.ShowTime = clock.Format("15:04")
+
+	invalidateCache(CacheTagShowtimes)
+
+	return st, nil
+}
+
+func GetShowtimes(movieID int, date string, filter dto.ShowtimeFilter) ([]dto.Showtime, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	query := `
+    SELECT s.id, s.id_movie, s.location, s.cinema, s.show_date, s.show_time, s.price, s.capacity
+    FROM showtimes s
+    JOIN movies m ON m.id = s.id_movie AND m.deleted_at IS NULL
+    WHERE (s.show_date + s.show_time) >= LOCALTIMESTAMP
+  `
+	params := []any{}
+
+	if movieID > 0 {
+		params = append(params, movieID)
+		query += fmt.Sprintf(` AND s.id_movie = $%d`, len(params))
+	}
+
+	if date != "" {
+		showDate, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidShowDate, err)
+		}
+		params = append(params, showDate)
+		query += fmt.Sprintf(` AND s.show_date = $%d`, len(params))
+	}
+
+	filterSQL, params := showtimeFilterSQL(filter, params)
+	query += filterSQL + ` ORDER BY s.show_date ASC, s.show_time ASC, s.cinema ASC`
+
+	rows, err := conn.Query(context.Background(), query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	showtimes := []dto.Showtime{}
+	for rows.Next() {
+		var st dto.Showtime
+		var showDate, showTime time.Time
+		if err := rows.Scan(&st.ID, &st.MovieID, &st.Location, &st.Cinema, &showDate, &showTime, &st.Price, &st.Capacity); err != nil {
+			return nil, err
+		}
+		st.ShowDate = showDate.Format("2006-01-02")
+		st.ShowTime = showTime.Format("15:04")
+		showtimes = append(showtimes, st)
+	}
+
+	return showtimes, rows.Err()
+}
+
+func DeleteShowtime(admin dto.AuditActor, id int) error {
+	_, err := withAudit(admin, "delete", "showtime", id, func(tx pgx.Tx) (int, error) {
+		_, err := tx.Exec(context.Background(), `DELETE FROM showtimes WHERE id = $1`, id)
+		return id, err
+	})
+	if err == nil {
+		invalidateCache(CacheTagShowtimes)
+	}
+	return err
+}
@@ -0,0 +1,294 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The suggestion index is a Redis sorted set where every member has score 0,
+// so ZRANGEBYLEX can answer prefix queries. Each member is
+// "<term>\x00<type>\x00<id>\x00<label>", with one term per word start of the
+// label so "endg" also finds "Avengers: Endgame".
+const (
+	suggestKey     = "suggest:index"
+	suggestSep     = "\x00"
+	suggestScanMax = 200
+	// suggestEmpty is always in the index, so an index built from an empty
+	// catalog still exists and lookups do not keep asking for a rebuild. It
+	// has no separators, so rankSuggestions skips it.
+	suggestEmpty = ""
+)
+
+// suggestRebuild queues a rebuild for the suggestion worker. There is only one
+// worker and a rebuild asked for while one runs is folded into a single
+// follow-up, so an older snapshot never replaces a newer one.
+var (
+	suggestRebuild    = make(chan struct{}, 1)
+	suggestWorkerOnce sync.Once
+)
+
+// suggestMissRebuild and suggestErrorLog limit how often lookups ask for a
+// rebuild of a missing index and log that Redis is unavailable.
+var (
+	suggestMissRebuild = &throttle{every: time.Minute}
+	suggestErrorLog    = &throttle{every: time.Minute}
+)
+
+// throttle lets an action through at most once every interval.
+type throttle struct {
+	mu    sync.Mutex
+	every time.Duration
+	last  time.Time
+}
+
+func (t *throttle) allow(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.last.IsZero() && now.Sub(t.last) < t.every {
+		return false
+	}
+	t.last = now
+	return true
+}
+
+func normalizeSuggestTerm(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func suggestTerms(label string) []string {
+	words := strings.Fields(normalizeSuggestTerm(label))
+	terms := make([]string, 0, len(words))
+	for i := range words {
+		terms = append(terms, strings.Join(words[i:], " "))
+	}
+	return terms
+}
+
+// RefreshSuggestIndex rebuilds the suggestion index from the database and
+// swaps it in atomically.
+func RefreshSuggestIndex() error {
+	ctx := context.Background()
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+    SELECT 'movie', id, title FROM movies WHERE deleted_at IS NULL
+    UNION ALL
+    SELECT 'actor', id, actor_name FROM actors WHERE actor_name IS NOT NULL AND deleted_at IS NULL
+    UNION ALL
+    SELECT 'genre', id, genre_name FROM genres WHERE deleted_at IS NULL
+  `)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	members := []redis.Z{{Member: suggestEmpty}}
+	for rows.Next() {
+		var kind, label string
+		var id int
+		if err := rows.Scan(&kind, &id, &label); err != nil {
+			return err
+		}
+		for _, term := range suggestTerms(label) {
+			members = append(members, redis.Z{
+				Member: term + suggestSep + kind + suggestSep + strconv.Itoa(id) + suggestSep + label,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rdb := utils.RedisClient()
+	tmpKey := suggestKey + ":building"
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, tmpKey)
+	for start := 0; start < len(members); start += 500 {
+		end := min(start+500, len(members))
+		pipe.ZAdd(ctx, tmpKey, members[start:end]...)
+	}
+	pipe.Rename(ctx, tmpKey, suggestKey)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RefreshSuggestIndexAsync rebuilds the index without blocking the caller.
+func RefreshSuggestIndexAsync() {
+	suggestWorkerOnce.Do(func() {
+		go func() {
+			for range suggestRebuild {
+				if err := RefreshSuggestIndex(); err != nil {
+					log.Println("Failed to refresh suggestion index:", err.Error())
+				}
+			}
+		}()
+	})
+	select {
+	case suggestRebuild <- struct{}{}:
+	default:
+	}
+}
+
+// SuggestTimeout is the latency budget for a suggestion lookup, configured
+// with SUGGEST_TIMEOUT_MS.
+func SuggestTimeout() time.Duration {
+	return time.Duration(utils.GetEnvInt("SUGGEST_TIMEOUT_MS", 150)) * time.Millisecond
+}
+
+// Suggest returns up to limit movies, actors and genres whose name has a word
+// starting with q. Redis is tried first; when the index is missing or Redis
+// is unavailable a prefix query against the database is used instead.
+func Suggest(q string, limit int) (dto.Suggestions, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), SuggestTimeout())
+	defer cancel()
+
+	prefix := normalizeSuggestTerm(q)
+	rdb := utils.RedisClient()
+	members, err := rdb.ZRangeByLex(ctx, suggestKey, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: suggestScanMax,
+	}).Result()
+	if err == nil && len(members) > 0 {
+		return rankSuggestions(prefix, members, limit), nil
+	}
+
+	if err == nil {
+		exists, _ := rdb.Exists(ctx, suggestKey).Result()
+		if exists == 1 {
+			return rankSuggestions(prefix, nil, limit), nil
+		}
+		if suggestMissRebuild.allow(time.Now()) {
+			RefreshSuggestIndexAsync()
+		}
+	} else if suggestErrorLog.allow(time.Now()) {
+		log.Println("Suggestion index unavailable:", err.Error())
+	}
+
+	return suggestFromDB(ctx, prefix, limit)
+}
+
+type suggestCandidate struct {
+	kind   string
+	item   dto.SuggestionItem
+	starts bool
+}
+
+// rankSuggestions de-duplicates index members and prefers labels that start
+// with the query, then shorter labels.
+func rankSuggestions(prefix string, members []string, limit int) dto.Suggestions {
+	seen := map[string]bool{}
+	candidates := []suggestCandidate{}
+	for _, member := range members {
+		parts := strings.SplitN(member, suggestSep, 4)
+		if len(parts) != 4 {
+			continue
+		}
+		key := parts[1] + ":" + parts[2]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		id, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, suggestCandidate{
+			kind:   parts[1],
+			item:   dto.SuggestionItem{ID: id, Label: parts[3]},
+			starts: strings.HasPrefix(normalizeSuggestTerm(parts[3]), prefix),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].starts != candidates[j].starts {
+			return candidates[i].starts
+		}
+		return len(candidates[i].item.Label) < len(candidates[j].item.Label)
+	})
+
+	return groupSuggestions(candidates, limit)
+}
+
+func groupSuggestions(candidates []suggestCandidate, limit int) dto.Suggestions {
+	result := dto.Suggestions{
+		Movies: []dto.SuggestionItem{},
+		Actors: []dto.SuggestionItem{},
+		Genres: []dto.SuggestionItem{},
+	}
+	for _, c := range candidates {
+		switch {
+		case c.kind == "movie" && len(result.Movies) < limit:
+			result.Movies = append(result.Movies, c.item)
+		case c.kind == "actor" && len(result.Actors) < limit:
+			result.Actors = append(result.Actors, c.item)
+		case c.kind == "genre" && len(result.Genres) < limit:
+			result.Genres = append(result.Genres, c.item)
+		}
+	}
+	return result
+}
+
+// escapeLike escapes the LIKE wildcards in s, for patterns using ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func suggestFromDB(ctx context.Context, prefix string, limit int) (dto.Suggestions, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Suggestions{}, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+    (SELECT 'movie', id, title, LOWER(title) LIKE $1 || '%' ESCAPE '\' FROM movies
+      WHERE deleted_at IS NULL AND (LOWER(title) LIKE $1 || '%' ESCAPE '\' OR LOWER(title) LIKE '% ' || $1 || '%' ESCAPE '\')
+      ORDER BY LENGTH(title) LIMIT $2)
+    UNION ALL
+    (SELECT 'actor', id, actor_name, LOWER(actor_name) LIKE $1 || '%' ESCAPE '\' FROM actors
+      WHERE deleted_at IS NULL AND (LOWER(actor_name) LIKE $1 || '%' ESCAPE '\' OR LOWER(actor_name) LIKE '% ' || $1 || '%' ESCAPE '\')
+      ORDER BY LENGTH(actor_name) LIMIT $2)
+    UNION ALL
+    (SELECT 'genre', id, genre_name, LOWER(genre_name) LIKE $1 || '%' ESCAPE '\' FROM genres
+      WHERE deleted_at IS NULL AND LOWER(genre_name) LIKE $1 || '%' ESCAPE '\'
+      ORDER BY LENGTH(genre_name) LIMIT $2)
+  `, escapeLike(prefix), limit)
+	if err != nil {
+		return dto.Suggestions{}, err
+	}
+	defer rows.Close()
+
+	candidates := []suggestCandidate{}
+	for rows.Next() {
+		var c suggestCandidate
+		if err := rows.Scan(&c.kind, &c.item.ID, &c.item.Label, &c.starts); err != nil {
+			return dto.Suggestions{}, err
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return dto.Suggestions{}, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].starts && !candidates[j].starts
+	})
+
+	return groupSuggestions(candidates, limit), nil
+}
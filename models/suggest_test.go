@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottleAllow(t *testing.T) {
+	th := &throttle{every: time.Minute}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	if !th.allow(now) {
+		t.Fatal("the first call should be allowed")
+	}
+	if th.allow(now.Add(30 * time.Second)) {
+		t.Error("a call inside the interval should be held back")
+	}
+	if !th.allow(now.Add(time.Minute)) {
+		t.Error("a call after the interval should be allowed")
+	}
+}
+
+func TestRankSuggestionsSkipsEmptyMarker(t *testing.T) {
+	members := []string{
+		suggestEmpty,
+		"dune" + suggestSep + "movie" + suggestSep + "7" + suggestSep + "Dune",
+	}
+	got := rankSuggestions("dune", members, 5)
+	if len(got.Movies) != 1 || got.Movies[0].ID != 7 || len(got.Actors)+len(got.Genres) != 0 {
+		t.Errorf("rankSuggestions = %+v, want only Dune", got)
+	}
+}
@@ -0,0 +1,265 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Movies, directors and actors synced from TMDB keep their TMDB ID, so a sync
+// can run again and update them instead of adding duplicates. Changes made by
+// a sync are logged with no actor.
+
+// TMDBMovieIDs returns the TMDB IDs of the movies already synced.
+func TMDBMovieIDs() (map[int]bool, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), `SELECT tmdb_id FROM movies WHERE tmdb_id IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list TMDB movies: %v", err)
+	}
+	defer rows.Close()
+
+	ids := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// SyncTMDBMovie creates or updates a movie from TMDB with its genres and
+// credits, which replace the ones it had. A movie seeded before TMDB IDs were
+// stored is matched by title and release date, and people by name. A deleted
+// movie is updated but stays deleted. A dry run rolls everything back. Movies
+// need a release date, like the ones added by admins.
+func SyncTMDBMovie(m dto.TMDBMovie, dryRun bool) (dto.TMDBSyncResult, error) {
+	result := dto.TMDBSyncResult{}
+	ctx := context.Background()
+
+	releaseDate, err := time.Parse("2006-01-02", m.ReleaseDate)
+	if err != nil {
+		return result, fmt.Errorf("invalid release date %q: %v", m.ReleaseDate, err)
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return result, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, `SELECT id FROM movies WHERE tmdb_id = $1 FOR UPDATE`, m.TMDBID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx, `
+      SELECT id FROM movies
+      WHERE tmdb_id IS NULL AND title = $1 AND release_date = $2
+      ORDER BY id LIMIT 1
+      FOR UPDATE
+    `, m.Title, releaseDate).Scan(&id)
+	}
+
+	var before map[string]any
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		err = tx.QueryRow(ctx, `
+      INSERT INTO movies (tmdb_id, external_id, title, description, release_date, duration_minutes, image, horizontal_image)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+      RETURNING id
+    `, m.TMDBID, fmt.Sprintf("tmdb:%d", m.TMDBID), m.Title, m.Description, releaseDate, m.Duration, m.Image, m.HorizontalImage).Scan(&id)
+		if err != nil {
+			return result, fmt.Errorf("failed to insert movie: %v", err)
+		}
+	case err != nil:
+		return result, fmt.Errorf("failed to look up movie: %v", err)
+	default:
+		if before, err = auditSnapshot(tx, "movie", id); err != nil {
+			return result, err
+		}
+		_, err = tx.Exec(ctx, `
+      UPDATE movies SET tmdb_id = $2, title = $3, description = $4, release_date = $5,
+        duration_minutes = $6, image = $7, horizontal_image = $8
+      WHERE id = $1
+    `, id, m.TMDBID, m.Title, m.Description, releaseDate, m.Duration, m.Image, m.HorizontalImage)
+		if err != nil {
+			return result, fmt.Errorf("failed to update movie: %v", err)
+		}
+		for _, table := range []string{"movie_genres", "movie_directors", "movie_casts"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE id_movie = $1`, id); err != nil {
+				return result, fmt.Errorf("failed to clear %s: %v", table, err)
+			}
+		}
+	}
+	result.MovieID = id
+
+	genres := &importNames{ids: map[string]int{}, created: map[string]int{}}
+	for _, name := range m.Genres {
+		genreID, err := genres.id(tx, "genre", name)
+		if err != nil {
+			return result, err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO movie_genres (id_movie, id_genre) VALUES ($1, $2)`, id, genreID); err != nil {
+			return result, fmt.Errorf("failed to insert genre %q: %v", name, err)
+		}
+	}
+	result.GenresCreated = genres.created["genre"]
+
+	crew := make([]dto.CrewInput, len(m.Directors))
+	for i, d := range m.Directors {
+		directorID, created, err := syncTMDBPerson(tx, "director", d)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.DirectorsCreated++
+		}
+		crew[i] = dto.CrewInput{DirectorID: directorID, Job: d.Job, Order: &m.Directors[i].Order}
+	}
+	if err := insertMovieCrew(tx, id, crew); err != nil {
+		return result, fmt.Errorf("failed to insert director: %v", err)
+	}
+
+	casts := make([]dto.CastInput, len(m.Cast))
+	for i, a := range m.Cast {
+		actorID, created, err := syncTMDBPerson(tx, "actor", a)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.ActorsCreated++
+		}
+		casts[i] = dto.CastInput{ActorID: actorID, RoleName: a.Role, Order: &m.Cast[i].Order}
+	}
+	if err := insertMovieCasts(tx, id, casts); err != nil {
+		return result, fmt.Errorf("failed to insert cast: %v", err)
+	}
+
+	result.Action = "create"
+	event := "movie.created"
+	if before != nil {
+		after, err := auditSnapshot(tx, "movie", id)
+		if err != nil {
+			return result, err
+		}
+		if _, changed := auditDiff(before, after); len(changed) == 0 {
+			result.Action = "unchanged"
+		} else {
+			if _, err := tx.Exec(ctx, `UPDATE movies SET updated_at = NOW() WHERE id = $1`, id); err != nil {
+				return result, fmt.Errorf("failed to update movie: %v", err)
+			}
+			result.Action, event = "update", "movie.updated"
+		}
+	}
+
+	if result.Action != "unchanged" {
+		if err := queueMovieEvent(tx, id, event); err != nil {
+			return result, err
+		}
+		if err := recordAudit(tx, dto.AuditActor{}, "sync", "movie", id, before); err != nil {
+			return result, err
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("commit failed: %v", err)
+	}
+
+	if result.Action != "unchanged" || result.GenresCreated+result.DirectorsCreated+result.ActorsCreated > 0 {
+		invalidateCache(MovieCacheTag(id), CacheTagMovies, CacheTagGenres, CacheTagPeople)
+	}
+	if result.Action != "unchanged" {
+		wakeWebhookWorkers()
+	}
+	return result, nil
+}
+
+// syncTMDBPerson finds a director or actor by TMDB ID, renaming them when the
+// name changed on TMDB. Someone not synced yet is matched by name, and
+// created when there is no one by that name. Names are unique, so when the
+// name already belongs to someone else, that row is kept as it is and used
+// for the credit, which is logged.
+func syncTMDBPerson(tx pgx.Tx, entityType string, credit dto.TMDBCredit) (int, bool, error) {
+	ctx := context.Background()
+	table, column := auditEntities[entityType].Table, importNameColumns[entityType]
+
+	var id int
+	var name string
+	err := tx.QueryRow(ctx, `SELECT id, `+column+` FROM `+table+` WHERE tmdb_id = $1`, credit.TMDBID).Scan(&id, &name)
+	if err == nil {
+		if name == credit.Name {
+			return id, false, nil
+		}
+		var taken bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE `+column+` = $1)`, credit.Name).Scan(&taken)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to look up %s %q: %v", entityType, credit.Name, err)
+		}
+		if taken {
+			log.Printf("Keeping %s %q (TMDB %d) as %q is taken by another %s", entityType, name, credit.TMDBID, credit.Name, entityType)
+			return id, false, nil
+		}
+		before, err := auditSnapshot(tx, entityType, id)
+		if err != nil {
+			return 0, false, err
+		}
+		_, err = tx.Exec(ctx, `UPDATE `+table+` SET `+column+` = $2, updated_at = NOW() WHERE id = $1`, id, credit.Name)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to rename %s %q to %q: %v", entityType, name, credit.Name, err)
+		}
+		return id, false, recordAudit(tx, dto.AuditActor{}, "sync", entityType, id, before)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, fmt.Errorf("failed to look up %s %q: %v", entityType, credit.Name, err)
+	}
+
+	var tmdbID *int
+	err = tx.QueryRow(ctx, `SELECT id, tmdb_id FROM `+table+` WHERE `+column+` = $1`, credit.Name).Scan(&id, &tmdbID)
+	if err == nil {
+		if tmdbID != nil {
+			log.Printf("Crediting %s %q (TMDB %d) for TMDB %d, who has the same name", entityType, credit.Name, *tmdbID, credit.TMDBID)
+			return id, false, nil
+		}
+		before, err := auditSnapshot(tx, entityType, id)
+		if err != nil {
+			return 0, false, err
+		}
+		_, err = tx.Exec(ctx, `UPDATE `+table+` SET tmdb_id = $2, updated_at = NOW() WHERE id = $1`, id, credit.TMDBID)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to link %s %q to TMDB: %v", entityType, credit.Name, err)
+		}
+		return id, false, recordAudit(tx, dto.AuditActor{}, "sync", entityType, id, before)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, fmt.Errorf("failed to look up %s %q: %v", entityType, credit.Name, err)
+	}
+
+	err = tx.QueryRow(ctx, `
+    INSERT INTO `+table+` (`+column+`, tmdb_id) VALUES ($1, $2) RETURNING id
+  `, credit.Name, credit.TMDBID).Scan(&id)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to insert %s %q: %v", entityType, credit.Name, err)
+	}
+	return id, true, recordAudit(tx, dto.AuditActor{}, "create", entityType, id, nil)
+}
@@ -4,11 +4,26 @@ import (
   "be-tickitz/dto"
   "be-tickitz/utils"
   "context"
+  "errors"
   "fmt"
   "log"
+  "slices"
+  "strconv"
   "time"
+
+  "github.com/jackc/pgx/v5"
 )
 
+// nextInvoiceNumberSQL issues the next invoice number, e.g. INV-2025-000042.
+// Numbers are given out when a transaction is paid and never change.
+const nextInvoiceNumberSQL = `'INV-' || to_char(NOW(), 'YYYY') || '-' || lpad(nextval('invoice_number_seq')::text, 6, '0')`
+
+// TaxRatePercent is the PPN rate included in ticket prices, configured with
+// TAX_RATE_PERCENT. The rate is stored with each invoice when it is issued.
+func TaxRatePercent() int {
+  return max(utils.GetEnvInt("TAX_RATE_PERCENT", 11), 0)
+}
+
 func CreateTransaction(userID int, input dto.CreateTransactionRequest) (int, error) {
   conn, err := utils.ConnectDB()
   if err != nil {
@@ -22,30 +37,76 @@ func CreateTransaction(userID int, input dto.CreateTransactionRequest) (int, err
   }
   defer tx.Rollback(context.Background())
 
-  showDate, err := time.Parse("2006-01-02", input.ShowDate)
+  showDate, showTime, err := parseShowSlot(input.ShowDate, input.ShowTime)
   if err != nil {
-    return 0, fmt.Errorf("invalid date format: %v", err)
+    return 0, err
   }
 
-  showTime, err := time.Parse("15:04:05", input.ShowTime)
+  if err := requireLive(tx, "movie", input.MovieID, ErrMovieNotFound); err != nil {
+    return 0, err
+  }
+  if err := requireLive(tx, "payment_method", input.PaymentMethod, ErrPaymentMethodNotFound); err != nil {
+    return 0, err
+  }
+
+  showtimeID, showtimePrice, err := findShowtime(tx, input.MovieID, showDate, showTime, input.Location, input.Cinema)
   if err != nil {
-    showTime, err = time.Parse("15:04", input.ShowTime)
+    return 0, err
+  }
+
+  // Scheduled showtimes are charged at their own price. Unscheduled slots
+  // still take the price sent by the client, so promos are not given on them.
+  pricePerSeat := input.PricePerSeat
+  if showtimeID > 0 {
+    if err := claimHeldSeats(tx, showtimeID, userID, input.Seats); err != nil {
+      return 0, err
+    }
+    pricePerSeat = showtimePrice
+  } else if input.PromoCode != "" {
+    return 0, fmt.Errorf("%w: only valid for scheduled showtimes", ErrPromoInvalid)
+  }
+
+  totalPrice := len(input.Seats) * pricePerSeat
+
+  var promoID *int
+  var promoCode *string
+  discount := 0
+  if input.PromoCode != "" {
+    id, quote, err := applyPromo(tx, input.PromoCode, promoOrder{
+      UserID:        userID,
+      MovieID:       input.MovieID,
+      Cinema:        input.Cinema,
+      PaymentMethod: input.PaymentMethod,
+      Subtotal:      totalPrice,
+    }, true)
     if err != nil {
-      return 0, fmt.Errorf("invalid time format: %v", err)
+      return 0, err
     }
+    promoID, promoCode, discount = &id, &quote.Code, quote.Discount
+    totalPrice = quote.Total
   }
 
-  totalPrice := len(input.Seats) * input.PricePerSeat
+  pointsRedeemed, pointsDiscount := 0, 0
+  if input.RedeemPoints > 0 {
+    pointsRedeemed, pointsDiscount, err = redeemablePoints(tx, userID, input.RedeemPoints, totalPrice)
+    if err != nil {
+      return 0, err
+    }
+    totalPrice -= pointsDiscount
+  }
 
+  // Belum ada payment gateway, jadi transaksi langsung dianggap paid
   var transactionID int
   err = tx.QueryRow(context.Background(), `
     INSERT INTO transactions (
       id_user, id_movie, show_date, show_time, location, cinema,
-      total_price, payment_method
+      total_price, payment_method, id_promo, promo_code, discount_amount,
+      points_redeemed, points_discount, status, paid_at, invoice_number, invoice_tax_rate
     )
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, 'paid', NOW(), `+nextInvoiceNumberSQL+`, $14)
     RETURNING id
-  `, userID, input.MovieID, showDate, showTime, input.Location, input.Cinema, totalPrice, input.PaymentMethod).Scan(&transactionID)
+  `, userID, input.MovieID, showDate, showTime, input.Location, input.Cinema, totalPrice, input.PaymentMethod,
+    promoID, promoCode, discount, pointsRedeemed, pointsDiscount, TaxRatePercent()).Scan(&transactionID)
 
   if err != nil {
     return 0, fmt.Errorf("failed to create transaction: %v", err)
@@ -55,17 +116,41 @@ func CreateTransaction(userID int, input dto.CreateTransactionRequest) (int, err
     _, err := tx.Exec(context.Background(), `
       INSERT INTO transaction_details (transaction_id, seat, price)
       VALUES ($1, $2, $3)
-    `, transactionID, seat, input.PricePerSeat)
+    `, transactionID, seat, pricePerSeat)
 
     if err != nil {
       return 0, fmt.Errorf("failed to insert seat %s: %v", seat, err)
     }
   }
 
+  if pointsRedeemed > 0 {
+    _, err := tx.Exec(context.Background(), `
+      INSERT INTO points_ledger (id_user, id_transaction, entry_type, points, description)
+      VALUES ($1, $2, 'redeem', $3, $4)
+    `, userID, transactionID, -pointsRedeemed, fmt.Sprintf("Redeemed on booking #%d", transactionID))
+    if err != nil {
+      return 0, fmt.Errorf("failed to redeem points: %v", err)
+    }
+  }
+
+  if err := creditTransactionPoints(tx, transactionID); err != nil {
+    return 0, fmt.Errorf("failed to credit points: %v", err)
+  }
+
+  if err := queueTransactionEvent(tx, transactionID, "transaction.created"); err != nil {
+    return 0, err
+  }
+  if err := QueueBookingEmail(tx, transactionID, bookingEmails["paid"]); err != nil {
+    return 0, err
+  }
+
   if err := tx.Commit(context.Background()); err != nil {
     return 0, fmt.Errorf("commit failed: %v", err)
   }
 
+  wakeWebhookWorkers()
+  wakeMailWorkers()
+  publishSeatEvent(showtimeID, SeatTaken, input.Seats, nil)
   return transactionID, nil
 }
 
@@ -101,6 +186,7 @@ func CheckSeatAvailability(input dto.CreateTransactionRequest) ([]string, error)
       AND t.show_time = $3
       AND LOWER(t.location) = LOWER($4)
       AND LOWER(t.cinema) = LOWER($5)
+      AND t.status NOT IN ('cancelled', 'refunded')
   `
   params := []interface{}{
     input.MovieID,
@@ -139,16 +225,52 @@ func CheckSeatAvailability(input dto.CreateTransactionRequest) ([]string, error)
   return takenSeats, rows.Err()
 }
 
-func GetAllTransactions() ([]dto.TransactionSummary, error) {
-  conn, err := utils.ConnectDB()
-  if err != nil {
-    return nil, err
+var transactionListSpec = listSpec{
+  Sorts: map[string]sortColumn{
+    "id":         {Column: "id", Cast: "int"},
+    "createdAt":  {Column: "created_at", Cast: "timestamp"},
+    "showDate":   {Column: "show_date", Cast: "date"},
+    "totalPrice": {Column: "total_price", Cast: "int"},
+  },
+  DefaultSort:   "createdAt",
+  DefaultDesc:   true,
+  SearchColumns: []string{"movie_title"},
+  Filters: map[string]filterColumn{
+    "location": {Column: "location"},
+    "cinema":   {Column: "cinema"},
+    "status":   {Column: "status", Kind: filterCode},
+  },
+}
+
+// transactionAdminFilters and transactionAdminRanges are what admins can
+// narrow the transaction list and export down with.
+var (
+  transactionAdminFilters = map[string]filterColumn{
+    "email":         {Column: "user_email"},
+    "movieId":       {Column: "id_movie", Kind: filterInt},
+    "location":      {Column: "location"},
+    "cinema":        {Column: "cinema"},
+    "paymentMethod": {Column: "payment_method", Kind: filterInt},
+    "status":        {Column: "status", Kind: filterCode},
   }
-  defer conn.Release()
+  transactionAdminRanges = []rangeFilter{
+    {Column: "show_date", Cast: "date", MinParam: "showDateFrom", MaxParam: "showDateTo"},
+    {Column: "total_price", Cast: "int", MinParam: "minPrice", MaxParam: "maxPrice"},
+  }
+)
+
+var transactionAdminListSpec = listSpec{
+  Sorts:         transactionListSpec.Sorts,
+  DefaultSort:   "createdAt",
+  DefaultDesc:   true,
+  SearchColumns: []string{"movie_title", "user_email"},
+  Filters:       transactionAdminFilters,
+  Ranges:        transactionAdminRanges,
+}
 
-  rows, err := conn.Query(context.Background(), `
+const transactionSummarySelect = `
     SELECT
-      t.id AS transaction_id,
+      t.id,
       m.title AS movie_title,
       t.show_date,
       t.show_time,
@@ -156,118 +278,277 @@ func GetAllTransactions() ([]dto.TransactionSummary, error) {
       t.cinema,
       t.total_price,
       t.payment_method,
-      ARRAY_AGG(td.seat) AS seats
+      ARRAY_AGG(td.seat) AS seats,
+      t.created_at,
+      COALESCE(t.promo_code, '') AS promo_code,
+      t.discount_amount,
+      t.points_redeemed,
+      t.points_discount,
+      t.status,
+      t.id_movie,
+      COALESCE(u.email, '') AS user_email
     FROM transactions t
     JOIN movies m ON t.id_movie = m.id
+    LEFT JOIN users u ON u.id = t.id_user
     LEFT JOIN transaction_details td ON td.transaction_id = t.id
+  `
+
+const transactionSummaryGroupBy = `
     GROUP BY
       t.id, m.title, t.show_date, t.show_time,
-      t.location, t.cinema, t.total_price, t.payment_method
-    ORDER BY t.created_at DESC
-  `)
+      t.location, t.cinema, t.total_price, t.payment_method,
+      t.created_at, t.promo_code, t.discount_amount,
+      t.points_redeemed, t.points_discount, t.status,
+      t.id_movie, u.email
+  `
+
+func scanTransactionSummary(row pgx.Row) (dto.TransactionSummary, error) {
+  var t dto.TransactionSummary
+  var showDate, showTime time.Time
+  var seats []string
+
+  if err := row.Scan(
+    &t.TransactionID,
+    &t.MovieTitle,
+    &showDate,
+    &showTime,
+    &t.Location,
+    &t.Cinema,
+    &t.TotalPrice,
+    &t.PaymentMethod,
+    &seats,
+    &t.CreatedAt,
+    &t.PromoCode,
+    &t.Discount,
+    &t.PointsRedeemed,
+    &t.PointsDiscount,
+    &t.Status,
+    &t.MovieID,
+    &t.UserEmail,
+  ); err != nil {
+    return t, err
+  }
+
+  t.ShowDate = showDate.Format("2006-01-02")
+  t.ShowTime = showTime.Format("15:04")
+  t.Seats = seats
+  return t, nil
+}
+
+// queueTransactionEvent sends the transaction, as admins see it in
+// GetAllTransactions, to the webhooks subscribed to event.
+func queueTransactionEvent(db querier, transactionID int, event string) error {
+  t, err := scanTransactionSummary(db.QueryRow(context.Background(), transactionSummarySelect+`
+    WHERE t.id = $1
+  `+transactionSummaryGroupBy, transactionID))
   if err != nil {
-    return nil, err
+    return fmt.Errorf("failed to load transaction for %s: %v", event, err)
+  }
+  return QueueWebhookEvent(db, event, t)
+}
+
+func GetAllTransactions(q utils.PageQuery) ([]dto.TransactionSummary, utils.PageResult, error) {
+  return listTransactions(transactionSummarySelect+transactionSummaryGroupBy, nil, q, transactionAdminListSpec)
+}
+
+func GetUserTransactions(userID int, q utils.PageQuery) ([]dto.TransactionSummary, utils.PageResult, error) {
+  return listTransactions(transactionSummarySelect+`
+    WHERE t.id_user = $1
+  `+transactionSummaryGroupBy, []any{userID}, q, transactionListSpec)
+}
+
+func listTransactions(base string, params []any, q utils.PageQuery, spec listSpec) ([]dto.TransactionSummary, utils.PageResult, error) {
+  conn, err := utils.ConnectDB()
+  if err != nil {
+    return nil, utils.PageResult{}, err
+  }
+  defer conn.Release()
+
+  rows, total, err := queryPage(conn, base, params, q, spec)
+  if err != nil {
+    return nil, utils.PageResult{}, err
   }
   defer rows.Close()
 
   var transactions []dto.TransactionSummary
 
   for rows.Next() {
-    var t dto.TransactionSummary
-    var showDate, showTime time.Time
-    var seats []string
-
-    if err := rows.Scan(
-      &t.TransactionID,
-      &t.MovieTitle,
-      &showDate,
-      &showTime,
-      &t.Location,
-      &t.Cinema,
-      &t.TotalPrice,
-      &t.PaymentMethod,
-      &seats,
-    ); err != nil {
-      return nil, err
+    t, err := scanTransactionSummary(rows)
+    if err != nil {
+      return nil, utils.PageResult{}, err
     }
-
-    t.ShowDate = showDate.Format("2006-01-02")
-    t.ShowTime = showTime.Format("15:04")
-    t.Seats = seats
-
     transactions = append(transactions, t)
   }
 
   if err := rows.Err(); err != nil {
-    return nil, err
+    return nil, utils.PageResult{}, err
   }
 
-  return transactions, nil
+  key, _ := spec.sortKey(q)
+  transactions, nextCursor := trimPage(transactions, q, func(t dto.TransactionSummary) (string, int) {
+    switch key {
+    case "createdAt":
+      return t.CreatedAt.Format("2006-01-02 15:04:05.999999"), t.TransactionID
+    case "showDate":
+      return t.ShowDate, t.TransactionID
+    case "totalPrice":
+      return strconv.Itoa(t.TotalPrice), t.TransactionID
+    }
+    return strconv.Itoa(t.TransactionID), t.TransactionID
+  })
+
+  return transactions, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
-func GetUserTransactions(userID int) ([]dto.TransactionSummary, error) {
+var (
+  ErrTransactionNotFound = errors.New("transaction not found")
+  ErrInvalidStatusChange = errors.New("invalid status change")
+)
+
+// transactionStatusFlow lists the statuses each status may move to.
+// Cancelled and refunded are final.
+var transactionStatusFlow = map[string][]string{
+  "pending": {"paid", "cancelled"},
+  "paid":    {"cancelled", "refunded"},
+}
+
+// UpdateTransactionStatus moves a transaction to a new status and settles its
+// loyalty points: paying credits them, cancelling or refunding reverses what
+// was earned and returns what was redeemed and frees the seats. Webhooks get a
+// transaction.<status> event and the customer the matching booking email. It
+// returns the previous status.
+func UpdateTransactionStatus(admin dto.AuditActor, id int, status string) (string, error) {
   conn, err := utils.ConnectDB()
   if err != nil {
-    return nil, err
+    return "", err
   }
   defer conn.Release()
 
-  rows, err := conn.Query(context.Background(), `
-    SELECT
-      t.id AS transaction_id,
-      m.title AS movie_title,
-      t.show_date,
-      t.show_time,
-      t.location,
-      t.cinema,
-      t.total_price,
-      t.payment_method,
-      ARRAY_AGG(td.seat) AS seats
-    FROM transactions t
-    JOIN movies m ON t.id_movie = m.id
-    LEFT JOIN transaction_details td ON td.transaction_id = t.id
-    WHERE t.id_user = $1
-    GROUP BY
-      t.id, m.title, t.show_date, t.show_time,
-      t.location, t.cinema, t.total_price, t.payment_method
-    ORDER BY t.created_at DESC
-  `, userID)
+  tx, err := conn.Begin(context.Background())
   if err != nil {
-    return nil, err
+    return "", err
   }
-  defer rows.Close()
+  defer tx.Rollback(context.Background())
 
-  var transactions []dto.TransactionSummary
+  var current string
+  err = tx.QueryRow(context.Background(), `
+    SELECT status FROM transactions WHERE id = $1 FOR UPDATE
+  `, id).Scan(&current)
+  if errors.Is(err, pgx.ErrNoRows) {
+    return "", ErrTransactionNotFound
+  }
+  if err != nil {
+    return "", err
+  }
 
-  for rows.Next() {
-    var t dto.TransactionSummary
-    var showDate, showTime time.Time
-    var seats []string
+  if !slices.Contains(transactionStatusFlow[current], status) {
+    return "", fmt.Errorf("%w: %s to %s", ErrInvalidStatusChange, current, status)
+  }
 
-    if err := rows.Scan(
-      &t.TransactionID,
-      &t.MovieTitle,
-      &showDate,
-      &showTime,
-      &t.Location,
-      &t.Cinema,
-      &t.TotalPrice,
-      &t.PaymentMethod,
-      &seats,
-    ); err != nil {
-      return nil, err
-    }
+  before, err := auditSnapshot(tx, "transaction", id)
+  if err != nil {
+    return "", err
+  }
 
-    t.ShowDate = showDate.Format("2006-01-02")
-    t.ShowTime = showTime.Format("15:04")
-    t.Seats = seats
+  _, err = tx.Exec(context.Background(), `
+    UPDATE transactions SET
+      status = $1,
+      paid_at = CASE WHEN $1 = 'paid' THEN NOW() ELSE paid_at END,
+      invoice_number = CASE WHEN $1 = 'paid' THEN COALESCE(invoice_number, `+nextInvoiceNumberSQL+`) ELSE invoice_number END,
+      invoice_tax_rate = CASE WHEN $1 = 'paid' THEN COALESCE(invoice_tax_rate, $3) ELSE invoice_tax_rate END,
+      updated_at = NOW()
+    WHERE id = $2
+  `, status, id, TaxRatePercent())
+  if err != nil {
+    return "", fmt.Errorf("failed to update status: %v", err)
+  }
 
-    transactions = append(transactions, t)
+  if status == "paid" {
+    err = creditTransactionPoints(tx, id)
+  } else {
+    err = reverseTransactionPoints(tx, id)
+  }
+  if err != nil {
+    return "", err
   }
 
-  if err := rows.Err(); err != nil {
-    return nil, err
+  if err := queueTransactionEvent(tx, id, "transaction."+status); err != nil {
+    return "", err
+  }
+  if err := QueueBookingEmail(tx, id, bookingEmails[status]); err != nil {
+    return "", err
+  }
+
+  if err := recordAudit(tx, admin, "update_status", "transaction", id, before); err != nil {
+    return "", err
   }
 
-  return transactions, nil
-}
\ No newline at end of file
+  var showtimeID int
+  var seats []string
+  if status != "paid" {
+    if showtimeID, seats, err = transactionShowtimeSeats(tx, id); err != nil {
+      return "", err
+    }
+  }
+
+  if err := tx.Commit(context.Background()); err != nil {
+    return "", fmt.Errorf("commit failed: %v", err)
+  }
+
+  wakeWebhookWorkers()
+  wakeMailWorkers()
+  publishSeatEvent(showtimeID, SeatReleased, seats, nil)
+  return current, nil
+}
+
+var transactionExportSpec = listSpec{
+  Sorts:         transactionListSpec.Sorts,
+  DefaultSort:   "createdAt",
+  SearchColumns: []string{"movie_title", "user_email"},
+  Filters:       transactionAdminFilters,
+  Ranges:        transactionAdminRanges,
+}
+
+// TransactionExportColumns are the header cells of a transaction export, in
+// the order ExportTransactions writes its values.
+var TransactionExportColumns = []any{
+  "ID", "Booked At", "Paid At", "Status", "Customer Email", "Movie ID", "Movie",
+  "Location", "Cinema", "Show Date", "Show Time", "Seats", "Tickets",
+  "Payment Method", "Promo Code", "Promo Discount", "Points Redeemed",
+  "Points Discount", "Total Price",
+}
+
+// ExportTransactions streams the transactions booked between from and to
+// (inclusive dates) that match the search and filters of
+// q, calling write with the values of each one.
+func ExportTransactions(from, to time.Time, q utils.PageQuery, write func(values ...any) error) error {
+  return streamList(`
+    SELECT t.id, t.created_at, t.paid_at, t.status, COALESCE(u.email, '') AS user_email,
+      t.id_movie, m.title AS movie_title, t.location, t.cinema, t.show_date, t.show_time,
+      COALESCE((SELECT ARRAY_AGG(td.seat ORDER BY td.seat) FROM transaction_details td
+        WHERE td.transaction_id = t.id), '{}') AS seats,
+      t.payment_method, COALESCE(pm.payment_name, '') AS payment_name, COALESCE(t.promo_code, '') AS promo_code,
+      t.discount_amount, t.points_redeemed, t.points_discount, t.total_price
+    FROM transactions t
+    JOIN movies m ON m.id = t.id_movie
+    LEFT JOIN users u ON u.id = t.id_user
+    LEFT JOIN payment_method pm ON pm.id = t.payment_method
+    WHERE t.created_at >= $1::date
+      AND t.created_at < $2::date + 1
+  `, []any{from, to}, q, transactionExportSpec, func(rows pgx.Rows) error {
+    var id, movieID, promoDiscount, pointsRedeemed, pointsDiscount, total int
+    var createdAt, showDate, showTime time.Time
+    var paidAt *time.Time
+    var paymentID *int
+    var status, email, title, location, cinema, payment, promo string
+    var seats []string
+    if err := rows.Scan(&id, &createdAt, &paidAt, &status, &email, &movieID, &title, &location,
+      &cinema, &showDate, &showTime, &seats, &paymentID, &payment, &promo, &promoDiscount, &pointsRedeemed,
+      &pointsDiscount, &total); err != nil {
+      return err
+    }
+    return write(id, createdAt, paidAt, status, email, movieID, title, location, cinema,
+      showDate.Format("2006-01-02"), showTime.Format("15:04"), seats, len(seats),
+      payment, promo, promoDiscount, pointsRedeemed, pointsDiscount, total)
+  })
+}
@@ -5,6 +5,7 @@ import (
 	"be-tickitz/utils"
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -18,6 +19,7 @@ type User struct {
 	PhoneNumber    *string `json:"phoneNumber" db:"phone_number,omitempty"`
 	ProfilePicture *string `json:"profilePicture" db:"profile_picture"`
 	Role           string  `json:"role" db:"role"`
+	Language       string  `json:"language" db:"language"`
 }
 
 type UserLogin struct {
@@ -107,20 +109,30 @@ func UpdateUserPassword(userID int, newPassword string) error {
 	return err
 }
 
-func GetAllUsers() ([]User, error) {
+var userListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":       {Column: "id", Cast: "int"},
+		"email":    {Column: "email", Cast: "text"},
+		"fullName": {Column: "full_name", Cast: "text"},
+	},
+	DefaultSort:   "id",
+	SearchColumns: []string{"email", "full_name"},
+	Filters:       map[string]filterColumn{"role": {Column: "role", Kind: filterCode}},
+}
+
+func GetAllUsers(q utils.PageQuery) ([]User, utils.PageResult, error) {
 	conn, err := utils.ConnectDB()
 	if err != nil {
-		return nil, err
+		return nil, utils.PageResult{}, err
 	}
 	defer conn.Release()
 
-	rows, err := conn.Query(context.Background(), `
-    SELECT id, email, full_name, phone_number, profile_picture, role
+	rows, total, err := queryPage(conn, `
+    SELECT id, email, COALESCE(full_name, '') AS full_name, phone_number, profile_picture, role
     FROM users
-    ORDER BY id ASC
-  `)
+  `, nil, q, userListSpec)
 	if err != nil {
-		return nil, err
+		return nil, utils.PageResult{}, err
 	}
 	defer rows.Close()
 
@@ -135,12 +147,26 @@ func GetAllUsers() ([]User, error) {
 			&u.ProfilePicture,
 			&u.Role,
 		); err != nil {
-			return nil, err
+			return nil, utils.PageResult{}, err
 		}
 		users = append(users, u)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := userListSpec.sortKey(q)
+	users, nextCursor := trimPage(users, q, func(u User) (string, int) {
+		switch key {
+		case "email":
+			return u.Email, u.ID
+		case "fullName":
+			return u.FullName, u.ID
+		}
+		return strconv.Itoa(u.ID), u.ID
+	})
 
-	return users, nil
+	return users, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
 }
 
 func GetUserByID(userID int) (User, error) {
@@ -152,7 +178,7 @@ func GetUserByID(userID int) (User, error) {
 
 	var u User
 	err = conn.QueryRow(context.Background(), `
-    SELECT id, email, full_name, phone_number, profile_picture, role
+    SELECT id, email, COALESCE(full_name, ''), phone_number, profile_picture, role, language
     FROM users
     WHERE id = $1
   `, userID).Scan(
@@ -162,31 +188,29 @@ func GetUserByID(userID int) (User, error) {
 		&u.PhoneNumber,
 		&u.ProfilePicture,
 		&u.Role,
+		&u.Language,
 	)
 	return u, err
 }
 
-func DeleteUserByID(userID int) error {
-	conn, err := utils.ConnectDB()
-	if err != nil {
-		return err
-	}
-	defer conn.Release()
-
-	var exists bool
-	err = conn.QueryRow(context.Background(),
-		`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID,
-	).Scan(&exists)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return fmt.Errorf("user not found")
-	}
+func DeleteUserByID(admin dto.AuditActor, userID int) error {
+	_, err := withAudit(admin, "delete", "user", userID, func(tx pgx.Tx) (int, error) {
+		var exists bool
+		err := tx.QueryRow(context.Background(),
+			`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID,
+		).Scan(&exists)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, fmt.Errorf("user not found")
+		}
 
-	_, err = conn.Exec(context.Background(),
-		`DELETE FROM users WHERE id = $1`, userID,
-	)
+		_, err = tx.Exec(context.Background(),
+			`DELETE FROM users WHERE id = $1`, userID,
+		)
+		return userID, err
+	})
 	return err
 }
 
@@ -202,9 +226,10 @@ func UpdateUserProfile(userID int, data dto.UpdateProfileRequest) error {
     SET full_name = COALESCE($1, full_name),
         phone_number = COALESCE($2, phone_number),
         profile_picture = COALESCE($3, profile_picture),
+        language = COALESCE($5, language),
         updated_at = CURRENT_TIMESTAMP
     WHERE id = $4
-  `, data.FullName, data.PhoneNumber, data.ProfilePicture, userID)
+  `, data.FullName, data.PhoneNumber, data.ProfilePicture, userID, data.Language)
 	if err != nil {
 		return err
 	}
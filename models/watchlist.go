@@ -0,0 +1,243 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var ErrMovieNotFound = errors.New("movie not found")
+
+// hasFutureShowtimeSQL matches movies (aliased m) that can be booked at all.
+const hasFutureShowtimeSQL = `EXISTS (
+      SELECT 1 FROM showtimes s
+      WHERE s.id_movie = m.id AND (s.show_date + s.show_time) >= LOCALTIMESTAMP
+    )`
+
+var watchlistSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"addedAt":     {Column: "created_at", Cast: "timestamp"},
+		"releaseDate": {Column: "release_date", Cast: "date", Nullable: true},
+		"title":       {Column: "title", Cast: "text"},
+	},
+	DefaultSort:   "addedAt",
+	DefaultDesc:   true,
+	SearchColumns: []string{"title"},
+}
+
+// AddToWatchlist saves a movie for the user. Events that already happened
+// (the movie is released or bookable) are marked as notified so watchers
+// only hear about what changes after they subscribe.
+func AddToWatchlist(userID, movieID int) error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if err := requireLive(conn, "movie", movieID, ErrMovieNotFound); err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(context.Background(), `
+    INSERT INTO watchlists (id_user, id_movie, notified_showtime_at, notified_release_at)
+    SELECT $1, m.id,
+      CASE WHEN `+hasFutureShowtimeSQL+` THEN NOW() END,
+      CASE WHEN m.release_date <= CURRENT_DATE THEN NOW() END
+    FROM movies m
+    WHERE m.id = $2
+    ON CONFLICT (id_user, id_movie) DO NOTHING
+  `, userID, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to add to watchlist: %v", err)
+	}
+	return nil
+}
+
+func RemoveFromWatchlist(userID, movieID int) error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	tag, err := conn.Exec(context.Background(), `
+    DELETE FROM watchlists WHERE id_user = $1 AND id_movie = $2
+  `, userID, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to remove from watchlist: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMovieNotFound
+	}
+	return nil
+}
+
+func GetWatchlist(userID int, q utils.PageQuery) ([]dto.WatchlistItem, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, `
+    SELECT m.id, m.title, m.image, m.horizontal_image, m.release_date,
+      `+hasFutureShowtimeSQL+` AS bookable,
+      w.created_at
+    FROM watchlists w
+    JOIN movies m ON m.id = w.id_movie
+    WHERE w.id_user = $1 AND m.deleted_at IS NULL
+  `, []any{userID}, q, watchlistSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	var items []dto.WatchlistItem
+	for rows.Next() {
+		var item dto.WatchlistItem
+		if err := rows.Scan(
+			&item.MovieID,
+			&item.Title,
+			&item.Image,
+			&item.HorizontalImage,
+			&item.ReleaseDate,
+			&item.Bookable,
+			&item.AddedAt,
+		); err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := watchlistSpec.sortKey(q)
+	items, nextCursor := trimPage(items, q, func(item dto.WatchlistItem) (string, int) {
+		switch key {
+		case "releaseDate":
+			if item.ReleaseDate.IsZero() {
+				return "", item.MovieID
+			}
+			return item.ReleaseDate.Format("2006-01-02"), item.MovieID
+		case "title":
+			return item.Title, item.MovieID
+		}
+		return item.AddedAt.Format("2006-01-02 15:04:05.999999"), item.MovieID
+	})
+
+	return items, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
+
+type watchlistNotice struct {
+	id       int
+	email    string
+	name     string
+	language string
+	movieID  int
+	title    string
+	bookable bool
+	released bool
+}
+
+// WatchlistNotifyInterval is how often watchers are checked for news,
+// configured with WATCHLIST_NOTIFY_INTERVAL_MINUTES.
+func WatchlistNotifyInterval() time.Duration {
+	return time.Duration(utils.GetEnvInt("WATCHLIST_NOTIFY_INTERVAL_MINUTES", 15)) * time.Minute
+}
+
+// StartWatchlistNotifier runs NotifyWatchers in the background on every
+// WatchlistNotifyInterval tick.
+func StartWatchlistNotifier() {
+	go func() {
+		ticker := time.NewTicker(WatchlistNotifyInterval())
+		defer ticker.Stop()
+		for {
+			if sent, err := NotifyWatchers(); err != nil {
+				log.Println("Watchlist notifier:", err.Error())
+			} else if sent > 0 {
+				log.Printf("Watchlist notifier: queued %d emails", sent)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// NotifyWatchers emails users whose watched movies got their first bookable
+// showtime or reached their release date. Entries are claimed with SKIP
+// LOCKED so several app instances can run the job; an email that cannot be
+// queued is released again to be retried on the next run.
+func NotifyWatchers() (int, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(context.Background(), `
+    WITH due AS (
+      SELECT w.id, u.email, COALESCE(u.full_name, '') AS full_name, u.language, m.id AS id_movie, m.title,
+        (w.notified_showtime_at IS NULL AND `+hasFutureShowtimeSQL+`) AS bookable,
+        (w.notified_release_at IS NULL AND m.release_date <= CURRENT_DATE) AS released
+      FROM watchlists w
+      JOIN movies m ON m.id = w.id_movie
+      JOIN users u ON u.id = w.id_user
+      WHERE (w.notified_showtime_at IS NULL OR w.notified_release_at IS NULL) AND m.deleted_at IS NULL
+      FOR UPDATE OF w SKIP LOCKED
+    )
+    UPDATE watchlists w SET
+      notified_showtime_at = CASE WHEN due.bookable THEN NOW() ELSE w.notified_showtime_at END,
+      notified_release_at = CASE WHEN due.released THEN NOW() ELSE w.notified_release_at END
+    FROM due
+    WHERE w.id = due.id AND (due.bookable OR due.released)
+    RETURNING w.id, due.email, due.full_name, due.language, due.id_movie, due.title, due.bookable, due.released
+  `)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim watchlist notices: %v", err)
+	}
+
+	var notices []watchlistNotice
+	for rows.Next() {
+		var n watchlistNotice
+		if err := rows.Scan(&n.id, &n.email, &n.name, &n.language, &n.movieID, &n.title, &n.bookable, &n.released); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		notices = append(notices, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, n := range notices {
+		err := QueueTemplateEmail(conn, n.email, "watchlist_alert", n.language, dto.WatchlistEmail{
+			Name:       n.name,
+			MovieTitle: n.title,
+			Bookable:   n.bookable,
+			Released:   n.released,
+		})
+		if err != nil {
+			log.Printf("Failed to queue email to watcher %s about movie %d: %v", n.email, n.movieID, err)
+			_, err = conn.Exec(context.Background(), `
+        UPDATE watchlists SET
+          notified_showtime_at = CASE WHEN $2::boolean THEN NULL ELSE notified_showtime_at END,
+          notified_release_at = CASE WHEN $3::boolean THEN NULL ELSE notified_release_at END
+        WHERE id = $1
+      `, n.id, n.bookable, n.released)
+			if err != nil {
+				log.Println("Failed to release watchlist notice:", err.Error())
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,490 @@
+package models
+
+import (
+	"be-tickitz/dto"
+	"be-tickitz/utils"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookEvents are the events webhooks can subscribe to.
+var WebhookEvents = []string{
+	"transaction.created",
+	"transaction.paid",
+	"transaction.cancelled",
+	"transaction.refunded",
+	"movie.created",
+	"movie.updated",
+}
+
+// webhookWake nudges the local workers when a delivery is queued.
+var webhookWake = make(chan struct{}, 1)
+
+// webhookStuckAfter is how long a delivery may stay in "sending" before it is
+// considered abandoned by a crashed worker and picked up again.
+const webhookStuckAfter = 10 * time.Minute
+
+// webhookEnvelope is the JSON body receivers get. ID identifies the event and
+// stays the same when a delivery is retried or redelivered, so receivers can
+// skip events they already handled.
+type webhookEnvelope struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+func validateWebhookEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("unknown event %q, expected one of %s", event, strings.Join(WebhookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+func webhookMaxAttempts() int {
+	return max(utils.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 8), 1)
+}
+
+// webhookRetryDelay is the wait after the given failed attempt, starting at
+// WEBHOOK_RETRY_BASE_SECONDS and at most six hours.
+func webhookRetryDelay(attempt int) time.Duration {
+	base := time.Duration(utils.GetEnvInt("WEBHOOK_RETRY_BASE_SECONDS", 30)) * time.Second
+	return retryDelay(base, attempt, 6*time.Hour)
+}
+
+// QueueWebhookEvent queues a delivery of event to every active webhook
+// subscribed to it. db should be the transaction making the change, so the
+// event only goes out if it commits; the caller then wakes the workers with
+// wakeWebhookWorkers, as they would not see the delivery before the commit.
+func QueueWebhookEvent(db querier, event string, data any) error {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(webhookEnvelope{
+		ID:        "evt_" + hex.EncodeToString(id),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %v", event, err)
+	}
+
+	tag, err := db.Exec(context.Background(), `
+    INSERT INTO webhook_deliveries (id_webhook, event, payload, max_attempts)
+    SELECT id, $1::varchar, $2::jsonb, $3::int FROM webhooks
+    WHERE is_active AND $1 = ANY(events)
+  `, event, string(payload), webhookMaxAttempts())
+	if err != nil {
+		return fmt.Errorf("failed to queue %s event: %v", event, err)
+	}
+
+	if _, inTx := db.(pgx.Tx); !inTx && tag.RowsAffected() > 0 {
+		wakeWebhookWorkers()
+	}
+	return nil
+}
+
+// wakeWebhookWorkers nudges the local workers to look for queued deliveries.
+func wakeWebhookWorkers() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartWebhookWorkers runs WEBHOOK_WORKERS background workers delivering
+// queued events. They poll every WEBHOOK_POLL_SECONDS and are woken early when
+// an event is queued.
+func StartWebhookWorkers() {
+	poll := time.Duration(max(utils.GetEnvInt("WEBHOOK_POLL_SECONDS", 5), 1)) * time.Second
+	startWorkers("Webhook worker", utils.GetEnvInt("WEBHOOK_WORKERS", 2), poll, webhookWake, DeliverNextWebhook)
+
+	go func() {
+		for {
+			if err := purgeWebhookDeliveries(); err != nil {
+				log.Println("Webhook log cleanup:", err.Error())
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+// DeliverNextWebhook claims one due delivery and sends it. It reports whether
+// a delivery was claimed. A failed delivery is retried with backoff until it
+// runs out of attempts and is marked failed.
+func DeliverNextWebhook() (bool, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+
+	var id, attempts, maxAttempts int
+	var url, secret, event, payload string
+	err = conn.QueryRow(context.Background(), `
+    WITH claimed AS (
+      UPDATE webhook_deliveries SET status = 'sending', attempts = attempts + 1, updated_at = NOW()
+      WHERE id = (
+        SELECT id FROM webhook_deliveries
+        WHERE (status = 'queued' AND next_attempt_at <= NOW())
+          OR (status = 'sending' AND updated_at < NOW() - make_interval(secs => $1))
+        ORDER BY next_attempt_at, id
+        LIMIT 1
+        FOR UPDATE SKIP LOCKED
+      )
+      RETURNING id, id_webhook, event, payload, attempts, max_attempts
+    )
+    SELECT c.id, w.url, w.secret, c.event, c.payload::text, c.attempts, c.max_attempts
+    FROM claimed c
+    JOIN webhooks w ON w.id = c.id_webhook
+  `, webhookStuckAfter.Seconds()).Scan(&id, &url, &secret, &event, &payload, &attempts, &maxAttempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %v", err)
+	}
+
+	resp, sendErr := utils.PostWebhook(url, secret, event, id, []byte(payload))
+	var status *int
+	if resp.Status != 0 {
+		status = &resp.Status
+	}
+
+	if sendErr == nil {
+		_, err = conn.Exec(context.Background(), `
+      UPDATE webhook_deliveries SET
+        status = 'delivered', delivered_at = NOW(), response_status = $2, response_body = $3,
+        last_error = NULL, updated_at = NOW()
+      WHERE id = $1
+    `, id, status, resp.Body)
+		return true, err
+	}
+
+	next := "queued"
+	if attempts >= maxAttempts {
+		next = "failed"
+		log.Printf("Webhook delivery %d of %s to %s failed after %d attempts: %v", id, event, url, attempts, sendErr)
+	}
+	_, err = conn.Exec(context.Background(), `
+    UPDATE webhook_deliveries SET
+      status = $2,
+      next_attempt_at = NOW() + make_interval(secs => $3),
+      response_status = $4,
+      response_body = $5,
+      last_error = $6,
+      updated_at = NOW()
+    WHERE id = $1
+  `, id, next, webhookRetryDelay(attempts).Seconds(), status, resp.Body, sendErr.Error())
+	return true, err
+}
+
+// purgeWebhookDeliveries drops delivered events older than
+// WEBHOOK_LOG_RETENTION_DAYS. Failed deliveries are kept for redelivery.
+func purgeWebhookDeliveries() error {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(context.Background(), `
+    DELETE FROM webhook_deliveries
+    WHERE status = 'delivered' AND delivered_at < NOW() - make_interval(days => $1)
+  `, utils.GetEnvInt("WEBHOOK_LOG_RETENTION_DAYS", 30))
+	return err
+}
+
+const webhookSelect = `
+    SELECT w.id, w.url, w.description, w.events, w.is_active,
+      last.status AS last_status, last.created_at AS last_delivery_at, w.created_at
+    FROM webhooks w
+    LEFT JOIN LATERAL (
+      SELECT status, created_at FROM webhook_deliveries
+      WHERE id_webhook = w.id
+      ORDER BY id DESC
+      LIMIT 1
+    ) last ON TRUE
+  `
+
+func scanWebhook(row pgx.Row) (dto.Webhook, error) {
+	var w dto.Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Description, &w.Events, &w.IsActive, &w.LastStatus, &w.LastSentAt, &w.CreatedAt)
+	return w, err
+}
+
+var webhookListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":        {Column: "id", Cast: "int"},
+		"createdAt": {Column: "created_at", Cast: "timestamp"},
+	},
+	DefaultSort:   "createdAt",
+	DefaultDesc:   true,
+	SearchColumns: []string{"url", "description"},
+	Filters:       map[string]filterColumn{"isActive": {Column: "is_active", Kind: filterBool}},
+}
+
+func CreateWebhook(admin dto.AuditActor, input dto.CreateWebhookRequest) (dto.Webhook, error) {
+	if err := validateWebhookEvents(input.Events); err != nil {
+		return dto.Webhook{}, err
+	}
+	if err := utils.CheckWebhookURL(input.URL); err != nil {
+		return dto.Webhook{}, err
+	}
+	secret, err := utils.NewWebhookSecret()
+	if err != nil {
+		return dto.Webhook{}, err
+	}
+
+	id, err := withAudit(admin, "create", "webhook", 0, func(tx pgx.Tx) (int, error) {
+		var id int
+		err := tx.QueryRow(context.Background(), `
+      INSERT INTO webhooks (url, description, secret, events, is_active)
+      VALUES ($1, $2, $3, $4, $5)
+      RETURNING id
+    `, input.URL, input.Description, secret, input.Events, input.IsActive == nil || *input.IsActive).Scan(&id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create webhook: %v", err)
+		}
+		return id, nil
+	})
+	if err != nil {
+		return dto.Webhook{}, err
+	}
+
+	webhook, err := GetWebhookByID(id)
+	webhook.Secret = secret
+	return webhook, err
+}
+
+func GetWebhookByID(id int) (dto.Webhook, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return dto.Webhook{}, err
+	}
+	defer conn.Release()
+
+	webhook, err := scanWebhook(conn.QueryRow(context.Background(), webhookSelect+` WHERE w.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto.Webhook{}, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+func GetAllWebhooks(q utils.PageQuery) ([]dto.Webhook, utils.PageResult, error) {
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, webhookSelect, nil, q, webhookListSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	webhooks := []dto.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := webhookListSpec.sortKey(q)
+	webhooks, nextCursor := trimPage(webhooks, q, func(w dto.Webhook) (string, int) {
+		if key == "createdAt" {
+			return w.CreatedAt.Format("2006-01-02 15:04:05.999999"), w.ID
+		}
+		return strconv.Itoa(w.ID), w.ID
+	})
+	return webhooks, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
+
+func UpdateWebhook(admin dto.AuditActor, id int, input dto.UpdateWebhookRequest) (dto.Webhook, error) {
+	if input.Events != nil {
+		if err := validateWebhookEvents(*input.Events); err != nil {
+			return dto.Webhook{}, err
+		}
+	}
+	if input.URL != nil {
+		if err := utils.CheckWebhookURL(*input.URL); err != nil {
+			return dto.Webhook{}, err
+		}
+	}
+	var secret *string
+	if input.RotateSecret {
+		s, err := utils.NewWebhookSecret()
+		if err != nil {
+			return dto.Webhook{}, err
+		}
+		secret = &s
+	}
+
+	_, err := withAudit(admin, "update", "webhook", id, func(tx pgx.Tx) (int, error) {
+		tag, err := tx.Exec(context.Background(), `
+      UPDATE webhooks SET
+        url = COALESCE($1, url),
+        description = COALESCE($2, description),
+        events = COALESCE($3, events),
+        is_active = COALESCE($4, is_active),
+        secret = COALESCE($5, secret),
+        updated_at = NOW()
+      WHERE id = $6
+    `, input.URL, input.Description, input.Events, input.IsActive, secret, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update webhook: %v", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, ErrWebhookNotFound
+		}
+		return id, nil
+	})
+	if err != nil {
+		return dto.Webhook{}, err
+	}
+
+	webhook, err := GetWebhookByID(id)
+	if secret != nil {
+		webhook.Secret = *secret
+	}
+	return webhook, err
+}
+
+// DeleteWebhook removes a webhook together with its delivery log.
+func DeleteWebhook(admin dto.AuditActor, id int) error {
+	_, err := withAudit(admin, "delete", "webhook", id, func(tx pgx.Tx) (int, error) {
+		tag, err := tx.Exec(context.Background(), `DELETE FROM webhooks WHERE id = $1`, id)
+		if err != nil {
+			return 0, err
+		}
+		if tag.RowsAffected() == 0 {
+			return 0, ErrWebhookNotFound
+		}
+		return id, nil
+	})
+	return err
+}
+
+const webhookDeliverySelect = `
+    SELECT id, id_webhook, event, payload, status, attempts, max_attempts, next_attempt_at,
+      response_status, response_body, last_error, delivered_at, created_at
+    FROM webhook_deliveries
+  `
+
+func scanWebhookDelivery(row pgx.Row) (dto.WebhookDelivery, error) {
+	var d dto.WebhookDelivery
+	var payload []byte
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &d.MaxAttempts,
+		&d.NextAttemptAt, &d.ResponseStatus, &d.ResponseBody, &d.LastError, &d.DeliveredAt, &d.CreatedAt)
+	d.Payload = payload
+	return d, err
+}
+
+var webhookDeliveryListSpec = listSpec{
+	Sorts: map[string]sortColumn{
+		"id":        {Column: "id", Cast: "int"},
+		"createdAt": {Column: "created_at", Cast: "timestamp"},
+	},
+	DefaultSort: "createdAt",
+	DefaultDesc: true,
+	Filters: map[string]filterColumn{
+		"status": {Column: "status", Kind: filterCode},
+		"event":  {Column: "event", Kind: filterCode},
+	},
+}
+
+// GetWebhookDeliveries is the delivery log of a webhook, newest first.
+func GetWebhookDeliveries(webhookID int, q utils.PageQuery) ([]dto.WebhookDelivery, utils.PageResult, error) {
+	if _, err := GetWebhookByID(webhookID); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	conn, err := utils.ConnectDB()
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer conn.Release()
+
+	rows, total, err := queryPage(conn, webhookDeliverySelect+` WHERE id_webhook = $1`, []any{webhookID}, q, webhookDeliveryListSpec)
+	if err != nil {
+		return nil, utils.PageResult{}, err
+	}
+	defer rows.Close()
+
+	deliveries := []dto.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, utils.PageResult{}, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PageResult{}, err
+	}
+
+	key, _ := webhookDeliveryListSpec.sortKey(q)
+	deliveries, nextCursor := trimPage(deliveries, q, func(d dto.WebhookDelivery) (string, int) {
+		if key == "createdAt" {
+			return d.CreatedAt.Format("2006-01-02 15:04:05.999999"), d.ID
+		}
+		return strconv.Itoa(d.ID), d.ID
+	})
+	return deliveries, utils.PageResult{Total: total, NextCursor: nextCursor}, nil
+}
+
+// RedeliverWebhook queues a delivery again as a new entry in the log, with
+// the same payload and event ID and a fresh set of attempts. It works on
+// deliveries in any status, e.g. to replay an event a receiver lost.
+func RedeliverWebhook(admin dto.AuditActor, webhookID, deliveryID int) (dto.WebhookDelivery, error) {
+	var delivery dto.WebhookDelivery
+	_, err := withAudit(admin, "redeliver", "webhook_delivery", 0, func(tx pgx.Tx) (int, error) {
+		var err error
+		delivery, err = scanWebhookDelivery(tx.QueryRow(context.Background(), `
+      INSERT INTO webhook_deliveries (id_webhook, event, payload, max_attempts)
+      SELECT id_webhook, event, payload, $3::int FROM webhook_deliveries
+      WHERE id = $1 AND id_webhook = $2
+      RETURNING id, id_webhook, event, payload, status, attempts, max_attempts, next_attempt_at,
+        response_status, response_body, last_error, delivered_at, created_at
+    `, deliveryID, webhookID, webhookMaxAttempts()))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrDeliveryNotFound
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to redeliver: %v", err)
+		}
+		return delivery.ID, nil
+	})
+	if err != nil {
+		return dto.WebhookDelivery{}, err
+	}
+
+	wakeWebhookWorkers()
+	return delivery, nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestValidateWebhookEvents(t *testing.T) {
+	if err := validateWebhookEvents([]string{"transaction.paid", "movie.created"}); err != nil {
+		t.Errorf("known events: %v", err)
+	}
+	if err := validateWebhookEvents([]string{"transaction.paid", "movie.deleted"}); err == nil {
+		t.Error("an unknown event should be rejected")
+	}
+}
+
+func TestWakeWebhookWorkersDoesNotBlock(t *testing.T) {
+	for range 3 {
+		wakeWebhookWorkers()
+	}
+	select {
+	case <-webhookWake:
+	default:
+		t.Fatal("workers should have been woken")
+	}
+	select {
+	case <-webhookWake:
+		t.Fatal("repeated wake ups should collapse into one")
+	default:
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"log"
+	"time"
+)
+
+// startWorkers runs count background workers calling next until it reports
+// there is nothing left to do, then waiting for the next poll or a wake up.
+// name prefixes the errors they log.
+func startWorkers(name string, count int, poll time.Duration, wake <-chan struct{}, next func() (bool, error)) {
+	for i := 0; i < max(count, 1); i++ {
+		go func() {
+			ticker := time.NewTicker(poll)
+			defer ticker.Stop()
+			for {
+				for {
+					worked, err := next()
+					if err != nil {
+						log.Println(name+":", err.Error())
+					}
+					if !worked {
+						break
+					}
+				}
+				select {
+				case <-ticker.C:
+				case <-wake:
+				}
+			}
+		}()
+	}
+}
+
+// retryDelay is the wait after the given failed attempt: base doubled on
+// every attempt, at most limit.
+func retryDelay(base time.Duration, attempt int, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{20, time.Hour},
+		{1000, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(30*time.Second, tt.attempt, time.Hour); got != tt.want {
+			t.Errorf("retryDelay(30s, %d, 1h) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -10,7 +10,9 @@ import (
 func actorAdminRouter(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.POST("", controllers.CreateActor)
+	r.GET("", controllers.GetAdminActors)
 	r.DELETE("/:id", controllers.DeleteActor)
+	r.POST("/:id/restore", controllers.RestoreActor)
 }
 
 func actorPublicRouter(r *gin.RouterGroup) {
@@ -0,0 +1,13 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+	"be-tickitz/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func auditAdminRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.GET("", controllers.GetAuditLog)
+}
@@ -10,7 +10,9 @@ import (
 func directorAdminRouter(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.POST("", controllers.CreateDirector)
+	r.GET("", controllers.GetAdminDirectors)
 	r.DELETE("/:id", controllers.DeleteDirector)
+	r.POST("/:id/restore", controllers.RestoreDirector)
 }
 
 func directorPublicRouter(r *gin.RouterGroup) {
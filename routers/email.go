@@ -0,0 +1,14 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+	"be-tickitz/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func emailAdminRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.GET("", controllers.GetOutboxEmails)
+	r.POST("/:id/retry", controllers.RetryEmail)
+}
@@ -10,8 +10,10 @@ import (
 func genreAdminRouter(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.POST("", controllers.CreateGenre)
+	r.GET("", controllers.GetAdminGenres)
 	r.DELETE("/:id", controllers.DeleteGenre)
+	r.POST("/:id/restore", controllers.RestoreGenre)
 }
 func genrePublicRouter(r *gin.RouterGroup) {
-	r.GET("", controllers.GetAllGenres)
+	r.GET("", middlewares.HTTPCache(), controllers.GetAllGenres)
 }
@@ -29,6 +29,17 @@ func CombineRouter(r *gin.Engine) {
 	TransactionRouter(r.Group("/transactions"))
 	TransactionAdminRouter(r.Group("/admin/transactions"))
 	CheckSeatsRouter(r.Group("/check-seats"))
+	showtimeAdminRouter(r.Group("/admin/showtimes"))
+	showtimePublicRouter(r.Group("/showtimes"))
+	searchRouter(r.Group("/search"))
+	reviewRouter(r.Group("/reviews"))
+	reviewAdminRouter(r.Group("/admin/reviews"))
+	promoAdminRouter(r.Group("/admin/promos"))
+	promoRouter(r.Group("/promos"))
+	reportAdminRouter(r.Group("/admin/reports"))
+	emailAdminRouter(r.Group("/admin/emails"))
+	webhookAdminRouter(r.Group("/admin/webhooks"))
+	auditAdminRouter(r.Group("/admin/audit-log"))
 
 	docs.SwaggerInfo.BasePath = "/"
 	r.GET("/docs", func(ctx *gin.Context) {
@@ -10,14 +10,22 @@ import (
 func movieAdminRouter(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.POST("", controllers.CreateMovie)
+	r.GET("", controllers.GetAdminMovies)
 	r.POST("/add-genre", controllers.AddGenretoMovie)
+	r.POST("/import", controllers.ImportMovies)
+	r.GET("/export", controllers.ExportMovies)
 	r.DELETE("/:id", controllers.DeleteMovie)
 	r.PATCH("/:id", controllers.UpdateMovie)
+	r.POST("/:id/restore", controllers.RestoreMovie)
 }
 
 func moviePublicRouter(r *gin.RouterGroup) {
-	r.GET("", controllers.GetAllMovies)
-	r.GET("/:id", controllers.GetMovieByID)
-	r.GET("/now-showing", controllers.GetNowShowing)
-	r.GET("/upcoming", controllers.GetUpcoming)
+	r.GET("", middlewares.HTTPCache(), controllers.GetAllMovies)
+	r.GET("/suggest", controllers.SuggestMovies)
+	r.GET("/recommended", middlewares.VerifyToken(), controllers.GetRecommendedMovies)
+	r.GET("/:id", middlewares.HTTPCache(), controllers.GetMovieByID)
+	r.GET("/:id/reviews", controllers.GetMovieReviews)
+	r.POST("/:id/reviews", middlewares.VerifyToken(), controllers.CreateReview)
+	r.GET("/now-showing", middlewares.HTTPCache(), controllers.GetNowShowing)
+	r.GET("/upcoming", middlewares.HTTPCache(), controllers.GetUpcoming)
 }
@@ -10,8 +10,9 @@ import (
 func adminPaymentMethod(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.POST("", controllers.CreatePaymentMethod)
-	r.GET("", controllers.GetAllPaymentMethod)
+	r.GET("", controllers.GetAdminPaymentMethods)
 	r.DELETE("/:id", controllers.DeletePaymentMethod)
+	r.POST("/:id/restore", controllers.RestorePaymentMethod)
 }
 
 func userPaymentMethod(r *gin.RouterGroup) {
@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+	"be-tickitz/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func promoAdminRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.POST("", controllers.CreatePromo)
+	r.GET("", controllers.GetAllPromos)
+	r.GET("/:id", controllers.GetPromoByID)
+	r.PATCH("/:id", controllers.UpdatePromo)
+	r.DELETE("/:id", controllers.DeletePromo)
+}
+
+func promoRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.POST("/validate", controllers.ValidatePromo)
+}
@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+	"be-tickitz/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func reportAdminRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.GET("/sales", controllers.GetSalesReport)
+	r.GET("/occupancy", controllers.GetOccupancyReport)
+	r.GET("/top-customers", controllers.GetTopCustomers)
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+	"be-tickitz/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func reviewRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.PATCH("/:id", controllers.UpdateReview)
+	r.DELETE("/:id", controllers.DeleteReview)
+}
+
+func reviewAdminRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.GET("", controllers.GetAllReviews)
+	r.PATCH("/:id", controllers.ModerateReview)
+}
@@ -0,0 +1,11 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func searchRouter(r *gin.RouterGroup) {
+	r.GET("", controllers.Search)
+}
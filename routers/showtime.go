@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+	"be-tickitz/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func showtimeAdminRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.POST("", controllers.CreateShowtime)
+	r.DELETE("/:id", controllers.DeleteShowtime)
+}
+
+func showtimePublicRouter(r *gin.RouterGroup) {
+	r.GET("", controllers.GetShowtimes)
+	r.GET("/:id/seats/stream", controllers.GetSeatStream)
+	r.POST("/:id/seats/hold", middlewares.VerifyToken(), controllers.HoldSeats)
+	r.DELETE("/:id/seats/hold", middlewares.VerifyToken(), controllers.ReleaseSeats)
+}
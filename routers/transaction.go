@@ -11,11 +11,16 @@ func TransactionRouter(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.POST("", controllers.CreateTransaction)
   r.GET("", controllers.GetMyTransactions)
+	r.GET("/:id/calendar.ics", controllers.GetBookingCalendar)
+	r.GET("/:id/ticket.pdf", controllers.GetTicketPDF)
+	r.GET("/:id/invoice.pdf", controllers.GetInvoicePDF)
 }
 
 func TransactionAdminRouter(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.GET("", controllers.GetAllTransactions)
+	r.GET("/export", controllers.ExportTransactions)
+	r.PATCH("/:id/status", controllers.UpdateTransactionStatus)
 }
 
 func CheckSeatsRouter(r *gin.RouterGroup){
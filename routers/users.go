@@ -18,4 +18,8 @@ func profileRouter(r *gin.RouterGroup) {
 	r.Use(middlewares.VerifyToken())
 	r.GET("", controllers.GetProfile)
 	r.PATCH("", controllers.UpdateProfile)
+	r.GET("/points", controllers.GetMyPoints)
+	r.GET("/watchlist", controllers.GetWatchlist)
+	r.POST("/watchlist/:movieId", controllers.AddToWatchlist)
+	r.DELETE("/watchlist/:movieId", controllers.RemoveFromWatchlist)
 }
@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"be-tickitz/controllers"
+	"be-tickitz/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func webhookAdminRouter(r *gin.RouterGroup) {
+	r.Use(middlewares.VerifyToken())
+	r.POST("", controllers.CreateWebhook)
+	r.GET("", controllers.GetAllWebhooks)
+	r.GET("/:id", controllers.GetWebhookByID)
+	r.PATCH("/:id", controllers.UpdateWebhook)
+	r.DELETE("/:id", controllers.DeleteWebhook)
+	r.GET("/:id/deliveries", controllers.GetWebhookDeliveries)
+	r.POST("/:id/deliveries/:deliveryId/redeliver", controllers.RedeliverWebhook)
+}
@@ -2,12 +2,327 @@ package utils
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache entries are stored under a key derived from the logical key and the
+// current version of each of its tags. Invalidating a tag only bumps its
+// version, so stale entries are never read again and simply expire with
+// their TTL; no key scan is needed.
+//
+// Redis is the primary store. While Redis is unreachable the cache falls
+// back to an in-process store and retries Redis after a short cool-down.
+
+const (
+	cacheKeyPrefix    = "cache:"
+	cacheTagPrefix    = "cache:tag:"
+	redisRetryAfter   = 10 * time.Second
+	memoryCacheMaxLen = 1000
+)
+
+var (
+	cacheGroup     singleflight.Group
+	redisDownUntil atomic.Int64
+	memory         = newMemoryCache()
+	pending        = &pendingTags{tags: map[string]int64{}}
 )
 
-func DeleteKeysByPrefix(ctx context.Context, prefix string) {
-	iter := RedisClient().Scan(ctx, 0, prefix+"*", 0).Iterator()
-	for iter.Next(ctx) {
-		key := iter.Val()
-		RedisClient().Del(ctx, key)
+// CacheTTL is the default lifetime of cache entries, configured with
+// CACHE_TTL_SECONDS.
+func CacheTTL() time.Duration {
+	return time.Duration(GetEnvInt("CACHE_TTL_SECONDS", 600)) * time.Second
+}
+
+// CacheRemember returns the cached value for key, or calls load and caches
+// its result. Concurrent misses for the same key share one load call. The
+// second return value reports whether the value came from the cache.
+func CacheRemember[T any](ctx context.Context, key string, ttl time.Duration, tags []string, load func() (T, error)) (T, bool, error) {
+	fullKey := cacheKeyPrefix + key + "@" + tagVersionHash(ctx, tags)
+
+	if raw, ok := cacheGet(ctx, fullKey); ok {
+		var cached T
+		if err := json.Unmarshal(raw, &cached); err == nil {
+			return cached, true, nil
+		}
+	}
+
+	value, err, _ := cacheGroup.Do(fullKey, func() (any, error) {
+		loaded, err := load()
+		if err != nil {
+			return loaded, err
+		}
+		if raw, err := json.Marshal(loaded); err == nil {
+			cacheSet(ctx, fullKey, raw, ttl)
+		}
+		return loaded, nil
+	})
+
+	result, _ := value.(T)
+	return result, false, err
+}
+
+// CacheInvalidate expires every entry cached with any of the given tags.
+// Redis is tried even during the cool-down, and bumps that cannot reach it
+// are kept and replayed once it is back, so entries cached before the outage
+// are not served as fresh afterwards.
+func CacheInvalidate(ctx context.Context, tags ...string) {
+	now := time.Now().Unix()
+	for _, tag := range tags {
+		memory.bumpTag(tag)
+	}
+	pending.add(tags, now)
+	flushPendingTags(ctx)
+}
+
+// flushPendingTags bumps the Redis versions of the tags invalidated while
+// Redis could not be reached. It reports whether nothing is left pending.
+func flushPendingTags(ctx context.Context) bool {
+	tags := pending.take()
+	if len(tags) == 0 {
+		return true
+	}
+
+	pipe := RedisClient().Pipeline()
+	for tag, at := range tags {
+		pipe.Incr(ctx, cacheTagPrefix+tag)
+		pipe.Set(ctx, cacheTagPrefix+tag+":at", at, 0)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		markRedisDown(err)
+		for tag, at := range tags {
+			pending.add([]string{tag}, at)
+		}
+		return false
+	}
+	return true
+}
+
+// CacheTagsModifiedAt returns the last time any of the tags was invalidated,
+// or the zero time when none of them has been invalidated yet.
+func CacheTagsModifiedAt(ctx context.Context, tags []string) time.Time {
+	var latest int64
+	fromRedis := false
+	if redisAvailable() && len(tags) > 0 && flushPendingTags(ctx) {
+		keys := make([]string, len(tags))
+		for i, tag := range tags {
+			keys[i] = cacheTagPrefix + tag + ":at"
+		}
+		values, err := RedisClient().MGet(ctx, keys...).Result()
+		if err == nil {
+			fromRedis = true
+			for _, v := range values {
+				if s, ok := v.(string); ok {
+					if at, err := strconv.ParseInt(s, 10, 64); err == nil && at > latest {
+						latest = at
+					}
+				}
+			}
+		} else {
+			markRedisDown(err)
+		}
+	}
+	if !fromRedis {
+		for _, tag := range tags {
+			if at := memory.tagModifiedAt(tag); at > latest {
+				latest = at
+			}
+		}
+	}
+
+	if latest == 0 {
+		return time.Time{}
+	}
+	return time.Unix(latest, 0)
+}
+
+func tagVersionHash(ctx context.Context, tags []string) string {
+	if len(tags) == 0 {
+		return "0"
+	}
+
+	versions := make([]string, len(tags))
+	fromRedis := false
+	if redisAvailable() && flushPendingTags(ctx) {
+		keys := make([]string, len(tags))
+		for i, tag := range tags {
+			keys[i] = cacheTagPrefix + tag
+		}
+		values, err := RedisClient().MGet(ctx, keys...).Result()
+		if err == nil {
+			fromRedis = true
+			for i, v := range values {
+				if s, ok := v.(string); ok {
+					versions[i] = s
+				} else {
+					versions[i] = "0"
+				}
+			}
+		} else {
+			markRedisDown(err)
+		}
+	}
+	if !fromRedis {
+		for i, tag := range tags {
+			versions[i] = "m" + strconv.FormatInt(memory.tagVersion(tag), 10)
+		}
+	}
+
+	sum := sha1.Sum([]byte(strings.Join(tags, ",") + "=" + strings.Join(versions, ",")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func cacheGet(ctx context.Context, key string) ([]byte, bool) {
+	if redisAvailable() {
+		raw, err := RedisClient().Get(ctx, key).Bytes()
+		if err == nil {
+			return raw, true
+		}
+		if errors.Is(err, redis.Nil) {
+			return nil, false
+		}
+		markRedisDown(err)
+	}
+	return memory.get(key)
+}
+
+func cacheSet(ctx context.Context, key string, raw []byte, ttl time.Duration) {
+	if redisAvailable() {
+		err := RedisClient().Set(ctx, key, raw, ttl).Err()
+		if err == nil {
+			return
+		}
+		markRedisDown(err)
+	}
+	memory.set(key, raw, ttl)
+}
+
+func redisAvailable() bool {
+	return time.Now().UnixNano() >= redisDownUntil.Load()
+}
+
+func markRedisDown(err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	log.Println("Redis unavailable, using in-memory cache:", err.Error())
+	redisDownUntil.Store(time.Now().Add(redisRetryAfter).UnixNano())
+}
+
+// pendingTags holds the tags invalidated while Redis was unreachable, with
+// the time of their last invalidation.
+type pendingTags struct {
+	mu   sync.Mutex
+	tags map[string]int64
+}
+
+func (p *pendingTags) add(tags []string, at int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, tag := range tags {
+		p.tags[tag] = max(p.tags[tag], at)
+	}
+}
+
+func (p *pendingTags) take() map[string]int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.tags) == 0 {
+		return nil
+	}
+	tags := p.tags
+	p.tags = map[string]int64{}
+	return tags
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+type memoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	tags       map[string]int64
+	modifiedAt map[string]int64
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{
+		entries:    map[string]memoryEntry{},
+		tags:       map[string]int64{},
+		modifiedAt: map[string]int64{},
+	}
+}
+
+func (m *memoryCache) get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(m.entries, key)
+		return nil, false
 	}
+	return entry.value, true
+}
+
+func (m *memoryCache) set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.entries) >= memoryCacheMaxLen {
+		now := time.Now()
+		for k, e := range m.entries {
+			if !e.expires.IsZero() && now.After(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		// Still full: drop an arbitrary entry to make room.
+		for k := range m.entries {
+			if len(m.entries) < memoryCacheMaxLen {
+				break
+			}
+			delete(m.entries, k)
+		}
+	}
+
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	m.entries[key] = entry
+}
+
+func (m *memoryCache) tagVersion(tag string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tags[tag]
+}
+
+func (m *memoryCache) bumpTag(tag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tags[tag]++
+	m.modifiedAt[tag] = time.Now().Unix()
+}
+
+func (m *memoryCache) tagModifiedAt(tag string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.modifiedAt[tag]
 }
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPendingTagsKeepLatestInvalidation(t *testing.T) {
+	p := &pendingTags{tags: map[string]int64{}}
+	p.add([]string{"movies", "genres"}, 20)
+	p.add([]string{"movies"}, 10)
+
+	tags := p.take()
+	if tags["movies"] != 20 || tags["genres"] != 20 || len(tags) != 2 {
+		t.Fatalf("take() = %v, want movies and genres at 20", tags)
+	}
+	if again := p.take(); again != nil {
+		t.Fatalf("second take() = %v, want nothing pending", again)
+	}
+}
+
+func TestCacheInvalidateKeepsTagsWhileRedisIsDown(t *testing.T) {
+	t.Setenv("RDADDRESS", "127.0.0.1:1")
+	redisDownUntil.Store(0)
+	t.Cleanup(func() {
+		pending.take()
+		redisDownUntil.Store(0)
+	})
+
+	before := memory.tagVersion("cache-test")
+	CacheInvalidate(context.Background(), "cache-test")
+	CacheInvalidate(context.Background(), "cache-test")
+
+	if got := memory.tagVersion("cache-test"); got != before+2 {
+		t.Fatalf("memory tag version = %d, want %d", got, before+2)
+	}
+	if redisAvailable() {
+		t.Fatal("Redis should be marked down after a failed invalidation")
+	}
+	if tags := pending.take(); tags["cache-test"] == 0 {
+		t.Fatalf("pending tags = %v, want cache-test kept for replay", tags)
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CalendarEvent is a single event in an iCalendar (RFC 5545) file.
+type CalendarEvent struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Location    string
+	Description string
+	// Status is CONFIRMED, TENTATIVE or CANCELLED.
+	Status string
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// NewICS renders event as an iCalendar file. Times are written in UTC, so
+// calendar apps show them in the reader's own time zone.
+func NewICS(event CalendarEvent) []byte {
+	stamp := func(t time.Time) string { return t.UTC().Format("20060102T150405Z") }
+
+	var b strings.Builder
+	line := func(name, value string) {
+		writeICSLine(&b, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Tickitz//Booking//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("BEGIN", "VEVENT")
+	line("UID", event.UID)
+	line("DTSTAMP", stamp(time.Now()))
+	line("DTSTART", stamp(event.Start))
+	line("DTEND", stamp(event.End))
+	line("SUMMARY", icsEscaper.Replace(event.Summary))
+	if event.Location != "" {
+		line("LOCATION", icsEscaper.Replace(event.Location))
+	}
+	if event.Description != "" {
+		line("DESCRIPTION", icsEscaper.Replace(event.Description))
+	}
+	if event.Status != "" {
+		line("STATUS", event.Status)
+	}
+	line("END", "VEVENT")
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// writeICSLine ends a content line with CRLF, folding it so no line is longer
+// than 75 octets. Folds never split a UTF-8 character.
+func writeICSLine(b *strings.Builder, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		fmt.Fprintf(b, "%s\r\n ", s[:cut])
+		s = s[cut:]
+		// The leading space of a continuation line counts toward its length.
+		limit = 74
+	}
+	b.WriteString(s + "\r\n")
+}
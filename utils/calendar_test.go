@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestNewICS(t *testing.T) {
+	start := time.Date(2025, 7, 30, 19, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+	ics := string(NewICS(CalendarEvent{
+		UID:         "booking-42@tickitz",
+		Start:       start,
+		End:         start.Add(2 * time.Hour),
+		Summary:     "Dune; Part Two, IMAX",
+		Location:    `Hall 1\Jakarta`,
+		Description: "Seats: A1, A2\nShow your e-ticket at the entrance",
+		Status:      "CONFIRMED",
+	}))
+
+	if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+		t.Fatalf("not a calendar:\n%s", ics)
+	}
+	if strings.Contains(strings.ReplaceAll(ics, "\r\n", ""), "\n") {
+		t.Fatal("lines must end with CRLF")
+	}
+
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	for _, want := range []string{
+		"DTSTART:20250730T123000Z\r\n",
+		"DTEND:20250730T143000Z\r\n",
+		`SUMMARY:Dune\; Part Two\, IMAX` + "\r\n",
+		`LOCATION:Hall 1\\Jakarta` + "\r\n",
+		`DESCRIPTION:Seats: A1\, A2\nShow your e-ticket at the entrance` + "\r\n",
+		"STATUS:CONFIRMED\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("missing %q in\n%s", want, unfolded)
+		}
+	}
+}
+
+func TestWriteICSLineFolds(t *testing.T) {
+	// Multi-byte characters straddle every possible fold position.
+	value := "DESCRIPTION:" + strings.Repeat("Sélamat menonton 🎬 ", 20)
+
+	var b strings.Builder
+	writeICSLine(&b, value)
+	out := b.String()
+
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("a %d octet line should be folded", len(value))
+	}
+	for i, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line %d is %d octets, want at most 75", i, len(line))
+		}
+		if i > 0 && !strings.HasPrefix(line, " ") {
+			t.Errorf("continuation line %d should start with a space", i)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != value {
+		t.Errorf("unfolding does not give the line back:\n%q\n%q", unfolded, value)
+	}
+
+	b.Reset()
+	writeICSLine(&b, strings.Repeat("x", 75))
+	if b.String() != strings.Repeat("x", 75)+"\r\n" {
+		t.Errorf("a 75 octet line should not be folded")
+	}
+}
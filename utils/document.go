@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// DocumentImage is an image ready to be placed in a PDF. Type is the fpdf
+// image type, JPG for fetched images.
+type DocumentImage struct {
+	Data []byte
+	Type string
+}
+
+// ErrImageAddress is returned for an image URL pointing at an address that is
+// not on the public internet.
+var ErrImageAddress = errors.New("image URL must point at a public address")
+
+const (
+	maxImageBytes = 5 << 20
+	// maxImagePixels keeps out images that are small to download but huge
+	// once decoded.
+	maxImagePixels = 40_000_000
+	// thumbnailWidth is what images are scaled down to before they are
+	// cached, plenty for the few centimetres a poster takes on a ticket.
+	thumbnailWidth = 300
+)
+
+// imageClient only connects to public addresses. Poster URLs come from
+// imports, so without the check a document could be made to fetch from the
+// server's own network.
+var imageClient = &http.Client{
+	Timeout: 5 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: checkImageDial,
+		}).DialContext,
+	},
+}
+
+// checkImageDial runs on every connection attempt, including redirects,
+// after the host has been resolved.
+func checkImageDial(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrImageAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// FetchImage downloads an image, e.g. a movie poster, to put in a PDF. Only a
+// JPEG thumbnail of it is kept, cached for a day by URL; posters get a new
+// URL when they change.
+func FetchImage(url string) (DocumentImage, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return DocumentImage{}, fmt.Errorf("unsupported image url %q", url)
+	}
+
+	img, _, err := CacheRemember(context.Background(), "image:"+url, 24*time.Hour, nil, func() (DocumentImage, error) {
+		resp, err := imageClient.Get(url)
+		if err != nil {
+			return DocumentImage{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return DocumentImage{}, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+		if err != nil {
+			return DocumentImage{}, err
+		}
+		if len(data) > maxImageBytes {
+			return DocumentImage{}, fmt.Errorf("image %s is too large", url)
+		}
+		return thumbnail(data)
+	})
+	return img, err
+}
+
+// thumbnail decodes a JPEG, PNG or GIF image and encodes it again as a JPEG at
+// most thumbnailWidth pixels wide. Each pixel is the average of the source
+// pixels it covers, laid over white where the source is transparent.
+func thumbnail(data []byte) (DocumentImage, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return DocumentImage{}, fmt.Errorf("unsupported image: %v", err)
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return DocumentImage{}, fmt.Errorf("image of %dx%d pixels is too large", cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return DocumentImage{}, fmt.Errorf("unsupported image: %v", err)
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > thumbnailWidth {
+		w, h = thumbnailWidth, max(h*thumbnailWidth/w, 1)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/h, y0+1)
+		for x := range w {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(b.Min.X+(x+1)*b.Dx()/w, x0+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			white := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + white),
+				G: uint16(g/n + white),
+				B: uint16(bl/n + white),
+				A: 0xffff,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return DocumentImage{}, err
+	}
+	return DocumentImage{Data: buf.Bytes(), Type: "JPG"}, nil
+}
+
+// QRCodePNG encodes content as a size x size pixel QR code.
+func QRCodePNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
+
+// TicketCode is the code printed as QR on e-tickets. It carries an HMAC of the
+// transaction ID signed with APP_SECRET, so a made up code can be told apart
+// from a real one at the cinema.
+func TicketCode(transactionID int) string {
+	godotenv.Load()
+	mac := hmac.New(sha256.New, []byte(os.Getenv("APP_SECRET")))
+	fmt.Fprintf(mac, "ticket:%d", transactionID)
+	return fmt.Sprintf("TKZ-%d-%s", transactionID, strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:12]))
+}
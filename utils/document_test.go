@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestTicketCode(t *testing.T) {
+	t.Setenv("APP_SECRET", "first-secret")
+	code := TicketCode(42)
+	if !regexp.MustCompile(`^TKZ-42-[0-9A-F]{12}$`).MatchString(code) {
+		t.Fatalf("TicketCode(42) = %q, want TKZ-42- and 12 hex digits", code)
+	}
+	if TicketCode(42) != code {
+		t.Fatal("the code of a transaction should not change")
+	}
+	if TicketCode(43)[7:] == code[7:] {
+		t.Fatal("transactions should get different signatures")
+	}
+
+	t.Setenv("APP_SECRET", "second-secret")
+	if TicketCode(42) == code {
+		t.Fatal("the signature should depend on APP_SECRET")
+	}
+}
+
+func TestQRCodePNG(t *testing.T) {
+	png, err := QRCodePNG("TKZ-42-ABCDEF012345", 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(png) < 8 || string(png[1:4]) != "PNG" {
+		t.Fatalf("QRCodePNG did not return a PNG image")
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 600, 900))
+	for y := range 900 {
+		for x := range 300 {
+			src.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var data bytes.Buffer
+	if err := png.Encode(&data, src); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := thumbnail(data.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Type != "JPG" {
+		t.Errorf("type = %s, want JPG", img.Type)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := thumb.Bounds().Size(); size != image.Pt(300, 450) {
+		t.Errorf("size = %v, want 300x450", size)
+	}
+	if r, g, _, _ := thumb.At(50, 200).RGBA(); r>>8 < 190 || g>>8 > 20 {
+		t.Errorf("left half = %v, want red", thumb.At(50, 200))
+	}
+	if r, g, b, _ := thumb.At(250, 200).RGBA(); r>>8 < 245 || g>>8 < 245 || b>>8 < 245 {
+		t.Errorf("transparent half = %v, want white", thumb.At(250, 200))
+	}
+
+	if _, err := thumbnail([]byte("<svg></svg>")); err == nil {
+		t.Error("an SVG should be rejected")
+	}
+}
+
+func TestFetchImageRefusesLocalAddresses(t *testing.T) {
+	t.Setenv("RDADDRESS", "127.0.0.1:1")
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	if _, err := FetchImage(server.URL + "/poster.png"); !errors.Is(err, ErrImageAddress) {
+		t.Fatalf("FetchImage() = %v, want ErrImageAddress", err)
+	}
+	if called {
+		t.Fatal("the local server should not have been reached")
+	}
+}
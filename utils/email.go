@@ -1,29 +1,17 @@
 package utils
 
-import (
-	"os"
-
-	"gopkg.in/gomail.v2"
-
-	"github.com/joho/godotenv"
-)
+import "github.com/joho/godotenv"
 
+// SendEmail sends an HTML email right away with the default mailer. Anything
+// sent on behalf of a request should go through the outbox instead (see
+// models.QueueEmail) so a mail server outage does not fail the request.
 func SendEmail(to, subject, body string) error {
-	godotenv.Load()
-
-	smtpHost := "smtp.gmail.com"
-	smtpPort := 587
-
-	sender := os.Getenv("EMAIL_SENDER")
-	password := os.Getenv("EMAIL_PASSWORD")
-
-	msg := gomail.NewMessage()
-	msg.SetHeader("From", sender)
-	msg.SetHeader("To", to)
-	msg.SetHeader("Subject", subject)
-	msg.SetBody("text/html", body)
-
-	dialer := gomail.NewDialer(smtpHost, smtpPort, sender, password)
+	return SendEmailMessage(to, EmailMessage{Subject: subject, HTML: body})
+}
 
-	return dialer.DialAndSend(msg)
+// SendEmailMessage sends a rendered template right away with the default
+// mailer, as a multipart email when it has a plain text body.
+func SendEmailMessage(to string, msg EmailMessage) error {
+	godotenv.Load()
+	return DefaultMailer().Send(to, msg)
 }
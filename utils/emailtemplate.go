@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// Email templates live in templates/email/<lang>/ as a pair of files per
+// email: <name>.html, rendered inside layout.html, and <name>.txt, the plain
+// text alternative. The .txt file also defines the "subject" template.
+// partials.html and partials.txt hold blocks shared by a language.
+//
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// EmailMessage is a rendered email with both its HTML and plain text body.
+type EmailMessage struct {
+	Subject     string
+	HTML        string
+	Text        string
+	Attachments []EmailAttachment
+}
+
+// EmailAttachment is a file sent along with an email. It is stored as JSON in
+// the outbox, where Content ends up base64 encoded.
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var emailTemplateCache sync.Map
+
+// DefaultEmailLanguage is the language used when a user has none or asks for
+// one without templates, configured with EMAIL_DEFAULT_LANGUAGE.
+func DefaultEmailLanguage() string {
+	if lang := os.Getenv("EMAIL_DEFAULT_LANGUAGE"); EmailLanguageSupported(lang) {
+		return lang
+	}
+	return "en"
+}
+
+// EmailLanguages lists the languages email templates exist for.
+func EmailLanguages() []string {
+	entries, _ := fs.ReadDir(emailTemplateFS, "templates/email")
+	langs := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			langs = append(langs, e.Name())
+		}
+	}
+	return langs
+}
+
+func EmailLanguageSupported(lang string) bool {
+	if lang == "" || strings.ContainsAny(lang, "./\\") {
+		return false
+	}
+	info, err := fs.Stat(emailTemplateFS, "templates/email/"+lang)
+	return err == nil && info.IsDir()
+}
+
+// RenderEmail renders the email called name in lang, falling back to the
+// default language. data is passed to the subject, HTML and text templates.
+func RenderEmail(name, lang string, data any) (EmailMessage, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	if !EmailLanguageSupported(lang) {
+		lang = DefaultEmailLanguage()
+	}
+
+	tmpl, err := loadEmailTemplate(name, lang)
+	if err != nil {
+		return EmailMessage{}, err
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return EmailMessage{}, fmt.Errorf("failed to render %s subject: %v", name, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return EmailMessage{}, fmt.Errorf("failed to render %s text: %v", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return EmailMessage{}, fmt.Errorf("failed to render %s html: %v", name, err)
+	}
+
+	return EmailMessage{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
+
+func loadEmailTemplate(name, lang string) (emailTemplate, error) {
+	key := lang + "/" + name
+	if cached, ok := emailTemplateCache.Load(key); ok {
+		return cached.(emailTemplate), nil
+	}
+
+	dir := "templates/email/" + lang + "/"
+	funcs := emailTemplateFuncs(lang)
+
+	html, err := htmltemplate.New("layout.html").Funcs(htmltemplate.FuncMap(funcs)).ParseFS(emailTemplateFS,
+		"templates/email/layout.html", dir+"partials.html", dir+name+".html")
+	if err != nil {
+		return emailTemplate{}, fmt.Errorf("failed to load %s email template: %v", key, err)
+	}
+	text, err := texttemplate.New(name + ".txt").Funcs(funcs).ParseFS(emailTemplateFS,
+		dir+"partials.txt", dir+name+".txt")
+	if err != nil {
+		return emailTemplate{}, fmt.Errorf("failed to load %s email template: %v", key, err)
+	}
+
+	tmpl := emailTemplate{html: html, text: text}
+	emailTemplateCache.Store(key, tmpl)
+	return tmpl, nil
+}
+
+var (
+	indonesianMonths = []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli",
+		"Agustus", "September", "Oktober", "November", "Desember"}
+	indonesianDays = []string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+)
+
+// emailTemplateFuncs are the helpers available to templates, formatting dates
+// and amounts the way lang writes them.
+func emailTemplateFuncs(lang string) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"date": func(t time.Time) string {
+			if lang == "id" {
+				return fmt.Sprintf("%s, %d %s %d", indonesianDays[t.Weekday()], t.Day(), indonesianMonths[t.Month()-1], t.Year())
+			}
+			return t.Format("Monday, January 2, 2006")
+		},
+		"rupiah": FormatRupiah,
+		"join":   strings.Join,
+	}
+}
+
+// FormatRupiah writes amount the Indonesian way, e.g. Rp150.000.
+func FormatRupiah(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := strconv.Itoa(amount)
+	var out strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteByte('.')
+		}
+		out.WriteRune(d)
+	}
+	return sign + "Rp" + out.String()
+}
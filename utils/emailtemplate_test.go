@@ -0,0 +1,20 @@
+package utils
+
+import "testing"
+
+func TestFormatRupiah(t *testing.T) {
+	tests := map[int]string{
+		0:          "Rp0",
+		999:        "Rp999",
+		1000:       "Rp1.000",
+		150000:     "Rp150.000",
+		1234567:    "Rp1.234.567",
+		-45000:     "-Rp45.000",
+		1000000000: "Rp1.000.000.000",
+	}
+	for amount, want := range tests {
+		if got := FormatRupiah(amount); got != want {
+			t.Errorf("FormatRupiah(%d) = %q, want %q", amount, got, want)
+		}
+	}
+}
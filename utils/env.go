@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+func GetEnvInt(key string, fallback int) int {
+	godotenv.Load()
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
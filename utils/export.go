@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportFormats lists the file formats export endpoints can produce.
+var ExportFormats = []string{"csv", "xlsx"}
+
+// ExportWriter writes a table row by row. Ints and floats become numbers in
+// spreadsheets, times are written as "2006-01-02 15:04:05" and everything else
+// as text. Close must be called to finish the file.
+type ExportWriter interface {
+	WriteRow(values ...any) error
+	Close() error
+}
+
+// NewExportWriter returns a writer producing format ("csv" or "xlsx") on w.
+func NewExportWriter(w io.Writer, format string) (ExportWriter, error) {
+	switch format {
+	case "csv":
+		return &csvExportWriter{w: csv.NewWriter(w)}, nil
+	case "xlsx":
+		return newXLSXExportWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// StartExport sends the download headers for a file called name-YYYYMMDD.format
+// and returns a writer streaming the file as the response body.
+func StartExport(c *gin.Context, format, name string) (ExportWriter, error) {
+	w, err := NewExportWriter(c.Writer, format)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, name, time.Now().Format("20060102"), format))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	return w, nil
+}
+
+// exportCell formats a value and tells whether it is a number.
+func exportCell(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case time.Time:
+		if v.IsZero() {
+			return "", false
+		}
+		return v.Format("2006-01-02 15:04:05"), false
+	case *time.Time:
+		if v == nil {
+			return "", false
+		}
+		return exportCell(*v)
+	case []string:
+		return strings.Join(v, ", "), false
+	case string:
+		return v, false
+	}
+	return fmt.Sprint(v), false
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e *csvExportWriter) WriteRow(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		cell, number := exportCell(v)
+		// Text starting like a formula is prefixed so spreadsheet apps do not
+		// evaluate it when the file is opened.
+		if !number && cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cell = "'" + cell
+		}
+		record[i] = cell
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExportWriter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// xlsxExportWriter writes a single sheet workbook. The sheet is the last part
+// of the zip archive, so rows go straight to the output as they are written.
+type xlsxExportWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return &xlsxExportWriter{zip: zw, sheet: sheet}, nil
+}
+
+func (e *xlsxExportWriter) WriteRow(values ...any) error {
+	e.rows++
+	fmt.Fprintf(e.sheet, `<row r="%d">`, e.rows)
+	for _, v := range values {
+		cell, number := exportCell(v)
+		if number {
+			fmt.Fprintf(e.sheet, `<c><v>%s</v></c>`, cell)
+			continue
+		}
+		e.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(e.sheet, []byte(cell)); err != nil {
+			return err
+		}
+		e.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := e.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (e *xlsxExportWriter) Close() error {
+	e.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zip.Close()
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const lastModifiedKey = "lastModified"
+
+// SetLastModified records when the data behind the response last changed, for
+// the HTTPCache middleware to send as Last-Modified. Later calls only move the
+// time forward.
+func SetLastModified(c *gin.Context, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if current, ok := GetLastModified(c); ok && current.After(t) {
+		return
+	}
+	c.Set(lastModifiedKey, t)
+}
+
+func GetLastModified(c *gin.Context) (time.Time, bool) {
+	value, ok := c.Get(lastModifiedKey)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, ok := value.(time.Time)
+	return t, ok && !t.IsZero()
+}
+
+// SetCacheStatus reports through X-Cache whether the body was served from the
+// application cache. It is a header rather than part of the body so the body,
+// and therefore its ETag, is the same either way.
+func SetCacheStatus(c *gin.Context, fromCache bool) {
+	if fromCache {
+		c.Header("X-Cache", "HIT")
+	} else {
+		c.Header("X-Cache", "MISS")
+	}
+}
@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Mailer delivers a single email. Drivers are picked with MAIL_DRIVER:
+//
+//	smtp     send through MAIL_SMTP_HOST:MAIL_SMTP_PORT (default)
+//	log      print the message to the application log
+//	file     write each message as an .eml file in MAIL_FILE_DIR
+//	capture  keep messages in memory, for tests
+type Mailer interface {
+	Send(to string, msg EmailMessage) error
+}
+
+var (
+	mailerMu      sync.Mutex
+	defaultMailer Mailer
+)
+
+// InitMailer sets up the default mailer from the environment. It fails on an
+// unknown MAIL_DRIVER, so a typo stops the server at startup instead of
+// sending mail nowhere.
+func InitMailer() error {
+	m, err := NewMailerFromEnv()
+	if err != nil {
+		return err
+	}
+	SetMailer(m)
+	return nil
+}
+
+// DefaultMailer returns the mailer configured by the environment, created on
+// first use. With an unknown driver every send fails, so the outbox keeps
+// the mail instead of marking it sent.
+func DefaultMailer() Mailer {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	if defaultMailer == nil {
+		m, err := NewMailerFromEnv()
+		if err != nil {
+			m = failingMailer{err}
+		}
+		defaultMailer = m
+	}
+	return defaultMailer
+}
+
+// failingMailer fails every send with the error that kept the configured
+// mailer from being created.
+type failingMailer struct {
+	err error
+}
+
+func (m failingMailer) Send(string, EmailMessage) error {
+	return m.err
+}
+
+// SetMailer replaces the default mailer, e.g. with a CaptureMailer in tests.
+func SetMailer(m Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	defaultMailer = m
+}
+
+func NewMailerFromEnv() (Mailer, error) {
+	switch driver := strings.ToLower(os.Getenv("MAIL_DRIVER")); driver {
+	case "", "smtp":
+		return NewSMTPMailerFromEnv(), nil
+	case "log":
+		return LogMailer{}, nil
+	case "file":
+		dir := os.Getenv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return FileMailer{Dir: dir}, nil
+	case "capture":
+		return &CaptureMailer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+func mailFrom() string {
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		return from
+	}
+	return os.Getenv("EMAIL_SENDER")
+}
+
+func newMailMessage(to string, msg EmailMessage) *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", mailFrom())
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", msg.Subject)
+	if msg.Text != "" {
+		m.SetBody("text/plain", msg.Text)
+		m.AddAlternative("text/html", msg.HTML)
+	} else {
+		m.SetBody("text/html", msg.HTML)
+	}
+	for _, a := range msg.Attachments {
+		content := a.Content
+		m.Attach(a.Filename,
+			gomail.SetHeader(map[string][]string{"Content-Type": {a.ContentType}}),
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(content)
+				return err
+			}))
+	}
+	return m
+}
+
+// SMTPMailer sends through an SMTP server. TLS is "starttls", which upgrades
+// the connection when the server offers it, or "tls" for implicit TLS
+// (usually port 465).
+type SMTPMailer struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	TLS        string
+	SkipVerify bool
+}
+
+// NewSMTPMailerFromEnv reads MAIL_SMTP_HOST, MAIL_SMTP_PORT, MAIL_SMTP_TLS,
+// MAIL_SMTP_SKIP_VERIFY and the MAIL_SMTP_USERNAME/PASSWORD credentials,
+// which default to EMAIL_SENDER and EMAIL_PASSWORD.
+func NewSMTPMailerFromEnv() SMTPMailer {
+	m := SMTPMailer{
+		Host:       os.Getenv("MAIL_SMTP_HOST"),
+		Port:       GetEnvInt("MAIL_SMTP_PORT", 587),
+		Username:   os.Getenv("MAIL_SMTP_USERNAME"),
+		Password:   os.Getenv("MAIL_SMTP_PASSWORD"),
+		TLS:        strings.ToLower(os.Getenv("MAIL_SMTP_TLS")),
+		SkipVerify: os.Getenv("MAIL_SMTP_SKIP_VERIFY") == "true",
+	}
+	if m.Host == "" {
+		m.Host = "smtp.gmail.com"
+	}
+	if m.Username == "" {
+		m.Username = os.Getenv("EMAIL_SENDER")
+	}
+	if m.Password == "" {
+		m.Password = os.Getenv("EMAIL_PASSWORD")
+	}
+	if m.TLS == "" {
+		m.TLS = "starttls"
+	}
+	return m
+}
+
+func (m SMTPMailer) Send(to string, msg EmailMessage) error {
+	dialer := gomail.NewDialer(m.Host, m.Port, m.Username, m.Password)
+	dialer.SSL = m.TLS == "tls"
+	dialer.TLSConfig = &tls.Config{ServerName: m.Host, InsecureSkipVerify: m.SkipVerify}
+	return dialer.DialAndSend(newMailMessage(to, msg))
+}
+
+// LogMailer prints messages instead of sending them.
+type LogMailer struct{}
+
+func (LogMailer) Send(to string, msg EmailMessage) error {
+	body := msg.Text
+	if body == "" {
+		body = msg.HTML
+	}
+	for _, a := range msg.Attachments {
+		body += fmt.Sprintf("\n[attachment %s, %d bytes]", a.Filename, len(a.Content))
+	}
+	log.Printf("Mail to %s: %s\n%s", to, msg.Subject, body)
+	return nil
+}
+
+// FileMailer writes every message as a complete .eml file into Dir, which
+// can be opened with any mail client.
+type FileMailer struct {
+	Dir string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]+`)
+
+func (m FileMailer) Send(to string, msg EmailMessage) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), unsafeFileChars.ReplaceAllString(to, "_"))
+	f, err := os.Create(filepath.Join(m.Dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = newMailMessage(to, msg).WriteTo(f)
+	return err
+}
+
+// CapturedEmail is a message kept by CaptureMailer.
+type CapturedEmail struct {
+	To      string
+	Message EmailMessage
+}
+
+// CaptureMailer keeps sent messages in memory. When Err is set every send
+// fails with it, to exercise retries.
+type CaptureMailer struct {
+	mu   sync.Mutex
+	sent []CapturedEmail
+	Err  error
+}
+
+func (m *CaptureMailer) Send(to string, msg EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.sent = append(m.sent, CapturedEmail{To: to, Message: msg})
+	return nil
+}
+
+// Sent returns a copy of the messages captured so far.
+func (m *CaptureMailer) Sent() []CapturedEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CapturedEmail(nil), m.sent...)
+}
+
+func (m *CaptureMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = nil
+}
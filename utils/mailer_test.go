@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewMailerFromEnv(t *testing.T) {
+	tests := map[string]Mailer{
+		"":        SMTPMailer{},
+		"SMTP":    SMTPMailer{},
+		"log":     LogMailer{},
+		"file":    FileMailer{},
+		"capture": &CaptureMailer{},
+	}
+	for driver, want := range tests {
+		t.Setenv("MAIL_DRIVER", driver)
+		m, err := NewMailerFromEnv()
+		if err != nil {
+			t.Fatalf("MAIL_DRIVER=%q: %v", driver, err)
+		}
+		if got, want := fmt.Sprintf("%T", m), fmt.Sprintf("%T", want); got != want {
+			t.Errorf("MAIL_DRIVER=%q gave %s, want %s", driver, got, want)
+		}
+	}
+
+	t.Setenv("MAIL_DRIVER", "pigeon")
+	if _, err := NewMailerFromEnv(); err == nil {
+		t.Error("an unknown driver should be an error")
+	}
+}
+
+func TestUnknownMailDriver(t *testing.T) {
+	t.Setenv("MAIL_DRIVER", "pigeon")
+	t.Cleanup(func() { SetMailer(nil) })
+
+	if err := InitMailer(); err == nil {
+		t.Error("InitMailer should refuse an unknown driver")
+	}
+
+	SetMailer(nil)
+	if err := DefaultMailer().Send("someone@tickitz.test", EmailMessage{Subject: "Reset"}); err == nil {
+		t.Error("sending with an unknown driver should fail instead of being logged")
+	}
+}
+
+func TestNewSMTPMailerFromEnv(t *testing.T) {
+	t.Setenv("MAIL_SMTP_HOST", "")
+	t.Setenv("MAIL_SMTP_PORT", "")
+	t.Setenv("MAIL_SMTP_TLS", "")
+	t.Setenv("MAIL_SMTP_USERNAME", "")
+	t.Setenv("MAIL_SMTP_PASSWORD", "")
+	t.Setenv("EMAIL_SENDER", "tickets@example.com")
+	t.Setenv("EMAIL_PASSWORD", "app-password")
+
+	m := NewSMTPMailerFromEnv()
+	want := SMTPMailer{Host: "smtp.gmail.com", Port: 587, Username: "tickets@example.com", Password: "app-password", TLS: "starttls"}
+	if m != want {
+		t.Fatalf("defaults = %+v, want %+v", m, want)
+	}
+
+	t.Setenv("MAIL_SMTP_HOST", "mail.example.com")
+	t.Setenv("MAIL_SMTP_PORT", "465")
+	t.Setenv("MAIL_SMTP_TLS", "TLS")
+	t.Setenv("MAIL_SMTP_USERNAME", "relay")
+	if m := NewSMTPMailerFromEnv(); m.Host != "mail.example.com" || m.Port != 465 || m.TLS != "tls" || m.Username != "relay" {
+		t.Fatalf("configured = %+v", m)
+	}
+}
+
+func TestCaptureMailer(t *testing.T) {
+	m := &CaptureMailer{}
+	msg := EmailMessage{Subject: "Your booking", Text: "See you"}
+	if err := m.Send("a@example.com", msg); err != nil {
+		t.Fatal(err)
+	}
+	sent := m.Sent()
+	if len(sent) != 1 || sent[0].To != "a@example.com" || sent[0].Message.Subject != "Your booking" {
+		t.Fatalf("sent = %+v", sent)
+	}
+
+	m.Err = errors.New("mail server down")
+	if err := m.Send("b@example.com", msg); !errors.Is(err, m.Err) {
+		t.Fatalf("Send with Err set = %v, want it returned", err)
+	}
+	if len(m.Sent()) != 1 {
+		t.Fatal("a failed send should not be captured")
+	}
+
+	m.Reset()
+	if len(m.Sent()) != 0 {
+		t.Fatal("Reset should forget captured messages")
+	}
+}
+
+func TestFileMailerWritesEML(t *testing.T) {
+	t.Setenv("MAIL_FROM", "Tickitz <no-reply@tickitz.test>")
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := FileMailer{Dir: dir}
+
+	err := m.Send("sri/../rahayu@example.com", EmailMessage{
+		Subject:     "Booking confirmed",
+		HTML:        "<p>Enjoy the movie</p>",
+		Text:        "Enjoy the movie",
+		Attachments: []EmailAttachment{{Filename: "booking.ics", ContentType: "text/calendar", Content: []byte("BEGIN:VCALENDAR")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("got files %v, want one .eml in the mail dir", files)
+	}
+	if name := filepath.Base(files[0]); strings.Contains(name, "/") || !strings.HasSuffix(name, "-sri_.._rahayu@example.com.eml") {
+		t.Errorf("file name %q should carry the sanitized recipient", name)
+	}
+	raw, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	eml := string(raw)
+	for _, want := range []string{
+		"Subject: Booking confirmed", "<no-reply@tickitz.test>", "multipart/alternative",
+		"text/plain", "text/html", `filename="booking.ics"`,
+	} {
+		if !strings.Contains(eml, want) {
+			t.Errorf("message is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const MaxPageLimit = 100
+
+// PageQuery holds the paging, sorting and filtering parameters shared by all
+// list endpoints:
+//
+//	?page=2&limit=20            offset mode
+//	?limit=20&cursor=<token>    cursor mode, token taken from pageInfo.nextCursor
+//	?sort=title or ?sort=-title sort key, "-" prefix (or order=desc) for descending
+//	?search=...                 free text filter
+//
+// A list is only paged when page, limit or cursor is given (or the endpoint
+// has a default limit); otherwise every row is returned.
+type PageQuery struct {
+	Page    int
+	Limit   int
+	Cursor  string
+	Sort    string
+	Desc    bool
+	Search  string
+	Filters url.Values
+}
+
+type PageInfo struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+	TotalPages int    `json:"totalPages,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// PageResult is what a model returns next to the rows of a page.
+type PageResult struct {
+	Total      int
+	NextCursor string
+}
+
+func ParsePageQuery(c *gin.Context, defaultLimit int) PageQuery {
+	q := PageQuery{
+		Cursor:  c.Query("cursor"),
+		Search:  strings.TrimSpace(c.Query("search")),
+		Filters: c.Request.URL.Query(),
+	}
+
+	q.Page, _ = strconv.Atoi(c.Query("page"))
+	q.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	if q.Limit <= 0 && (q.Page > 0 || q.Cursor != "") {
+		q.Limit = 20
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	if q.Limit > MaxPageLimit {
+		q.Limit = MaxPageLimit
+	}
+	if q.Limit > 0 && q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.Cursor != "" {
+		q.Page = 0
+	}
+
+	sort := c.Query("sort")
+	if strings.HasPrefix(sort, "-") {
+		q.Desc = true
+		sort = strings.TrimPrefix(sort, "-")
+	}
+	if strings.EqualFold(c.Query("order"), "desc") {
+		q.Desc = true
+	}
+	q.Sort = sort
+
+	return q
+}
+
+func (q PageQuery) Paged() bool {
+	return q.Limit > 0
+}
+
+func (q PageQuery) Offset() int {
+	if q.Page > 1 {
+		return (q.Page - 1) * q.Limit
+	}
+	return 0
+}
+
+type cursorToken struct {
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func EncodeCursor(value string, id int) string {
+	raw, _ := json.Marshal(cursorToken{Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(cursor string) (string, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	return token.Value, token.ID, nil
+}
+
+// NewPageInfo builds the pageInfo block with next/prev links relative to the
+// current request.
+func NewPageInfo(c *gin.Context, q PageQuery, result PageResult) *PageInfo {
+	info := &PageInfo{Total: result.Total}
+	if !q.Paged() {
+		return info
+	}
+
+	info.Limit = q.Limit
+	info.NextCursor = result.NextCursor
+
+	if q.Cursor != "" {
+		if result.NextCursor != "" {
+			info.Next = pageLink(c, map[string]string{"cursor": result.NextCursor, "page": ""})
+		}
+		return info
+	}
+
+	info.Page = q.Page
+	info.TotalPages = (result.Total + q.Limit - 1) / q.Limit
+	if q.Page < info.TotalPages {
+		info.Next = pageLink(c, map[string]string{"page": strconv.Itoa(q.Page + 1)})
+	}
+	if q.Page > 1 {
+		info.Prev = pageLink(c, map[string]string{"page": strconv.Itoa(q.Page - 1)})
+	}
+	return info
+}
+
+func pageLink(c *gin.Context, set map[string]string) string {
+	u := *c.Request.URL
+	values := u.Query()
+	for k, v := range set {
+		if v == "" {
+			values.Del(k)
+		} else {
+			values.Set(k, v)
+		}
+	}
+	u.RawQuery = values.Encode()
+	return u.RequestURI()
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func pageQuery(target string, defaultLimit int) PageQuery {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return ParsePageQuery(c, defaultLimit)
+}
+
+func TestParsePageQuery(t *testing.T) {
+	tests := []struct {
+		target       string
+		defaultLimit int
+		page, limit  int
+		sort         string
+		desc         bool
+	}{
+		{"/movies", 0, 0, 0, "", false},
+		{"/movies", 10, 1, 10, "", false},
+		{"/movies?page=3", 0, 3, 20, "", false},
+		{"/movies?limit=5", 0, 1, 5, "", false},
+		{"/movies?page=2&limit=500", 0, 2, MaxPageLimit, "", false},
+		{"/movies?page=-1&limit=5", 0, 1, 5, "", false},
+		{"/movies?limit=-4", 0, 0, 0, "", false},
+		{"/movies?cursor=abc&page=4", 0, 0, 20, "", false},
+		{"/movies?sort=-title", 0, 0, 0, "title", true},
+		{"/movies?sort=title&order=DESC", 0, 0, 0, "title", true},
+		{"/movies?sort=title&order=asc", 0, 0, 0, "title", false},
+	}
+	for _, tt := range tests {
+		q := pageQuery(tt.target, tt.defaultLimit)
+		if q.Page != tt.page || q.Limit != tt.limit || q.Sort != tt.sort || q.Desc != tt.desc {
+			t.Errorf("%s (default %d): page %d, limit %d, sort %q, desc %v; want %d, %d, %q, %v",
+				tt.target, tt.defaultLimit, q.Page, q.Limit, q.Sort, q.Desc, tt.page, tt.limit, tt.sort, tt.desc)
+		}
+	}
+
+	q := pageQuery("/movies?search=%20dune%20&genre=3", 0)
+	if q.Search != "dune" || q.Filters.Get("genre") != "3" {
+		t.Errorf("search %q, genre %q; want dune and 3", q.Search, q.Filters.Get("genre"))
+	}
+	if q.Paged() || q.Offset() != 0 {
+		t.Errorf("a query without paging parameters should not be paged")
+	}
+	if q := pageQuery("/movies?page=3&limit=20", 0); q.Offset() != 40 {
+		t.Errorf("offset of page 3 = %d, want 40", q.Offset())
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, value := range []string{"2025-07-30", "", "Title, with \"quotes\""} {
+		cursor := EncodeCursor(value, 42)
+		got, id, err := DecodeCursor(cursor)
+		if err != nil || got != value || id != 42 {
+			t.Errorf("DecodeCursor(EncodeCursor(%q, 42)) = %q, %d, %v", value, got, id, err)
+		}
+	}
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, _, err := DecodeCursor(cursor); err == nil {
+			t.Errorf("DecodeCursor(%q) should fail", cursor)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Published messages go through Redis so subscribers on every app instance
+// receive them. Each process holds a single Redis subscription and hands
+// messages to its local subscribers. While Redis is unreachable messages only
+// reach subscribers of the publishing process.
+
+// subscriberBuffer is how many messages a subscriber may fall behind before it
+// is dropped. Its channel is then closed, so the client reconnects and starts
+// from a fresh state instead of silently missing messages.
+const subscriberBuffer = 32
+
+// subscribeTimeout is how long Subscribe waits for the Redis subscription
+// before it returns anyway, when Redis is slow to answer.
+var subscribeTimeout = 2 * time.Second
+
+// pubsubChannel holds the local subscribers of one channel. ready is closed
+// once run has subscribed to the channel in Redis; pending means run has not
+// picked it up yet.
+type pubsubChannel struct {
+	subs    map[chan []byte]struct{}
+	ready   chan struct{}
+	pending bool
+}
+
+// pubsubHub tracks the local subscribers under mu. The Redis subscription is
+// owned by the run goroutine, which follows the channels in channels, so a
+// slow or unreachable Redis never holds up publishing or other subscribers.
+type pubsubHub struct {
+	mu       sync.Mutex
+	channels map[string]*pubsubChannel
+	changed  chan struct{}
+	start    sync.Once
+}
+
+var hub = &pubsubHub{
+	channels: map[string]*pubsubChannel{},
+	changed:  make(chan struct{}, 1),
+}
+
+// Subscribe returns the messages published on channel from now on, and a
+// function ending the subscription.
+func Subscribe(channel string) (<-chan []byte, func()) {
+	hub.start.Do(func() { go hub.run() })
+	ch := make(chan []byte, subscriberBuffer)
+
+	hub.mu.Lock()
+	c := hub.channels[channel]
+	if c == nil {
+		c = &pubsubChannel{subs: map[chan []byte]struct{}{}, ready: make(chan struct{}), pending: true}
+		hub.channels[channel] = c
+		hub.notify()
+	}
+	c.subs[ch] = struct{}{}
+	ready := c.ready
+	hub.mu.Unlock()
+
+	// Every subscriber waits until the channel is subscribed in Redis, so
+	// messages published once Subscribe returns arrive, including for
+	// subscribers that join while the first one is still waiting.
+	select {
+	case <-ready:
+	case <-time.After(subscribeTimeout):
+	}
+
+	return ch, func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		hub.remove(channel, ch)
+	}
+}
+
+// Publish sends payload to the subscribers of channel on every instance.
+func Publish(ctx context.Context, channel string, payload []byte) {
+	if redisAvailable() {
+		err := RedisClient().Publish(ctx, channel, payload).Err()
+		if err == nil {
+			return
+		}
+		markRedisDown(err)
+	}
+	hub.dispatch(channel, payload)
+}
+
+func (h *pubsubHub) receive(messages <-chan *redis.Message) {
+	for msg := range messages {
+		h.dispatch(msg.Channel, []byte(msg.Payload))
+	}
+}
+
+func (h *pubsubHub) dispatch(channel string, payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := h.channels[channel]
+	if c == nil {
+		return
+	}
+	for ch := range c.subs {
+		select {
+		case ch <- payload:
+		default:
+			h.remove(channel, ch)
+		}
+	}
+}
+
+// remove closes a subscriber; the Redis subscription is dropped once nobody
+// on this instance listens to channel. h.mu must be held.
+func (h *pubsubHub) remove(channel string, ch chan []byte) {
+	c := h.channels[channel]
+	if c == nil {
+		return
+	}
+	if _, ok := c.subs[ch]; !ok {
+		return
+	}
+	delete(c.subs, ch)
+	close(ch)
+	if len(c.subs) == 0 {
+		delete(h.channels, channel)
+		h.notify()
+	}
+}
+
+// notify tells run that the subscribed channels changed. h.mu must be held.
+func (h *pubsubHub) notify() {
+	select {
+	case h.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run owns the Redis subscription. On every change it compares the channels
+// local subscribers listen to with the ones subscribed in Redis and catches
+// up, calling Redis without holding h.mu. A channel whose subscribe fails is
+// still remembered by the client and subscribed again once Redis is back.
+func (h *pubsubHub) run() {
+	ctx := context.Background()
+	pubsub := RedisClient().Subscribe(ctx)
+	go h.receive(pubsub.Channel())
+
+	subscribed := map[string]bool{}
+	for range h.changed {
+		var add, drop []string
+		var ready []chan struct{}
+		h.mu.Lock()
+		for channel, c := range h.channels {
+			if !subscribed[channel] {
+				add = append(add, channel)
+			}
+			if c.pending {
+				c.pending = false
+				ready = append(ready, c.ready)
+			}
+		}
+		for channel := range subscribed {
+			if h.channels[channel] == nil {
+				drop = append(drop, channel)
+			}
+		}
+		h.mu.Unlock()
+
+		if len(add) > 0 {
+			if err := pubsub.Subscribe(ctx, add...); err != nil {
+				log.Println("Redis subscribe failed, messages stay local:", err.Error())
+			}
+			for _, channel := range add {
+				subscribed[channel] = true
+			}
+		}
+		if len(drop) > 0 {
+			if err := pubsub.Unsubscribe(ctx, drop...); err != nil {
+				log.Println("Redis unsubscribe failed:", err.Error())
+			}
+			for _, channel := range drop {
+				delete(subscribed, channel)
+			}
+		}
+		for _, r := range ready {
+			close(r)
+		}
+	}
+}
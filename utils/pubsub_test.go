@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// receive waits for the next message on ch, failing the test after a second.
+func receive(t *testing.T, ch <-chan []byte) ([]byte, bool) {
+	t.Helper()
+	select {
+	case msg, ok := <-ch:
+		return msg, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a message")
+		return nil, false
+	}
+}
+
+func TestPubSubDeliversLocallyWhileRedisIsDown(t *testing.T) {
+	t.Setenv("RDADDRESS", "127.0.0.1:1")
+	redisDownUntil.Store(0)
+	t.Cleanup(func() { redisDownUntil.Store(0) })
+
+	first, stopFirst := Subscribe("pubsub-test")
+	second, stopSecond := Subscribe("pubsub-test")
+	other, stopOther := Subscribe("pubsub-test-other")
+	defer stopOther()
+
+	Publish(context.Background(), "pubsub-test", []byte("hello"))
+	for _, ch := range []<-chan []byte{first, second} {
+		if msg, ok := receive(t, ch); !ok || string(msg) != "hello" {
+			t.Fatalf("got %q (open %v), want hello", msg, ok)
+		}
+	}
+	select {
+	case msg := <-other:
+		t.Fatalf("subscriber of another channel got %q", msg)
+	default:
+	}
+
+	stopFirst()
+	if _, ok := <-first; ok {
+		t.Fatal("the channel should be closed once the subscription ends")
+	}
+	Publish(context.Background(), "pubsub-test", []byte("again"))
+	if msg, ok := receive(t, second); !ok || string(msg) != "again" {
+		t.Fatalf("got %q (open %v), want again", msg, ok)
+	}
+
+	stopSecond()
+	hub.mu.Lock()
+	_, left := hub.channels["pubsub-test"]
+	hub.mu.Unlock()
+	if left {
+		t.Fatal("the channel should be forgotten once its last subscriber leaves")
+	}
+}
+
+func TestPubSubDropsSlowSubscriber(t *testing.T) {
+	t.Setenv("RDADDRESS", "127.0.0.1:1")
+	redisDownUntil.Store(0)
+	t.Cleanup(func() { redisDownUntil.Store(0) })
+
+	ch, stop := Subscribe("pubsub-test-slow")
+	defer stop()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		Publish(context.Background(), "pubsub-test-slow", []byte("tick"))
+	}
+	for i := 0; i < subscriberBuffer; i++ {
+		if _, ok := <-ch; !ok {
+			t.Fatalf("channel closed after %d messages, want %d buffered", i, subscriberBuffer)
+		}
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("a subscriber that fell behind should have its channel closed")
+	}
+}
+
+func TestSubscribeWaitsUntilChannelIsReady(t *testing.T) {
+	t.Setenv("RDADDRESS", "127.0.0.1:1")
+	hub.start.Do(func() { go hub.run() })
+
+	// A channel run has picked up but not subscribed in Redis yet.
+	ready := make(chan struct{})
+	hub.mu.Lock()
+	hub.channels["pubsub-test-ready"] = &pubsubChannel{subs: map[chan []byte]struct{}{}, ready: ready}
+	hub.mu.Unlock()
+
+	returned := make(chan func(), 2)
+	for range 2 {
+		go func() {
+			_, stop := Subscribe("pubsub-test-ready")
+			returned <- stop
+		}()
+	}
+	select {
+	case <-returned:
+		t.Fatal("Subscribe returned before the channel was subscribed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(ready)
+	for range 2 {
+		select {
+		case stop := <-returned:
+			defer stop()
+		case <-time.After(time.Second):
+			t.Fatal("Subscribe did not return once the channel was ready")
+		}
+	}
+}
+
+func TestSubscribeTimesOut(t *testing.T) {
+	t.Setenv("RDADDRESS", "127.0.0.1:1")
+	hub.start.Do(func() { go hub.run() })
+	timeout := subscribeTimeout
+	subscribeTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { subscribeTimeout = timeout })
+
+	hub.mu.Lock()
+	hub.channels["pubsub-test-stuck"] = &pubsubChannel{subs: map[chan []byte]struct{}{}, ready: make(chan struct{})}
+	hub.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		_, stop := Subscribe("pubsub-test-stuck")
+		stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe should give up waiting for a stuck Redis")
+	}
+}
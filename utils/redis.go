@@ -3,20 +3,34 @@ package utils
 import (
 	"os"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
 )
 
-func RedisClient() redis.Client {
-	godotenv.Load()
-	addr := os.Getenv("RDADDRESS")
-	pass := os.Getenv("RDPASSWORD")
-	db, _ := strconv.Atoi(os.Getenv("RDDB"))
-	var RedisClient = redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: pass,
-		DB:       db,
+var (
+	redisClient *redis.Client
+	redisOnce   sync.Once
+)
+
+// RedisClient returns the shared Redis client. The client keeps its own
+// connection pool, so it is created once and reused by every caller.
+func RedisClient() *redis.Client {
+	redisOnce.Do(func() {
+		godotenv.Load()
+		addr := os.Getenv("RDADDRESS")
+		pass := os.Getenv("RDPASSWORD")
+		db, _ := strconv.Atoi(os.Getenv("RDDB"))
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:         addr,
+			Password:     pass,
+			DB:           db,
+			DialTimeout:  time.Second,
+			ReadTimeout:  500 * time.Millisecond,
+			WriteTimeout: 500 * time.Millisecond,
+		})
 	})
-	return *RedisClient
+	return redisClient
 }
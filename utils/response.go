@@ -5,4 +5,5 @@ type Response struct {
 	Message string `json:"message"`
 	Errors any `json:"error,omitempty"`
 	Results any `json:"results,omitempty"`
+	PageInfo *PageInfo `json:"pageInfo,omitempty"`
 }
\ No newline at end of file
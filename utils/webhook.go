@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookAddress is returned for a webhook URL pointing at an address that
+// is not on the public internet.
+var ErrWebhookAddress = errors.New("webhook URL must point at a public address")
+
+// WebhookResponse is what a receiver answered to a delivery.
+type WebhookResponse struct {
+	Status int
+	Body   string
+}
+
+const maxWebhookResponseBytes = 2048
+
+// cgnat is the carrier-grade NAT range, which netip does not count as
+// private.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddress reports whether ip is on the public internet, ruling out
+// loopback, private, link-local (which holds cloud metadata at
+// 169.254.169.254) and other special ranges.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip) &&
+		!(ip.Is4() && ip.As4()[0] == 0)
+}
+
+// allowPrivateWebhooks lets webhooks reach local addresses when
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS is 1, for receivers on a development
+// machine or inside the same network.
+func allowPrivateWebhooks() bool {
+	return GetEnvInt("WEBHOOK_ALLOW_PRIVATE_NETWORKS", 0) == 1
+}
+
+// checkWebhookDial runs on every connection attempt, after the host has been
+// resolved, so a DNS name cannot be pointed at a local address after the
+// webhook was checked.
+func checkWebhookDial(_, address string, _ syscall.RawConn) error {
+	if allowPrivateWebhooks() {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+var webhookClient = &http.Client{
+	Transport: &http.Transport{
+		// Deliveries go straight to the receiver, so the address check sees
+		// the receiver's address rather than a proxy's.
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: checkWebhookDial,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	// Redirects are not followed; a receiver has to answer at the registered
+	// URL.
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// CheckWebhookURL resolves the host of a webhook URL and rejects it when any
+// of its addresses is not public. Deliveries check the address again when
+// they connect.
+func CheckWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook URL must use http or https")
+	}
+	if allowPrivateWebhooks() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("cannot resolve webhook host %q: %v", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrWebhookAddress, u.Hostname(), addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// NewWebhookSecret returns a random signing secret for a new webhook.
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// SignWebhook signs a payload the way receivers check it: the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the webhook secret. The timestamp lets
+// receivers reject replayed deliveries.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostWebhook sends a signed delivery. Any 2xx answer counts as delivered;
+// other answers are returned together with an error so they can be logged.
+func PostWebhook(url, secret, event string, deliveryID int, body []byte) (WebhookResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Tickitz-Webhooks/1.0")
+	req.Header.Set("X-Tickitz-Event", event)
+	req.Header.Set("X-Tickitz-Delivery", strconv.Itoa(deliveryID))
+	req.Header.Set("X-Tickitz-Signature", fmt.Sprintf("t=%d,v1=%s", timestamp, SignWebhook(secret, timestamp, body)))
+
+	client := *webhookClient
+	client.Timeout = time.Duration(GetEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second
+	resp, err := client.Do(req)
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	answer, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBytes))
+	// The answer is kept in the delivery log, which only takes valid text.
+	text := strings.ReplaceAll(strings.ToValidUTF8(string(answer), "\uFFFD"), "\x00", "")
+	result := WebhookResponse{Status: resp.StatusCode, Body: text}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n '1700000000.{"ok":true}' | openssl dgst -sha256 -hmac whsec_test
+	want := "85876387ad9d6be57a04653bc0729da757049f58afb10ba6cac3bedaecf4fda3"
+	got := SignWebhook("whsec_test", 1700000000, []byte(`{"ok":true}`))
+	if got != want {
+		t.Fatalf("SignWebhook() = %s, want %s", got, want)
+	}
+	if SignWebhook("whsec_test", 1700000001, []byte(`{"ok":true}`)) == got {
+		t.Fatal("the signature should change with the timestamp")
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"0.1.2.3":          false,
+		"224.0.0.1":        false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+		"::ffff:8.8.8.8":   true,
+	}
+	for addr, want := range tests {
+		if got := publicAddress(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("publicAddress(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "0")
+	for _, raw := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://127.0.0.1:8080/hook",
+		"https://[::1]/hook",
+		"http://10.0.0.5/hook",
+	} {
+		if err := CheckWebhookURL(raw); !errors.Is(err, ErrWebhookAddress) {
+			t.Errorf("CheckWebhookURL(%q) = %v, want ErrWebhookAddress", raw, err)
+		}
+	}
+	if err := CheckWebhookURL("ftp://93.184.216.34/hook"); err == nil {
+		t.Error("CheckWebhookURL should reject schemes other than http and https")
+	}
+	if err := CheckWebhookURL("https://93.184.216.34/hook"); err != nil {
+		t.Errorf("CheckWebhookURL(public IP) = %v, want nil", err)
+	}
+}
+
+func TestPostWebhookRefusesLocalReceivers(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "0")
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := PostWebhook(server.URL, "whsec_test", "movie.created", 1, []byte(`{}`))
+	if !errors.Is(err, ErrWebhookAddress) {
+		t.Fatalf("PostWebhook() = %v, want ErrWebhookAddress", err)
+	}
+	if called {
+		t.Fatal("the local receiver should not have been reached")
+	}
+}
+
+func TestPostWebhookSignsDelivery(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "1")
+	body := []byte(`{"event":"movie.created"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		parts := strings.Split(r.Header.Get("X-Tickitz-Signature"), ",")
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "t=") || !strings.HasPrefix(parts[1], "v1=") {
+			http.Error(w, "bad signature header", http.StatusBadRequest)
+			return
+		}
+		timestamp, _ := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+		if strings.TrimPrefix(parts[1], "v1=") != SignWebhook("whsec_test", timestamp, got) {
+			http.Error(w, "signature mismatch", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Tickitz-Event") != "movie.created" || r.Header.Get("X-Tickitz-Delivery") != "7" {
+			http.Error(w, "missing event headers", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	resp, err := PostWebhook(server.URL, "whsec_test", "movie.created", 7, body)
+	if err != nil {
+		t.Fatalf("PostWebhook() = %v (%d %s)", err, resp.Status, resp.Body)
+	}
+	if resp.Status != http.StatusOK || resp.Body != "ok" {
+		t.Fatalf("response = %+v, want 200 ok", resp)
+	}
+}